@@ -0,0 +1,72 @@
+package mesa
+
+import "testing"
+
+// RunAgainst benchmarks each of the named implementations of the target with every case, in place of Target. Every
+// implementation gets its own instance for each case, built from the same fields and input, so they are compared
+// fairly. Init and Teardown are called once for the whole run.
+//
+// Each implementation runs as a sub-benchmark of its case named impl=<name>, e.g.
+// BenchmarkSort/size-1024/impl=quick, and implementations run in the order of their names. The key=value form lets
+// benchstat put the implementations side by side with:
+//
+//	go test -bench Sort -count 10 > out.txt
+//	benchstat -col /impl out.txt
+func (m MethodBenchmarkMesa[Inst, F, I, O]) RunAgainst(
+	b *testing.B,
+	impls map[string]func(ctx *Ctx, inst Inst, in I) O,
+) {
+	if len(impls) == 0 {
+		b.Fatal("RunAgainst requires at least one implementation")
+	}
+
+	ctx := newCtx(b)
+
+	if m.Init != nil {
+		m.Init(ctx)
+	}
+
+	if m.Teardown != nil {
+		defer m.Teardown(ctx)
+	}
+
+	names := sortedKeys(impls)
+
+	for _, bb := range m.Cases {
+		b.Run(bb.Name, func(b *testing.B) {
+			if bb.Skip != "" {
+				b.Skip(bb.Skip)
+			}
+
+			for _, name := range names {
+				im := m
+				im.Init, im.Teardown = nil, nil
+				im.Target = impls[name]
+
+				c := bb
+				c.Name = "impl=" + name
+				im.Cases = []MethodBenchmarkCase[Inst, F, I, O]{c}
+
+				im.run(b, nil)
+			}
+		})
+	}
+}
+
+// RunForTypes runs the suite once per named factory, in place of NewInstance and NewInstanceErr, e.g. to check that
+// several implementations of an interface behave the same way. Each run is a subtest named after its factory, with
+// the cases nested in it, so the Init and Teardown functions are called once per factory. Factories run in the order
+// of their names.
+func (m MethodMesa[Inst, F, I, O]) RunForTypes(t *testing.T, factories map[string]func(ctx *Ctx, fields F) Inst) {
+	if len(factories) == 0 {
+		t.Fatal("RunForTypes requires at least one factory")
+	}
+
+	for _, name := range sortedKeys(factories) {
+		im := m
+		im.NewInstance = factories[name]
+		im.NewInstanceErr = nil
+
+		t.Run(name, im.Run)
+	}
+}
@@ -0,0 +1,201 @@
+package mesa_test
+
+import (
+	"sort"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/a20r/mesa"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMethodBenchmarkMesa_RunAgainst(t *testing.T) {
+	setBenchtime(t, "10x")
+
+	var (
+		mu        sync.Mutex
+		inits     int
+		teardowns int
+		instances int
+		calls     = map[string]bool{}
+		checked   = map[int]bool{}
+	)
+
+	m := mesa.MethodBenchmarkMesa[*[]int, mesa.Empty, []int, int]{
+		Init: func(ctx *mesa.Ctx) {
+			inits++
+		},
+		Teardown: func(ctx *mesa.Ctx) {
+			teardowns++
+		},
+		NewInstance: func(ctx *mesa.Ctx, _ mesa.Empty) *[]int {
+			instances++
+			return &[]int{}
+		},
+		Check: func(ctx *mesa.Ctx, inst *[]int, in []int, out int) {
+			checked[out] = true
+		},
+		Cases: []mesa.MethodBenchmarkCase[*[]int, mesa.Empty, []int, int]{
+			{Name: "Small", Input: []int{3, 1, 2}},
+			{Name: "Skipped", Input: []int{1}, Skip: "not needed"},
+		},
+	}
+
+	record := func(name string, in []int) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		calls[name+"/"+strconv.Itoa(len(in))] = true
+	}
+
+	testing.Benchmark(func(b *testing.B) {
+		m.RunAgainst(b, map[string]func(ctx *mesa.Ctx, inst *[]int, in []int) int{
+			"sum": func(ctx *mesa.Ctx, inst *[]int, in []int) int {
+				record("sum", in)
+
+				sum := 0
+				for _, n := range in {
+					sum += n
+				}
+
+				return sum
+			},
+			"max": func(ctx *mesa.Ctx, inst *[]int, in []int) int {
+				record("max", in)
+
+				sorted := append([]int(nil), in...)
+				sort.Ints(sorted)
+
+				return sorted[len(sorted)-1]
+			},
+		})
+	})
+
+	assert.Equal(t, 1, inits)
+	assert.Equal(t, 1, teardowns)
+	assert.GreaterOrEqual(t, instances, 2, "every implementation gets its own instance")
+	assert.Equal(t, map[string]bool{"max/3": true, "sum/3": true}, calls)
+	assert.Equal(t, map[int]bool{3: true, 6: true}, checked)
+}
+
+type stack interface {
+	Push(v int)
+	Pop() (int, bool)
+}
+
+type sliceStack struct{ items []int }
+
+func (s *sliceStack) Push(v int) { s.items = append(s.items, v) }
+
+func (s *sliceStack) Pop() (int, bool) {
+	if len(s.items) == 0 {
+		return 0, false
+	}
+
+	v := s.items[len(s.items)-1]
+	s.items = s.items[:len(s.items)-1]
+
+	return v, true
+}
+
+type listStack struct{ head *listNode }
+
+type listNode struct {
+	value int
+	next  *listNode
+}
+
+func (s *listStack) Push(v int) { s.head = &listNode{value: v, next: s.head} }
+
+func (s *listStack) Pop() (int, bool) {
+	if s.head == nil {
+		return 0, false
+	}
+
+	v := s.head.value
+	s.head = s.head.next
+
+	return v, true
+}
+
+func TestMethodMesa_RunForTypes(t *testing.T) {
+	var names []string
+
+	m := mesa.MethodMesa[stack, mesa.Empty, []int, []int]{
+		Target: func(ctx *mesa.Ctx, s stack, in []int) []int {
+			names = append(names, ctx.T().Name())
+
+			for _, v := range in {
+				s.Push(v)
+			}
+
+			var out []int
+			for v, ok := s.Pop(); ok; v, ok = s.Pop() {
+				out = append(out, v)
+			}
+
+			return out
+		},
+		Cases: []mesa.MethodCase[stack, mesa.Empty, []int, []int]{
+			{Name: "LIFO", Input: []int{1, 2, 3}, Expected: []int{3, 2, 1}},
+			{Name: "Empty", Input: nil},
+		},
+	}
+
+	m.RunForTypes(t, map[string]func(ctx *mesa.Ctx, _ mesa.Empty) stack{
+		"slice": func(ctx *mesa.Ctx, _ mesa.Empty) stack {
+			return &sliceStack{}
+		},
+		"list": func(ctx *mesa.Ctx, _ mesa.Empty) stack {
+			return &listStack{}
+		},
+	})
+
+	assert.Equal(t, []string{
+		"TestMethodMesa_RunForTypes/list/LIFO",
+		"TestMethodMesa_RunForTypes/list/Empty",
+		"TestMethodMesa_RunForTypes/slice/LIFO",
+		"TestMethodMesa_RunForTypes/slice/Empty",
+	}, names)
+}
+
+func TestMethodMesa_RunForTypes_Failure(t *testing.T) {
+	expectFailure(t, func(t *testing.T) {
+		m := mesa.MethodMesa[stack, mesa.Empty, int, int]{
+			Target: func(ctx *mesa.Ctx, s stack, in int) int {
+				s.Push(in)
+				s.Push(in + 1)
+				v, _ := s.Pop()
+				return v
+			},
+			Cases: []mesa.MethodCase[stack, mesa.Empty, int, int]{
+				{Name: "Last pushed", Input: 1, Expected: 2},
+			},
+		}
+
+		m.RunForTypes(t, map[string]func(ctx *mesa.Ctx, _ mesa.Empty) stack{
+			"slice": func(ctx *mesa.Ctx, _ mesa.Empty) stack {
+				return &sliceStack{}
+			},
+			"queue": func(ctx *mesa.Ctx, _ mesa.Empty) stack {
+				return &queue{}
+			},
+		})
+	}, "--- FAIL: TestMethodMesa_RunForTypes_Failure/queue/Last_pushed",
+		"--- PASS: TestMethodMesa_RunForTypes_Failure/slice/Last_pushed")
+}
+
+// queue is a broken stack that pops the oldest value.
+type queue struct{ sliceStack }
+
+func (q *queue) Pop() (int, bool) {
+	if len(q.items) == 0 {
+		return 0, false
+	}
+
+	v := q.items[0]
+	q.items = q.items[1:]
+
+	return v, true
+}
@@ -0,0 +1,39 @@
+package mesa
+
+import (
+	"math"
+	"runtime"
+)
+
+// allocRuns is the number of times the target is measured for MaxAllocs and ExpectNoAllocs.
+const allocRuns = 5
+
+// measureAllocs calls fn once, which also warms up caches and lazy initialization, and returns its output along with
+// the smallest number of heap allocations of fn over allocRuns more calls. Taking the minimum filters out allocations
+// made concurrently by other goroutines.
+func measureAllocs[O any](fn func() O) (O, uint64) {
+	out := fn()
+
+	var (
+		before, after runtime.MemStats
+		allocs        uint64 = math.MaxUint64
+	)
+
+	for i := 0; i < allocRuns; i++ {
+		runtime.ReadMemStats(&before)
+		fn()
+		runtime.ReadMemStats(&after)
+
+		if n := after.Mallocs - before.Mallocs; n < allocs {
+			allocs = n
+		}
+	}
+
+	return out, allocs
+}
+
+// checkAllocs asserts that the target made at most maxAllocs allocations.
+func checkAllocs(ctx *Ctx, allocs, maxAllocs uint64) {
+	ctx.As.LessOrEqual(allocs, maxAllocs, "%s: target made %d allocations, more than the maximum of %d", ctx.name(),
+		allocs, maxAllocs)
+}
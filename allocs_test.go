@@ -0,0 +1,91 @@
+package mesa_test
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/a20r/mesa"
+)
+
+func TestExpectNoAllocs(t *testing.T) {
+	m := mesa.FunctionMesa[int, int]{
+		RequireAssertions: true,
+		Target: func(ctx *mesa.Ctx, in int) int {
+			return in * 2
+		},
+		Check: func(ctx *mesa.Ctx, in int, out int) {},
+		Cases: []mesa.FunctionCase[int, int]{
+			{Name: "No allocations", Input: 21, ExpectNoAllocs: true},
+		},
+	}
+
+	m.Run(t)
+}
+
+func TestMaxAllocs(t *testing.T) {
+	m := mesa.FunctionMesa[int, []string]{
+		Target: func(ctx *mesa.Ctx, n int) []string {
+			out := make([]string, 0, n)
+			for i := 0; i < n; i++ {
+				out = append(out, strconv.Itoa(i+100))
+			}
+
+			return out
+		},
+		Cases: []mesa.FunctionCase[int, []string]{
+			{Name: "Within the limit", Input: 3, MaxAllocs: 4, Expected: []string{"100", "101", "102"}},
+		},
+	}
+
+	m.Run(t)
+}
+
+func TestMaxAllocs_Exceeded(t *testing.T) {
+	expectFailure(t, func(t *testing.T) {
+		m := mesa.FunctionMesa[int, []string]{
+			Target: func(ctx *mesa.Ctx, n int) []string {
+				var out []string
+				for i := 0; i < n; i++ {
+					out = append(out, strconv.Itoa(i+100))
+				}
+
+				return out
+			},
+			Cases: []mesa.FunctionCase[int, []string]{
+				{Name: "Too many", Input: 10, MaxAllocs: 2},
+			},
+		}
+
+		m.Run(t)
+	}, "more than the maximum of 2")
+}
+
+func TestExpectNoAllocs_Allocates(t *testing.T) {
+	expectFailure(t, func(t *testing.T) {
+		m := mesa.FunctionMesa[int, string]{
+			Target: func(ctx *mesa.Ctx, n int) string {
+				return strconv.Itoa(n + 1000)
+			},
+			Cases: []mesa.FunctionCase[int, string]{
+				{Name: "Allocates", Input: 1, ExpectNoAllocs: true},
+			},
+		}
+
+		m.Run(t)
+	}, "target made 1 allocations, more than the maximum of 0")
+}
+
+func TestExpectNoAllocs_WithMaxAllocs(t *testing.T) {
+	expectFailure(t, func(t *testing.T) {
+		m := mesa.FunctionMesa[int, string]{
+			Target: func(ctx *mesa.Ctx, n int) string {
+				return strconv.Itoa(n + 1000)
+			},
+			Cases: []mesa.FunctionCase[int, string]{
+				{Name: "Allocates", Input: 1, ExpectNoAllocs: true, MaxAllocs: 10},
+			},
+		}
+
+		m.Run(t)
+	}, "target made 1 allocations, more than the maximum of 0")
+}
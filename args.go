@@ -0,0 +1,66 @@
+package mesa
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Args splits s into arguments the way a POSIX shell would, so that CLI parsers can be tested with inputs that read
+// like a command line, e.g. Args(`--name "a b" -x`) returns ["--name", "a b", "-x"]. Arguments are separated by
+// whitespace. Single quotes keep everything up to the next single quote as is. Double quotes keep everything up to the
+// next unescaped double quote, where a backslash only escapes a double quote or a backslash. Outside of quotes a
+// backslash escapes any character. Quotes can be adjacent to other characters, and "" is an empty argument. It panics
+// if a quote is not closed or s ends with a backslash, since cases are usually declared as literals.
+func Args(s string) []string {
+	args := []string{}
+
+	var (
+		arg    strings.Builder
+		inArg  bool
+		quote  rune
+		escape bool
+	)
+
+	for _, r := range s {
+		switch {
+		case escape:
+			if quote == '"' && r != '"' && r != '\\' {
+				arg.WriteRune('\\')
+			}
+
+			arg.WriteRune(r)
+			escape = false
+		case r == '\\' && quote != '\'':
+			escape = true
+			inArg = true
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote != 0:
+			arg.WriteRune(r)
+		case r == '\'' || r == '"':
+			quote = r
+			inArg = true
+		case unicode.IsSpace(r):
+			if inArg {
+				args = append(args, arg.String())
+				arg.Reset()
+				inArg = false
+			}
+		default:
+			arg.WriteRune(r)
+			inArg = true
+		}
+	}
+
+	switch {
+	case escape:
+		panic(fmt.Sprintf("mesa: args %q end with a backslash", s))
+	case quote != 0:
+		panic(fmt.Sprintf("mesa: args %q have an unclosed %c quote", s, quote))
+	case inArg:
+		args = append(args, arg.String())
+	}
+
+	return args
+}
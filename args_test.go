@@ -0,0 +1,69 @@
+package mesa_test
+
+import (
+	"flag"
+	"testing"
+
+	"github.com/a20r/mesa"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArgs(t *testing.T) {
+	m := mesa.FunctionMesa[string, []string]{
+		Target: func(ctx *mesa.Ctx, in string) []string {
+			return mesa.Args(in)
+		},
+		Cases: []mesa.FunctionCase[string, []string]{
+			{Name: "Empty", Input: "", Expected: []string{}},
+			{Name: "Only whitespace", Input: " \t\n", Expected: []string{}},
+			{Name: "Plain", Input: "-x  --flag value", Expected: []string{"-x", "--flag", "value"}},
+			{Name: "Double quotes", Input: `--flag "a b" -x`, Expected: []string{"--flag", "a b", "-x"}},
+			{Name: "Single quotes", Input: `'a "b" \c'`, Expected: []string{`a "b" \c`}},
+			{Name: "Escaped double quote", Input: `"say \"hi\""`, Expected: []string{`say "hi"`}},
+			{Name: "Backslash in double quotes", Input: `"a\\b\c"`, Expected: []string{`a\b\c`}},
+			{Name: "Escaped space", Input: `a\ b c`, Expected: []string{"a b", "c"}},
+			{Name: "Adjacent quotes", Input: `--name="a b"'c'`, Expected: []string{"--name=a bc"}},
+			{Name: "Empty quotes", Input: `"" ''`, Expected: []string{"", ""}},
+		},
+	}
+
+	m.Run(t)
+}
+
+func TestArgs_Invalid(t *testing.T) {
+	assert.PanicsWithValue(t, `mesa: args "\"a b" have an unclosed " quote`, func() { mesa.Args(`"a b`) })
+	assert.PanicsWithValue(t, `mesa: args "'a" have an unclosed ' quote`, func() { mesa.Args(`'a`) })
+	assert.PanicsWithValue(t, `mesa: args "a\\" end with a backslash`, func() { mesa.Args(`a\`) })
+}
+
+func TestArgs_FlagSet(t *testing.T) {
+	type parsed struct {
+		Name    string
+		Verbose bool
+		Rest    []string
+	}
+
+	m := mesa.FunctionMesa[[]string, parsed]{
+		Target: func(ctx *mesa.Ctx, args []string) parsed {
+			var p parsed
+
+			fs := flag.NewFlagSet("cmd", flag.ContinueOnError)
+			fs.StringVar(&p.Name, "name", "", "")
+			fs.BoolVar(&p.Verbose, "v", false, "")
+			ctx.Re.NoError(fs.Parse(args))
+
+			p.Rest = fs.Args()
+
+			return p
+		},
+		Cases: []mesa.FunctionCase[[]string, parsed]{
+			{
+				Name:     "Quoted flag value",
+				Input:    mesa.Args(`-v --name "Jane Doe" file.txt`),
+				Expected: parsed{Name: "Jane Doe", Verbose: true, Rest: []string{"file.txt"}},
+			},
+		},
+	}
+
+	m.Run(t)
+}
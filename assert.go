@@ -0,0 +1,148 @@
+package mesa
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// Eventually asserts that fn returns true within timeout, polling it every interval. The failure message includes
+// the name of the case.
+func (c *Ctx) Eventually(fn func() bool, timeout, interval time.Duration) bool {
+	return c.As.Eventually(fn, timeout, interval, "%s: condition was not satisfied within %v", c.name(), timeout)
+}
+
+// Never asserts that fn does not return true within timeout, polling it every interval. The failure message
+// includes the name of the case.
+func (c *Ctx) Never(fn func() bool, timeout, interval time.Duration) bool {
+	return c.As.Never(fn, timeout, interval, "%s: condition was satisfied within %v", c.name(), timeout)
+}
+
+// EqualJSON asserts that expected and actual are equivalent JSON documents. Both sides are unmarshaled and compared
+// structurally, so key order and whitespace don't matter.
+func (c *Ctx) EqualJSON(expected, actual string) bool {
+	var e, a any
+
+	if err := json.Unmarshal([]byte(expected), &e); err != nil {
+		return c.As.Fail(fmt.Sprintf("%s: expected value is not valid JSON: %v", c.name(), err))
+	}
+
+	if err := json.Unmarshal([]byte(actual), &a); err != nil {
+		return c.As.Fail(fmt.Sprintf("%s: actual value is not valid JSON: %v", c.name(), err))
+	}
+
+	return c.As.Equal(e, a, "%s: JSON documents are not equivalent", c.name())
+}
+
+// JSONEq asserts that expected and actual are equivalent JSON documents, like EqualJSON, using testify's JSONEq. The
+// failure message includes a diff of the decoded documents, so only structural differences are shown.
+func (c *Ctx) JSONEq(expected, actual string) bool {
+	return c.As.JSONEq(expected, actual, "%s: JSON documents are not equivalent", c.name())
+}
+
+// YAMLEq asserts that expected and actual are equivalent YAML documents using testify's YAMLEq, so key order,
+// indentation and quoting don't matter. Like JSONEq, the failure message includes a diff of the decoded documents.
+func (c *Ctx) YAMLEq(expected, actual string) bool {
+	return c.As.YAMLEq(expected, actual, "%s: YAML documents are not equivalent", c.name())
+}
+
+// ElementsMatch asserts that expected and actual, which must be slices or arrays, have the same elements regardless
+// of their order. Duplicates must appear the same number of times in both, and nil and empty slices are equal.
+func (c *Ctx) ElementsMatch(expected, actual any) bool {
+	return c.As.ElementsMatch(expected, actual, "%s: elements don't match", c.name())
+}
+
+// Regexp asserts that actual matches the regular expression pattern. The pattern is compiled first, and the case
+// fails without matching if it is invalid. Use ExpectedRegex to assert on the output of a case without a Check.
+func (c *Ctx) Regexp(pattern, actual string) bool {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return c.As.Fail(fmt.Sprintf("%s: invalid pattern %q: %v", c.name(), pattern, err))
+	}
+
+	return c.As.Regexp(re, actual, "%s: %q doesn't match %q", c.name(), actual, pattern)
+}
+
+// Contains asserts that container, which can be a string, a slice, an array or a map, contains element. Maps are
+// searched by key. The failure message includes the name of the case.
+func (c *Ctx) Contains(container, element any) bool {
+	return c.As.Contains(container, element, "%s: %#v does not contain %#v", c.name(), container, element)
+}
+
+// NotContains asserts that container, which can be a string, a slice, an array or a map, does not contain element.
+// Maps are searched by key. The failure message includes the name of the case.
+func (c *Ctx) NotContains(container, element any) bool {
+	return c.As.NotContains(container, element, "%s: %#v contains %#v", c.name(), container, element)
+}
+
+// Len asserts that object, which can be anything accepted by the len builtin, has the given length. The failure
+// message includes the name of the case.
+func (c *Ctx) Len(object any, length int) bool {
+	return c.As.Len(object, length, "%s: unexpected length", c.name())
+}
+
+// NoErr asserts that err is nil and stops the case otherwise.
+func (c *Ctx) NoErr(err error) {
+	c.Re.NoError(err, "%s: unexpected error", c.name())
+}
+
+// OK asserts that cond is true and fails the case with msg otherwise.
+func (c *Ctx) OK(cond bool, msg string) bool {
+	return c.As.True(cond, "%s: %s", c.name(), msg)
+}
+
+// Errorf fails the case with a formatted message and continues it, like t.Errorf. It works for tests and benchmarks.
+func (c *Ctx) Errorf(format string, args ...any) {
+	t := c.reporter()
+	t.Helper()
+	t.Errorf(format, args...)
+}
+
+// Fatalf fails the case with a formatted message and stops it, like t.Fatalf. It works for tests and benchmarks.
+func (c *Ctx) Fatalf(format string, args ...any) {
+	t := c.reporter()
+	t.Helper()
+
+	if f, ok := t.(interface{ Fatalf(string, ...any) }); ok {
+		f.Fatalf(format, args...)
+		return
+	}
+
+	t.Errorf(format, args...)
+	t.FailNow()
+}
+
+// skipper is implemented by *testing.T and *testing.B.
+type skipper interface {
+	Skip(args ...any)
+	Skipf(format string, args ...any)
+}
+
+// Skip skips the case, e.g. from a hook that finds out the case can't run, and logs args like t.Skip. It works for
+// tests and benchmarks.
+func (c *Ctx) Skip(args ...any) {
+	withHelper(c.t).Helper()
+
+	if s := c.skipper(); s != nil {
+		s.Skip(args...)
+	}
+}
+
+// Skipf skips the case and logs a formatted reason like t.Skipf. It works for tests and benchmarks.
+func (c *Ctx) Skipf(format string, args ...any) {
+	withHelper(c.t).Helper()
+
+	if s := c.skipper(); s != nil {
+		s.Skipf(format, args...)
+	}
+}
+
+// skipper returns the test or benchmark of the context. The case fails, and nil is returned if ctx.Re doesn't stop it,
+// when it can't be skipped.
+func (c *Ctx) skipper() skipper {
+	s, ok := c.t.(skipper)
+	c.Re.True(ok, "Ctx is backed by a %T which can't be skipped", c.t)
+
+	return s
+}
@@ -0,0 +1,350 @@
+package mesa_test
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/a20r/mesa"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCtx_Eventually(t *testing.T) {
+	var calls atomic.Int32
+
+	r := record(func(ctx *mesa.Ctx) {
+		ok := ctx.Eventually(func() bool {
+			return calls.Add(1) >= 3
+		}, time.Second, time.Millisecond)
+		assert.True(t, ok)
+	})
+
+	assert.False(t, r.failed)
+	assert.GreaterOrEqual(t, calls.Load(), int32(3))
+}
+
+func TestCtx_Eventually_Timeout(t *testing.T) {
+	r := record(func(ctx *mesa.Ctx) {
+		ok := ctx.Eventually(func() bool {
+			return false
+		}, 20*time.Millisecond, time.Millisecond)
+		assert.False(t, ok)
+	})
+
+	assert.True(t, r.failed)
+	assert.True(t, strings.Contains(strings.Join(r.errors, "\n"), "TestRecorder/case: condition was not satisfied"))
+}
+
+func TestCtx_Never(t *testing.T) {
+	r := record(func(ctx *mesa.Ctx) {
+		ok := ctx.Never(func() bool {
+			return false
+		}, 20*time.Millisecond, time.Millisecond)
+		assert.True(t, ok)
+	})
+
+	assert.False(t, r.failed)
+}
+
+func TestCtx_Never_Satisfied(t *testing.T) {
+	r := record(func(ctx *mesa.Ctx) {
+		ok := ctx.Never(func() bool {
+			return true
+		}, time.Second, time.Millisecond)
+		assert.False(t, ok)
+	})
+
+	assert.True(t, r.failed)
+	assert.True(t, strings.Contains(strings.Join(r.errors, "\n"), "TestRecorder/case: condition was satisfied"))
+}
+
+func TestCtx_EqualJSON(t *testing.T) {
+	r := record(func(ctx *mesa.Ctx) {
+		assert.True(t, ctx.EqualJSON(`{"a": 1, "b": [1, 2]}`, `{"b":[1,2],"a":1}`))
+		assert.True(t, ctx.EqualJSON(`{"outer": {"x": "y", "z": null}}`, "{\n  \"outer\": {\"z\": null, \"x\": \"y\"}\n}"))
+	})
+
+	assert.False(t, r.failed)
+
+	r = record(func(ctx *mesa.Ctx) {
+		assert.False(t, ctx.EqualJSON(`{"a": 1}`, `{"a": 2}`))
+	})
+
+	assert.True(t, r.failed)
+	assert.Contains(t, strings.Join(r.errors, "\n"), "JSON documents are not equivalent")
+
+	r = record(func(ctx *mesa.Ctx) {
+		assert.False(t, ctx.EqualJSON(`{"a": 1}`, `{"a":`))
+	})
+
+	assert.True(t, r.failed)
+	assert.Contains(t, strings.Join(r.errors, "\n"), "actual value is not valid JSON")
+}
+
+func TestCtx_JSONEq(t *testing.T) {
+	r := record(func(ctx *mesa.Ctx) {
+		assert.True(t, ctx.JSONEq(`{"a": 1, "b": {"c": [1, 2]}}`, `{"b":{"c":[1,2]},"a":1}`))
+	})
+
+	assert.False(t, r.failed)
+
+	r = record(func(ctx *mesa.Ctx) {
+		assert.False(t, ctx.JSONEq(`{"name": "ada", "age": 36}`, `{"age": 37, "name": "ada"}`))
+	})
+
+	assert.True(t, r.failed)
+	assert.Contains(t, strings.Join(r.errors, "\n"), "TestRecorder/case: JSON documents are not equivalent")
+	assert.Contains(t, strings.Join(r.errors, "\n"), `- (string) (len=3) "age": (float64) 36`)
+	assert.Contains(t, strings.Join(r.errors, "\n"), `+ (string) (len=3) "age": (float64) 37`)
+}
+
+func TestCtx_YAMLEq(t *testing.T) {
+	r := record(func(ctx *mesa.Ctx) {
+		assert.True(t, ctx.YAMLEq("name: ada\nroles: [admin, dev]\n", "roles:\n  - admin\n  - dev\nname: 'ada'\n"))
+	})
+
+	assert.False(t, r.failed)
+
+	r = record(func(ctx *mesa.Ctx) {
+		assert.False(t, ctx.YAMLEq("replicas: 2\nimage: app:1\n", "image: app:1\nreplicas: 3\n"))
+	})
+
+	assert.True(t, r.failed)
+	assert.Contains(t, strings.Join(r.errors, "\n"), "TestRecorder/case: YAML documents are not equivalent")
+	assert.Contains(t, strings.Join(r.errors, "\n"), `- (string) (len=8) "replicas": (int) 2`)
+	assert.Contains(t, strings.Join(r.errors, "\n"), `+ (string) (len=8) "replicas": (int) 3`)
+}
+
+func TestCtx_ElementsMatch(t *testing.T) {
+	r := record(func(ctx *mesa.Ctx) {
+		assert.True(t, ctx.ElementsMatch([]int{1, 2, 2, 3}, []int{2, 3, 2, 1}))
+		assert.True(t, ctx.ElementsMatch([]string(nil), []string{}))
+	})
+
+	assert.False(t, r.failed)
+
+	r = record(func(ctx *mesa.Ctx) {
+		assert.False(t, ctx.ElementsMatch([]int{1, 2, 2}, []int{1, 1, 2}))
+	})
+
+	assert.True(t, r.failed)
+	assert.Contains(t, strings.Join(r.errors, "\n"), "TestRecorder/case: elements don't match")
+}
+
+func TestCtx_Regexp(t *testing.T) {
+	r := record(func(ctx *mesa.Ctx) {
+		assert.True(t, ctx.Regexp(`^id=(\d+) user=(\w+)$`, "id=42 user=ada"))
+		assert.True(t, ctx.Regexp(`(?m)^level=warn$`, "level=info\nlevel=warn\n"))
+	})
+
+	assert.False(t, r.failed)
+
+	r = record(func(ctx *mesa.Ctx) {
+		assert.False(t, ctx.Regexp(`^level=warn$`, "level=info\nlevel=warn\n"))
+	})
+
+	assert.True(t, r.failed)
+	assert.Contains(t, strings.Join(r.errors, "\n"), "TestRecorder/case: \"level=info\\nlevel=warn\\n\" doesn't match")
+
+	r = record(func(ctx *mesa.Ctx) {
+		assert.False(t, ctx.Regexp(`(`, "x"))
+	})
+
+	assert.True(t, r.failed)
+	assert.Contains(t, strings.Join(r.errors, "\n"), `invalid pattern "("`)
+}
+
+func TestCtx_Contains(t *testing.T) {
+	r := record(func(ctx *mesa.Ctx) {
+		assert.True(t, ctx.Contains("hello world", "lo w"))
+		assert.True(t, ctx.Contains([]int{1, 2, 3}, 2))
+		assert.True(t, ctx.Contains(map[string]int{"a": 1}, "a"))
+		assert.True(t, ctx.NotContains("hello", "bye"))
+		assert.True(t, ctx.NotContains([]string{"a", "b"}, "c"))
+		assert.True(t, ctx.NotContains(map[string]int{"a": 1}, "b"))
+	})
+
+	assert.False(t, r.failed, r.errors)
+
+	r = record(func(ctx *mesa.Ctx) {
+		assert.False(t, ctx.Contains([]string{"a", "b"}, "c"))
+		assert.False(t, ctx.Contains(map[string]int{"a": 1}, 1))
+		assert.False(t, ctx.NotContains("hello", "ell"))
+	})
+
+	assert.True(t, r.failed)
+	require.Len(t, r.errors, 3)
+	assert.Contains(t, r.errors[0], `TestRecorder/case: []string{"a", "b"} does not contain "c"`)
+	assert.Contains(t, r.errors[1], `TestRecorder/case: map[string]int{"a":1} does not contain 1`)
+	assert.Contains(t, r.errors[2], `TestRecorder/case: "hello" contains "ell"`)
+}
+
+func TestCtx_Len(t *testing.T) {
+	r := record(func(ctx *mesa.Ctx) {
+		assert.True(t, ctx.Len("héllo", 6))
+		assert.True(t, ctx.Len([]int{1, 2}, 2))
+		assert.True(t, ctx.Len(map[string]bool{}, 0))
+	})
+
+	assert.False(t, r.failed, r.errors)
+
+	r = record(func(ctx *mesa.Ctx) {
+		assert.False(t, ctx.Len([]int{1, 2}, 3))
+	})
+
+	assert.True(t, r.failed)
+	assert.Contains(t, r.errors[0], "TestRecorder/case: unexpected length")
+	assert.Contains(t, r.errors[0], `should have 3 item(s), but has 2`)
+}
+
+func TestCtx_NoErr(t *testing.T) {
+	r := record(func(ctx *mesa.Ctx) {
+		ctx.NoErr(nil)
+	})
+
+	assert.False(t, r.failed)
+
+	r = record(func(ctx *mesa.Ctx) {
+		ctx.NoErr(errors.New("boom"))
+		t.Error("NoErr should stop the case")
+	})
+
+	assert.True(t, r.failed)
+	assert.Contains(t, strings.Join(r.errors, "\n"), "TestRecorder/case: unexpected error")
+}
+
+func TestCtx_OK(t *testing.T) {
+	r := record(func(ctx *mesa.Ctx) {
+		assert.True(t, ctx.OK(true, "should pass"))
+	})
+
+	assert.False(t, r.failed)
+
+	r = record(func(ctx *mesa.Ctx) {
+		assert.False(t, ctx.OK(false, "cache should be warm"))
+	})
+
+	assert.True(t, r.failed)
+	assert.Contains(t, strings.Join(r.errors, "\n"), "TestRecorder/case: cache should be warm")
+}
+
+func TestCtx_Errorf(t *testing.T) {
+	r := record(func(ctx *mesa.Ctx) {
+		ctx.Errorf("first %d", 1)
+		ctx.Errorf("second %d", 2)
+	})
+
+	assert.True(t, r.failed)
+	assert.Equal(t, []string{"first 1", "second 2"}, r.errors)
+}
+
+func TestCtx_Fatalf(t *testing.T) {
+	r := record(func(ctx *mesa.Ctx) {
+		ctx.Fatalf("stop %s", "here")
+		t.Error("Fatalf should stop the case")
+	})
+
+	assert.True(t, r.failed)
+	assert.Equal(t, []string{"stop here"}, r.errors)
+}
+
+func TestCtx_Fatalf_Benchmark(t *testing.T) {
+	var continued bool
+
+	testing.Benchmark(func(b *testing.B) {
+		m := mesa.MethodBenchmarkMesa[mesa.Empty, mesa.Empty, mesa.Empty, mesa.Empty]{
+			NewInstance: func(ctx *mesa.Ctx, _ mesa.Empty) mesa.Empty {
+				return nil
+			},
+			Target: func(ctx *mesa.Ctx, _ mesa.Empty, _ mesa.Empty) mesa.Empty {
+				return nil
+			},
+			Check: func(ctx *mesa.Ctx, _ mesa.Empty, _ mesa.Empty, _ mesa.Empty) {
+				ctx.Fatalf("stop")
+				continued = true
+			},
+			Cases: []mesa.MethodBenchmarkCase[mesa.Empty, mesa.Empty, mesa.Empty, mesa.Empty]{
+				{Name: "Fatal"},
+			},
+		}
+
+		m.Run(b)
+	})
+
+	assert.False(t, continued)
+}
+
+func TestCtx_Skip(t *testing.T) {
+	t.Setenv("MESA_TEST_SKIP_ENV", "")
+	t.Setenv("MESA_TEST_SET_ENV", "set")
+
+	var called []string
+
+	m := mesa.FunctionMesa[string, string]{
+		BeforeCall: func(ctx *mesa.Ctx, env string) {
+			if os.Getenv(env) == "" {
+				ctx.Skipf("%s is not set", env)
+			}
+		},
+		Target: func(ctx *mesa.Ctx, env string) string {
+			called = append(called, env)
+			return os.Getenv(env)
+		},
+		Cases: []mesa.FunctionCase[string, string]{
+			{Name: "Unset", Input: "MESA_TEST_SKIP_ENV"},
+			{Name: "Set", Input: "MESA_TEST_SET_ENV", Expected: "set"},
+			{
+				Name:  "Skip",
+				Input: "MESA_TEST_SET_ENV",
+				BeforeCall: func(ctx *mesa.Ctx, env string) {
+					ctx.Skip("not", "needed")
+				},
+				XFail: "skipped cases are not inverted",
+			},
+		},
+	}
+
+	m.Run(t)
+
+	assert.Equal(t, []string{"MESA_TEST_SET_ENV"}, called)
+}
+
+func TestCtx_Skip_Benchmark(t *testing.T) {
+	setBenchtime(t, "10x")
+
+	called := false
+
+	m := mesa.MethodBenchmarkMesa[mesa.Empty, mesa.Empty, int, int]{
+		NewInstance: func(ctx *mesa.Ctx, _ mesa.Empty) mesa.Empty {
+			return nil
+		},
+		BeforeCall: func(ctx *mesa.Ctx, _ mesa.Empty, in int) {
+			ctx.Skip("not supported")
+		},
+		Target: func(ctx *mesa.Ctx, _ mesa.Empty, in int) int {
+			called = true
+			return in
+		},
+		Cases: []mesa.MethodBenchmarkCase[mesa.Empty, mesa.Empty, int, int]{
+			{Name: "Skipped", Input: 1},
+		},
+	}
+
+	testing.Benchmark(m.Run)
+
+	assert.False(t, called)
+}
+
+func TestCtx_Skip_Unsupported(t *testing.T) {
+	r := record(func(ctx *mesa.Ctx) {
+		ctx.Skip("reason")
+		t.Error("Skip should stop the case")
+	})
+
+	assert.True(t, r.failed)
+	assert.Contains(t, strings.Join(r.errors, "\n"), "can't be skipped")
+}
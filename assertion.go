@@ -0,0 +1,131 @@
+package mesa
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// AssertionMode controls whether failing assertions made through ctx.As and ctx.Re stop the case.
+type AssertionMode int
+
+const (
+	// Mixed keeps ctx.As non-fatal and ctx.Re fatal. This is the default.
+	Mixed AssertionMode = iota
+
+	// SoftOnly makes ctx.Re non-fatal, so a failing assertion never stops the case. This avoids aborting a case
+	// halfway through when every failure should be reported. A failure to create the instance still stops the case.
+	SoftOnly
+
+	// HardOnly makes ctx.As fatal, so the first failing assertion stops the case.
+	HardOnly
+)
+
+// setAssertions rebinds the assertion objects of the context to the assertion settings of the suite.
+func (c *Ctx) setAssertions() {
+	t := c.reporter()
+	if c.opts.requireAssertions {
+		t = countT{testingT: t, count: &c.assertions}
+	}
+
+	if c.opts.messagePrefix != "" {
+		t = prefixT{testingT: t, prefix: c.opts.messagePrefix + " " + c.name() + ": "}
+	}
+
+	c.As = assert.New(t)
+	c.Re = require.New(t)
+
+	switch c.opts.assertionMode {
+	case SoftOnly:
+		c.Re = require.New(softT{t})
+	case HardOnly:
+		c.As = assert.New(hardT{t})
+	}
+}
+
+// testingT is a TestingT that can mark its callers as helpers, like *testing.T. The wrappers below embed it without
+// overriding Helper, except for countT, so that the promoted Helper marks the testify assertion that calls it rather
+// than the wrapper, and failures are reported at the line of the caller of the assertion.
+type testingT interface {
+	require.TestingT
+	Helper()
+}
+
+// withHelper returns t as a testingT, with a Helper that does nothing if t has none.
+func withHelper(t require.TestingT) testingT {
+	if h, ok := t.(testingT); ok {
+		return h
+	}
+
+	return noHelperT{t}
+}
+
+// noHelperT is a TestingT without helpers.
+type noHelperT struct {
+	require.TestingT
+}
+
+// Helper does nothing.
+func (noHelperT) Helper() {}
+
+// countT is a TestingT that counts assertions. Every testify assertion marks itself as a helper, so calls to Helper
+// are counted. Helper can't be promoted to count them, so the failure header of a suite with RequireAssertions names
+// the testify assertion rather than its caller, which the Error Trace of testify still includes.
+type countT struct {
+	testingT
+	count *atomic.Int64
+}
+
+// Helper counts an assertion and forwards the call.
+func (c countT) Helper() {
+	c.count.Add(1)
+	c.testingT.Helper()
+}
+
+// failT is a TestingT that counts failures, so that the failures of a hook can be told apart from earlier failures of
+// the test.
+type failT struct {
+	testingT
+	count *atomic.Int64
+}
+
+// Errorf counts and reports the failure.
+func (f failT) Errorf(format string, args ...any) {
+	f.testingT.Helper()
+	f.count.Add(1)
+	f.testingT.Errorf(format, args...)
+}
+
+// prefixT is a TestingT that prepends a prefix to every failure message.
+type prefixT struct {
+	testingT
+	prefix string
+}
+
+// Errorf reports the failure with the prefix prepended.
+func (p prefixT) Errorf(format string, args ...any) {
+	p.testingT.Helper()
+	p.testingT.Errorf("%s%s", p.prefix, fmt.Sprintf(format, args...))
+}
+
+// softT is a TestingT that records failures without stopping the test.
+type softT struct {
+	testingT
+}
+
+// FailNow does nothing since Errorf already marked the test as failed.
+func (softT) FailNow() {}
+
+// hardT is a TestingT that stops the test on the first failure.
+type hardT struct {
+	testingT
+}
+
+// Errorf reports the failure and stops the test.
+func (h hardT) Errorf(format string, args ...any) {
+	h.testingT.Helper()
+	h.testingT.Errorf(format, args...)
+	h.testingT.FailNow()
+}
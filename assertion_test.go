@@ -0,0 +1,303 @@
+package mesa_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/a20r/mesa"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// runFailingChecks runs a case whose Check makes two failing assertions, first with check, and returns the output of
+// the test process.
+func runFailingChecks(t *testing.T, mode mesa.AssertionMode, check func(ctx *mesa.Ctx, msg string)) (string, bool) {
+	out, ok, err := runInSubprocess(t, func(t *testing.T) {
+		m := mesa.FunctionMesa[mesa.Empty, mesa.Empty]{
+			AssertionMode: mode,
+			Check: func(ctx *mesa.Ctx, _ mesa.Empty, _ mesa.Empty) {
+				check(ctx, "first failure")
+				check(ctx, "second failure")
+			},
+			Cases: []mesa.FunctionCase[mesa.Empty, mesa.Empty]{
+				{Name: "Failing"},
+			},
+		}
+
+		m.Run(t)
+	})
+	if ok {
+		require.Error(t, err, "expected test to fail:\n%s", out)
+	}
+
+	return out, ok
+}
+
+func TestAssertionMode(t *testing.T) {
+	t.Run("Mixed", func(t *testing.T) {
+		out, ok := runFailingChecks(t, mesa.Mixed, func(ctx *mesa.Ctx, msg string) {
+			ctx.Re.Fail(msg)
+		})
+		if ok {
+			assert.Contains(t, out, "first failure")
+			assert.NotContains(t, out, "second failure")
+		}
+	})
+
+	t.Run("SoftOnly", func(t *testing.T) {
+		out, ok := runFailingChecks(t, mesa.SoftOnly, func(ctx *mesa.Ctx, msg string) {
+			ctx.Re.Fail(msg)
+		})
+		if ok {
+			assert.Contains(t, out, "first failure")
+			assert.Contains(t, out, "second failure")
+		}
+	})
+
+	t.Run("HardOnly", func(t *testing.T) {
+		out, ok := runFailingChecks(t, mesa.HardOnly, func(ctx *mesa.Ctx, msg string) {
+			ctx.As.Fail(msg)
+		})
+		if ok {
+			assert.Contains(t, out, "first failure")
+			assert.NotContains(t, out, "second failure")
+		}
+	})
+}
+
+func TestAssertionMode_Init(t *testing.T) {
+	out, ok, err := runInSubprocess(t, func(t *testing.T) {
+		m := mesa.FunctionMesa[mesa.Empty, mesa.Empty]{
+			AssertionMode: mesa.SoftOnly,
+			Init: func(ctx *mesa.Ctx) {
+				ctx.Re.Fail("first failure")
+				ctx.Re.Fail("second failure")
+			},
+			Target: func(ctx *mesa.Ctx, _ mesa.Empty) mesa.Empty {
+				t.Log("case ran")
+				return nil
+			},
+			Cases: []mesa.FunctionCase[mesa.Empty, mesa.Empty]{
+				{Name: "Case"},
+			},
+		}
+
+		m.Run(t)
+	})
+	if !ok {
+		return
+	}
+
+	require.Error(t, err, out)
+	assert.Contains(t, out, "first failure")
+	assert.Contains(t, out, "second failure")
+	assert.Contains(t, out, "Init failed, no cases were run")
+	assert.NotContains(t, out, "case ran")
+}
+
+func TestFunctionCase_ContinueOnRequireFailure(t *testing.T) {
+	out, ok, err := runInSubprocess(t, func(t *testing.T) {
+		m := mesa.FunctionMesa[string, mesa.Empty]{
+			Check: func(ctx *mesa.Ctx, name string, _ mesa.Empty) {
+				ctx.Re.Fail(name + ": first failure")
+				t.Log(name + ": Check continued")
+			},
+			Cases: []mesa.FunctionCase[string, mesa.Empty]{
+				{Name: "Continue", Input: "continue", ContinueOnRequireFailure: true},
+				{Name: "Stop", Input: "stop"},
+			},
+		}
+
+		m.Run(t)
+	})
+	if !ok {
+		return
+	}
+
+	require.Error(t, err, "expected test to fail:\n%s", out)
+	assert.Contains(t, out, "continue: first failure")
+	assert.Contains(t, out, "continue: Check continued")
+	assert.Contains(t, out, "stop: first failure")
+	assert.NotContains(t, out, "stop: Check continued")
+}
+
+func TestSoftOnly_GuardedFailures(t *testing.T) {
+	out, ok, err := runInSubprocess(t, func(t *testing.T) {
+		m := mesa.MethodMesa[*strings.Builder, error, string, string]{
+			AssertionMode: mesa.SoftOnly,
+			NewInstanceErr: func(ctx *mesa.Ctx, err error) (*strings.Builder, error) {
+				return &strings.Builder{}, err
+			},
+			Target: func(ctx *mesa.Ctx, inst *strings.Builder, in string) string {
+				inst.WriteString(in)
+				return inst.String()
+			},
+			Check: func(ctx *mesa.Ctx, inst *strings.Builder, in string, out string) {
+				ctx.ReadAll(nil)
+				mesa.Resolve[int](ctx, "missing")
+				t.Log("Check continued")
+			},
+			Cases: []mesa.MethodCase[*strings.Builder, error, string, string]{
+				{Name: "Helpers", Input: "a"},
+				{Name: "No instance", Fields: errors.New("connection refused"), Input: "b"},
+			},
+		}
+
+		m.Run(t)
+	})
+	if !ok {
+		return
+	}
+
+	require.Error(t, err, out)
+	assert.Contains(t, out, "cannot read from a nil reader")
+	assert.Contains(t, out, "no value provided for key missing")
+	assert.Contains(t, out, "Check continued")
+	assert.Contains(t, out, "failed to create instance")
+	assert.Equal(t, 1, strings.Count(out, "Check continued"), "the case without an instance must stop")
+	assert.NotContains(t, out, "panic")
+}
+
+func TestMessagePrefix(t *testing.T) {
+	expectFailure(t, func(t *testing.T) {
+		m := mesa.FunctionMesa[int, int]{
+			MessagePrefix: "[payments]",
+			AssertionMode: mesa.SoftOnly,
+			Target: func(ctx *mesa.Ctx, in int) int {
+				return in
+			},
+			Check: func(ctx *mesa.Ctx, in int, out int) {
+				ctx.Re.Equal(in+1, out)
+			},
+			Cases: []mesa.FunctionCase[int, int]{
+				{Name: "Failing", Input: 1},
+			},
+		}
+
+		m.Run(t)
+	}, "[payments] TestMessagePrefix/Failing: ")
+}
+
+func TestMessagePrefix_Suite(t *testing.T) {
+	out, ok, err := runInSubprocess(t, func(t *testing.T) {
+		m := mesa.FunctionMesa[int, int]{
+			MessagePrefix: "[payments]",
+			Init: func(ctx *mesa.Ctx) {
+				ctx.As.Fail("init broke")
+			},
+			Teardown: func(ctx *mesa.Ctx) {
+				ctx.As.Fail("teardown broke")
+			},
+			Cases: []mesa.FunctionCase[int, int]{
+				{Name: "Case", Input: 1},
+			},
+		}
+
+		m.Run(t)
+	})
+	if !ok {
+		return
+	}
+
+	require.Error(t, err, out)
+	assert.Contains(t, out, "init broke")
+	assert.Contains(t, out, "teardown broke")
+	assert.Contains(t, out, "[payments] TestMessagePrefix_Suite: Init failed, no cases were run")
+	assert.Equal(t, 3, strings.Count(out, "[payments] TestMessagePrefix_Suite: "), out)
+}
+
+func TestRequireAssertions(t *testing.T) {
+	m := mesa.FunctionMesa[int, int]{
+		RequireAssertions: true,
+		MessagePrefix:     "[math]",
+		AssertionMode:     mesa.SoftOnly,
+		Target: func(ctx *mesa.Ctx, in int) int {
+			return in * 2
+		},
+		Cases: []mesa.FunctionCase[int, int]{
+			{
+				Name:  "Asserting Check",
+				Input: 1,
+				Check: func(ctx *mesa.Ctx, in int, out int) {
+					ctx.Re.Equal(2, out)
+				},
+			},
+			{
+				Name:     "Expected output",
+				Input:    2,
+				Expected: 4,
+				Check:    func(ctx *mesa.Ctx, in int, out int) {},
+			},
+			{
+				Name:  "No Check",
+				Input: 3,
+			},
+		},
+	}
+
+	m.Run(t)
+}
+
+func TestRequireAssertions_EmptyCheck(t *testing.T) {
+	expectFailure(t, func(t *testing.T) {
+		m := mesa.FunctionMesa[int, int]{
+			RequireAssertions: true,
+			Target: func(ctx *mesa.Ctx, in int) int {
+				return in * 2
+			},
+			Cases: []mesa.FunctionCase[int, int]{
+				{
+					Name:  "Empty Check",
+					Input: 1,
+					Check: func(ctx *mesa.Ctx, in int, out int) {},
+				},
+			},
+		}
+
+		m.Run(t)
+	}, "TestRequireAssertions_EmptyCheck/Empty_Check: Check made no assertions")
+}
+
+func TestAssertionWrappers_FailureLine(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(m *mesa.FunctionMesa[int, int])
+	}{
+		{name: "Default", setup: func(m *mesa.FunctionMesa[int, int]) {}},
+		{name: "MessagePrefix", setup: func(m *mesa.FunctionMesa[int, int]) { m.MessagePrefix = "[payments]" }},
+		{name: "SoftOnly", setup: func(m *mesa.FunctionMesa[int, int]) { m.AssertionMode = mesa.SoftOnly }},
+		{name: "HardOnly", setup: func(m *mesa.FunctionMesa[int, int]) { m.AssertionMode = mesa.HardOnly }},
+		{name: "Init", setup: func(m *mesa.FunctionMesa[int, int]) {
+			m.Init = func(ctx *mesa.Ctx) {
+				ctx.As.Equal(1, 2) // failure line
+			}
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, ok, err := runInSubprocess(t, func(t *testing.T) {
+				m := mesa.FunctionMesa[int, int]{
+					Target: func(ctx *mesa.Ctx, in int) int {
+						return in
+					},
+					Check: func(ctx *mesa.Ctx, in int, out int) {
+						ctx.As.Equal(1, out) // failure line
+					},
+					Cases: []mesa.FunctionCase[int, int]{{Name: "Case", Input: 2}},
+				}
+				tt.setup(&m)
+
+				m.Run(t)
+			})
+			if !ok {
+				return
+			}
+
+			require.Error(t, err, out)
+			assert.Regexp(t, `(?m)^\s+assertion_test\.go:\d+: `, out, "the failure is reported at the assertion")
+			assert.NotRegexp(t, `(?m)^\s+(assertion|assertions|xfail)\.go:\d+: `, out)
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package mesa
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+// updateBaselineEnv is the environment variable that makes RunWithBaseline rewrite the baselines with the current
+// results, like updateEnv does for snapshots.
+const updateBaselineEnv = "MESA_UPDATE_BASELINE"
+
+// RunWithBaseline runs the benchmark cases and compares the ns/op of each case to the baseline stored as JSON at
+// baselinePath, keyed by case name. The benchmark fails if a case is slower than its baseline by more than tolerance,
+// which is a fraction of the baseline, e.g. 0.1 allows cases to be 10% slower. The results are written to the
+// baseline instead when it does not exist yet or the benchmarks are run with MESA_UPDATE_BASELINE=1.
+func (m MethodBenchmarkMesa[Inst, F, I, O]) RunWithBaseline(b *testing.B, baselinePath string, tolerance float64) {
+	results := make(map[string]float64)
+
+	m.run(b, func(name string, r benchResult) {
+		results[name] = r.nsPerOp
+	})
+
+	baseline, err := readBaseline(baselinePath)
+
+	switch {
+	case errors.Is(err, fs.ErrNotExist), envSet(updateBaselineEnv):
+		if baseline == nil {
+			baseline = make(map[string]float64)
+		}
+
+		for name, nsPerOp := range results {
+			baseline[name] = nsPerOp
+		}
+
+		if err := writeBaseline(baselinePath, baseline); err != nil {
+			b.Fatalf("failed to write benchmark baseline %s: %v", baselinePath, err)
+		}
+
+		return
+	case err != nil:
+		b.Fatalf("failed to read benchmark baseline %s: %v", baselinePath, err)
+	}
+
+	names := make([]string, 0, len(results))
+	for name := range results {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		base, ok := baseline[name]
+		if !ok {
+			b.Logf("no baseline for case %q, run with MESA_UPDATE_BASELINE=1 to add it", name)
+			continue
+		}
+
+		if limit := base * (1 + tolerance); results[name] > limit {
+			b.Errorf("case %q regressed: %.2f ns/op exceeds baseline of %.2f ns/op with tolerance %.0f%%", name,
+				results[name], base, tolerance*100)
+		}
+	}
+}
+
+// readBaseline reads the baseline file at path.
+func readBaseline(path string) (map[string]float64, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var baseline map[string]float64
+	if err := json.Unmarshal(data, &baseline); err != nil {
+		return nil, err
+	}
+
+	return baseline, nil
+}
+
+// writeBaseline writes the baseline as indented JSON to path, creating its directory if needed.
+func writeBaseline(path string, baseline map[string]float64) error {
+	data, err := json.MarshalIndent(baseline, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
@@ -0,0 +1,104 @@
+package mesa_test
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/a20r/mesa"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sumBenchmark() mesa.MethodBenchmarkMesa[mesa.Empty, mesa.Empty, []int, int] {
+	return mesa.MethodBenchmarkMesa[mesa.Empty, mesa.Empty, []int, int]{
+		NewInstance: func(ctx *mesa.Ctx, _ mesa.Empty) mesa.Empty {
+			return nil
+		},
+		Target: func(ctx *mesa.Ctx, _ mesa.Empty, in []int) int {
+			sum := 0
+			for _, n := range in {
+				sum += n
+			}
+			return sum
+		},
+		Cases: []mesa.MethodBenchmarkCase[mesa.Empty, mesa.Empty, []int, int]{
+			{Name: "Small", Input: []int{1, 2, 3}},
+			{Name: "Large", Input: make([]int, 1000)},
+		},
+	}
+}
+
+// setBenchtime sets the -benchtime flag used by testing.Benchmark until the test finishes.
+func setBenchtime(t *testing.T, d string) {
+	benchtime := flag.Lookup("test.benchtime")
+	prev := benchtime.Value.String()
+	require.NoError(t, benchtime.Value.Set(d))
+	t.Cleanup(func() { _ = benchtime.Value.Set(prev) })
+}
+
+// runBaseline runs the sum benchmark against the baseline at path and reports whether it failed. Each case runs a
+// fixed number of iterations to keep the test fast.
+func runBaseline(t *testing.T, path string, tolerance float64) bool {
+	setBenchtime(t, "100x")
+
+	failed := false
+
+	testing.Benchmark(func(b *testing.B) {
+		sumBenchmark().RunWithBaseline(b, path, tolerance)
+		failed = failed || b.Failed()
+	})
+
+	return failed
+}
+
+func TestRunWithBaseline(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bench", "baseline.json")
+
+	assert.False(t, runBaseline(t, path, 0.1), "missing baseline should be written")
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	var baseline map[string]float64
+	require.NoError(t, json.Unmarshal(data, &baseline))
+	assert.Contains(t, baseline, "Small")
+	assert.Contains(t, baseline, "Large")
+
+	for name := range baseline {
+		baseline[name] *= 1000
+	}
+
+	data, err = json.Marshal(baseline)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, data, 0o644))
+
+	assert.False(t, runBaseline(t, path, 10), "cases within tolerance should pass")
+}
+
+func TestRunWithBaseline_Regression(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "baseline.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"Small": 0.000001, "Large": 0.000001}`), 0o644))
+
+	assert.True(t, runBaseline(t, path, 0.1))
+}
+
+func TestRunWithBaseline_Update(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "baseline.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"Small": 0.000001, "Large": 0.000001, "Removed": 1}`), 0o644))
+
+	t.Setenv("MESA_UPDATE_BASELINE", "1")
+
+	assert.False(t, runBaseline(t, path, 0.1), "the baseline should be rewritten instead of compared")
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	var baseline map[string]float64
+	require.NoError(t, json.Unmarshal(data, &baseline))
+	assert.Greater(t, baseline["Small"], 0.000001)
+	assert.Greater(t, baseline["Large"], 0.000001)
+	assert.Equal(t, 1.0, baseline["Removed"], "cases that didn't run keep their baseline")
+}
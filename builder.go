@@ -0,0 +1,66 @@
+package mesa
+
+import "testing"
+
+// Type assertion to ensure the builder adheres to the Mesa interface
+var _ Mesa = (*FuncBuilder[any, any])(nil)
+
+// FuncBuilder is a fluent alternative to the FunctionMesa struct literal. Cases are accumulated with Case and
+// the builder lowers to a FunctionMesa when it is run.
+type FuncBuilder[InputType, OutputType any] struct {
+	m FunctionMesa[InputType, OutputType]
+}
+
+// Func creates a new builder for the provided target function.
+func Func[InputType, OutputType any](
+	target func(ctx *Ctx, in InputType) OutputType,
+) *FuncBuilder[InputType, OutputType] {
+	return &FuncBuilder[InputType, OutputType]{
+		m: FunctionMesa[InputType, OutputType]{Target: target},
+	}
+}
+
+// Case adds a case that asserts the output of the target is equal to expected using the DiffMode of the suite.
+// Check, Before and Skip modify the most recently added case.
+func (f *FuncBuilder[I, O]) Case(name string, in I, expected O) *FuncBuilder[I, O] {
+	f.m.Cases = append(f.m.Cases, ExpectEqual(name, in, expected))
+	return f
+}
+
+// Check replaces the equality check of the last case with the provided function.
+func (f *FuncBuilder[I, O]) Check(check func(ctx *Ctx, in I, out O)) *FuncBuilder[I, O] {
+	f.last("Check").Check = check
+	return f
+}
+
+// Before sets the function called before the target function for the last case.
+func (f *FuncBuilder[I, O]) Before(before func(ctx *Ctx, in I)) *FuncBuilder[I, O] {
+	f.last("Before").BeforeCall = before
+	return f
+}
+
+// Skip skips the last case with the given reason.
+func (f *FuncBuilder[I, O]) Skip(reason string) *FuncBuilder[I, O] {
+	f.last("Skip").Skip = reason
+	return f
+}
+
+// Mesa returns the FunctionMesa the builder lowers to.
+func (f *FuncBuilder[I, O]) Mesa() FunctionMesa[I, O] {
+	return f.m
+}
+
+// Run runs the accumulated cases.
+func (f *FuncBuilder[I, O]) Run(t *testing.T) {
+	f.m.Run(t)
+}
+
+// last returns the most recently added case. It panics if no case has been added since that is a mistake in the
+// test definition rather than a test failure.
+func (f *FuncBuilder[I, O]) last(method string) *FunctionCase[I, O] {
+	if len(f.m.Cases) == 0 {
+		panic("mesa: " + method + " called before Case")
+	}
+
+	return &f.m.Cases[len(f.m.Cases)-1]
+}
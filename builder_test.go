@@ -0,0 +1,46 @@
+package mesa_test
+
+import (
+	"testing"
+
+	"github.com/a20r/mesa"
+)
+
+func TestFunc(t *testing.T) {
+	var before []int
+
+	mesa.Func(func(ctx *mesa.Ctx, n int) int {
+		return n * 2
+	}).
+		Case("Double 0", 0, 0).
+		Case("Double 2", 2, 4).
+		Before(func(ctx *mesa.Ctx, n int) {
+			before = append(before, n)
+		}).
+		Case("Double 3", 3, 0).
+		Check(func(ctx *mesa.Ctx, n int, out int) {
+			ctx.As.Equal(6, out)
+		}).
+		Case("Skipped", 4, 0).
+		Skip("not ready").
+		Run(t)
+
+	if len(before) != 1 || before[0] != 2 {
+		t.Errorf("expected Before to only run for the second case, got %v", before)
+	}
+}
+
+func TestFunc_Mesa(t *testing.T) {
+	m := mesa.Func(func(ctx *mesa.Ctx, s string) int {
+		return len(s)
+	}).
+		Case("Empty", "", 0).
+		Case("Hello", "hello", 5).
+		Mesa()
+
+	if len(m.Cases) != 2 {
+		t.Fatalf("expected 2 cases, got %d", len(m.Cases))
+	}
+
+	m.Run(t)
+}
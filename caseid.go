@@ -0,0 +1,25 @@
+package mesa
+
+import (
+	"fmt"
+	"hash/fnv"
+)
+
+// CaseID returns an identifier of the case that is stable across runs, so that external tools such as flaky test
+// trackers can correlate the results of a case. It is the ID of the case when it sets one, and otherwise a hash of the
+// name of the suite and the name of the case, which changes when either is renamed. It is empty outside of a case,
+// e.g. in Init.
+func (c *Ctx) CaseID() string {
+	if c.caseID != "" {
+		return c.caseID
+	}
+
+	if c.suite == nil {
+		return ""
+	}
+
+	h := fnv.New64a()
+	h.Write([]byte(c.suite.name() + "/" + c.caseName))
+
+	return fmt.Sprintf("%016x", h.Sum64())
+}
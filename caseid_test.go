@@ -0,0 +1,47 @@
+package mesa_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/a20r/mesa"
+)
+
+func TestCtx_CaseID(t *testing.T) {
+	var (
+		ids      = map[string][]string{}
+		suiteIDs []string
+	)
+
+	m := mesa.FunctionMesa[int, int]{
+		Init: func(ctx *mesa.Ctx) {
+			suiteIDs = append(suiteIDs, ctx.CaseID())
+		},
+		Target: func(ctx *mesa.Ctx, in int) int {
+			return in
+		},
+		Check: func(ctx *mesa.Ctx, in int, out int) {
+			ids[ctx.T().Name()] = append(ids[ctx.T().Name()], ctx.CaseID())
+		},
+		Cases: []mesa.FunctionCase[int, int]{
+			{Name: "One", Input: 1},
+			{Name: "Two", Input: 2},
+			{Name: "Renamed", ID: "legacy-three", Input: 3},
+		},
+	}
+
+	// Run the suite twice, as separate runs would.
+	m.Run(t)
+	m.Run(t)
+
+	assert.Equal(t, []string{"", ""}, suiteIDs)
+
+	one, two := ids[t.Name()+"/One"], ids[t.Name()+"/Two"]
+	assert.Len(t, one, 1)
+	assert.Len(t, ids[t.Name()+"/One#01"], 1)
+	assert.Equal(t, one[0], ids[t.Name()+"/One#01"][0], "the ID must be stable across runs")
+	assert.Regexp(t, `^[0-9a-f]{16}$`, one[0])
+	assert.NotEqual(t, one[0], two[0], "the ID must be unique per case")
+	assert.Equal(t, []string{"legacy-three"}, ids[t.Name()+"/Renamed"])
+}
@@ -0,0 +1,149 @@
+package mesa
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+)
+
+// ExpectEqual returns a case whose Check asserts that the output of the target is equal to want. Equality is asserted
+// with ctx.As.Equal unless the suite sets a different DiffMode. This lets a whole table be written as a list of
+// ExpectEqual calls.
+func ExpectEqual[InputType, OutputType any](
+	name string,
+	in InputType,
+	want OutputType,
+) FunctionCase[InputType, OutputType] {
+	return FunctionCase[InputType, OutputType]{
+		Name:  name,
+		Input: in,
+		Check: func(ctx *Ctx, _ InputType, out OutputType) {
+			ctx.equal(want, out)
+		},
+	}
+}
+
+// SnapshotCase returns a case whose Check marshals the output of the target to indented JSON and compares it to the
+// snapshot of the case with Ctx.Snapshot. Snapshots are created and updated by running the tests with
+// MESA_UPDATE=1, which turns a table into a list of inputs without hand written expectations.
+func SnapshotCase[InputType, OutputType any](name string, in InputType) FunctionCase[InputType, OutputType] {
+	return FunctionCase[InputType, OutputType]{
+		Name:  name,
+		Input: in,
+		Check: func(ctx *Ctx, _ InputType, out OutputType) {
+			data, err := json.MarshalIndent(out, "", "  ")
+			ctx.Re.NoError(err, "failed to marshal output to JSON")
+
+			if err != nil {
+				return
+			}
+
+			ctx.Snapshot(append(data, '\n'))
+		},
+	}
+}
+
+// ClosureCall is a call made by ClosureCase to the function returned by the target, along with the result it is
+// expected to return.
+type ClosureCall[ArgType, ResultType any] struct {
+	// Arg is passed to the returned function.
+	Arg ArgType
+
+	// Expected is the result the returned function must return for Arg.
+	Expected ResultType
+}
+
+// ClosureCase returns a case for a target that returns a function, such as a constructor of handlers or counters. Its
+// Check calls the returned function with the Arg of every call in order, so state kept by the function carries over
+// between calls, and asserts that each result is equal to the Expected result of the call. Failures name the index of
+// the call.
+func ClosureCase[InputType, ArgType, ResultType any](
+	name string,
+	in InputType,
+	calls ...ClosureCall[ArgType, ResultType],
+) FunctionCase[InputType, func(ArgType) ResultType] {
+	return FunctionCase[InputType, func(ArgType) ResultType]{
+		Name:  name,
+		Input: in,
+		Check: func(ctx *Ctx, _ InputType, fn func(ArgType) ResultType) {
+			ctx.Re.NotNil(fn, "target returned a nil function")
+
+			if fn == nil {
+				return
+			}
+
+			for i, call := range calls {
+				ctx.equal(call.Expected, fn(call.Arg), "call %d with %#v", i, call.Arg)
+			}
+		},
+	}
+}
+
+// CasesFromMap returns one case per entry of m, using the key as the Name and the value as the Input of the case.
+// Every case shares check. Cases are sorted by name so subtests run in a stable order.
+func CasesFromMap[InputType, OutputType any](
+	m map[string]InputType,
+	check func(ctx *Ctx, in InputType, out OutputType),
+) []FunctionCase[InputType, OutputType] {
+	cases := make([]FunctionCase[InputType, OutputType], 0, len(m))
+	for _, name := range sortedKeys(m) {
+		cases = append(cases, FunctionCase[InputType, OutputType]{
+			Name:  name,
+			Input: m[name],
+			Check: check,
+		})
+	}
+
+	return cases
+}
+
+// MethodCasesFromMap is the MethodMesa variant of CasesFromMap.
+func MethodCasesFromMap[InstanceType, FieldsType, InputType, OutputType any](
+	m map[string]InputType,
+	check func(ctx *Ctx, inst InstanceType, in InputType, out OutputType),
+) []MethodCase[InstanceType, FieldsType, InputType, OutputType] {
+	cases := make([]MethodCase[InstanceType, FieldsType, InputType, OutputType], 0, len(m))
+	for _, name := range sortedKeys(m) {
+		cases = append(cases, MethodCase[InstanceType, FieldsType, InputType, OutputType]{
+			Name:  name,
+			Input: m[name],
+			Check: check,
+		})
+	}
+
+	return cases
+}
+
+// SizeCases returns one benchmark case per size, named size-<n>, whose input is built by calling build with the size
+// when the case starts. The size is also used as the Bytes of the case so throughput is reported, which can be reset to
+// zero when the size isn't a number of bytes.
+func SizeCases[InstanceType, FieldsType, InputType, OutputType any](
+	sizes []int,
+	build func(n int) InputType,
+) []MethodBenchmarkCase[InstanceType, FieldsType, InputType, OutputType] {
+	cases := make([]MethodBenchmarkCase[InstanceType, FieldsType, InputType, OutputType], len(sizes))
+	for i, n := range sizes {
+		n := n
+		cases[i] = MethodBenchmarkCase[InstanceType, FieldsType, InputType, OutputType]{
+			Name:  "size-" + strconv.Itoa(n),
+			Bytes: int64(n),
+			InputFn: func(_ *Ctx, _ InstanceType) InputType {
+				return build(n)
+			},
+		}
+	}
+
+	return cases
+}
+
+// sortedKeys returns the keys of m in ascending order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
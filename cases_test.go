@@ -0,0 +1,366 @@
+package mesa_test
+
+import (
+	"hash/crc32"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/a20r/mesa"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpectEqual(t *testing.T) {
+	m := mesa.FunctionMesa[string, string]{
+		Target: func(ctx *mesa.Ctx, in string) string {
+			return strings.ToUpper(in)
+		},
+		Cases: []mesa.FunctionCase[string, string]{
+			mesa.ExpectEqual("Empty", "", ""),
+			mesa.ExpectEqual("Lower", "abc", "ABC"),
+			mesa.ExpectEqual("Mixed", "aBc", "ABC"),
+		},
+	}
+
+	m.Run(t)
+}
+
+func TestExpectEqual_Mismatch(t *testing.T) {
+	expectFailure(t, func(t *testing.T) {
+		m := mesa.FunctionMesa[string, string]{
+			Target: func(ctx *mesa.Ctx, in string) string {
+				return in
+			},
+			Cases: []mesa.FunctionCase[string, string]{
+				mesa.ExpectEqual("Identity", "abc", "ABC"),
+			},
+		}
+
+		m.Run(t)
+	}, "--- FAIL: TestExpectEqual_Mismatch/Identity")
+}
+
+type user struct {
+	ID    int      `json:"id"`
+	Name  string   `json:"name"`
+	Roles []string `json:"roles,omitempty"`
+}
+
+func newUser(id int, name string, admin bool) user {
+	u := user{ID: id, Name: strings.TrimSpace(name)}
+	if admin {
+		u.Roles = append(u.Roles, "admin")
+	}
+
+	return u
+}
+
+type userInput struct {
+	id    int
+	name  string
+	admin bool
+}
+
+func TestSnapshotCase(t *testing.T) {
+	m := mesa.FunctionMesa[userInput, user]{
+		Target: func(ctx *mesa.Ctx, in userInput) user {
+			return newUser(in.id, in.name, in.admin)
+		},
+		Cases: []mesa.FunctionCase[userInput, user]{
+			mesa.SnapshotCase[userInput, user]("Admin", userInput{id: 1, name: " ada ", admin: true}),
+			mesa.SnapshotCase[userInput, user]("Member", userInput{id: 2, name: "bob"}),
+		},
+	}
+
+	m.Run(t)
+}
+
+func TestSnapshotCase_Mismatch(t *testing.T) {
+	expectFailure(t, func(t *testing.T) {
+		m := mesa.FunctionMesa[userInput, user]{
+			Target: func(ctx *mesa.Ctx, in userInput) user {
+				return newUser(in.id, in.name, in.admin)
+			},
+			Cases: []mesa.FunctionCase[userInput, user]{
+				mesa.SnapshotCase[userInput, user]("Changed", userInput{id: 3, name: "carol"}),
+				mesa.SnapshotCase[userInput, user]("Missing", userInput{id: 4, name: "dan"}),
+			},
+		}
+
+		m.Run(t)
+	},
+		"output does not match snapshot testdata/snapshots/TestSnapshotCase_Mismatch/Changed.snap",
+		"snapshot testdata/snapshots/TestSnapshotCase_Mismatch/Missing.snap does not exist",
+	)
+}
+
+func TestCasesFromMap(t *testing.T) {
+	cases := mesa.CasesFromMap(map[string]string{
+		"Lower": "abc",
+		"Mixed": "aBc",
+		"Empty": "",
+	}, func(ctx *mesa.Ctx, in string, out string) {
+		ctx.As.Equal(strings.ToUpper(in), out)
+	})
+
+	names := make([]string, len(cases))
+	for i, c := range cases {
+		names[i] = c.Name
+	}
+
+	assert.Equal(t, []string{"Empty", "Lower", "Mixed"}, names)
+
+	m := mesa.FunctionMesa[string, string]{
+		Target: func(ctx *mesa.Ctx, in string) string {
+			return strings.ToUpper(in)
+		},
+		Cases: cases,
+	}
+
+	m.Run(t)
+}
+
+func TestMethodCasesFromMap(t *testing.T) {
+	m := mesa.MethodMesa[*strings.Builder, mesa.Empty, string, int]{
+		NewInstance: func(ctx *mesa.Ctx, _ mesa.Empty) *strings.Builder {
+			return &strings.Builder{}
+		},
+		Target: func(ctx *mesa.Ctx, inst *strings.Builder, in string) int {
+			n, _ := inst.WriteString(in)
+			return n
+		},
+		Cases: mesa.MethodCasesFromMap[*strings.Builder, mesa.Empty](map[string]string{
+			"Empty": "",
+			"Word":  "word",
+		}, func(ctx *mesa.Ctx, inst *strings.Builder, in string, out int) {
+			ctx.As.Equal(len(in), out)
+			ctx.As.Equal(in, inst.String())
+		}),
+	}
+
+	m.Run(t)
+}
+
+func TestSizeCases(t *testing.T) {
+	cases := mesa.SizeCases[mesa.Empty, mesa.Empty, []byte, uint32]([]int{16, 1024}, func(n int) []byte {
+		return make([]byte, n)
+	})
+
+	if assert.Len(t, cases, 2) {
+		assert.Equal(t, "size-16", cases[0].Name)
+		assert.Equal(t, int64(16), cases[0].Bytes)
+		assert.Len(t, cases[0].InputFn(nil, nil), 16)
+
+		assert.Equal(t, "size-1024", cases[1].Name)
+		assert.Equal(t, int64(1024), cases[1].Bytes)
+		assert.Len(t, cases[1].InputFn(nil, nil), 1024)
+	}
+}
+
+func BenchmarkSizeCases(b *testing.B) {
+	m := mesa.MethodBenchmarkMesa[mesa.Empty, mesa.Empty, []byte, uint32]{
+		NewInstance: func(ctx *mesa.Ctx, _ mesa.Empty) mesa.Empty {
+			return nil
+		},
+		Target: func(ctx *mesa.Ctx, _ mesa.Empty, in []byte) uint32 {
+			return crc32.ChecksumIEEE(in)
+		},
+		Cases: mesa.SizeCases[mesa.Empty, mesa.Empty, []byte, uint32]([]int{64, 4096}, func(n int) []byte {
+			return make([]byte, n)
+		}),
+	}
+
+	m.Run(b)
+}
+
+func TestFunctionMesa_Append(t *testing.T) {
+	upper := func(ctx *mesa.Ctx, in string) string {
+		return strings.ToUpper(in)
+	}
+
+	ascii := mesa.FunctionMesa[string, string]{
+		Target: upper,
+		Cases: []mesa.FunctionCase[string, string]{
+			mesa.ExpectEqual("Lower", "abc", "ABC"),
+		},
+	}
+
+	unicode := mesa.FunctionMesa[string, string]{
+		Cases: []mesa.FunctionCase[string, string]{
+			mesa.ExpectEqual("Accented", "éa", "ÉA"),
+		},
+	}
+
+	m := ascii.Append(unicode)
+
+	assert.Len(t, ascii.Cases, 1)
+	assert.Len(t, m.Cases, 2)
+
+	m.Run(t)
+}
+
+func TestMethodMesa_Append_DuplicateNames(t *testing.T) {
+	expectFailure(t, func(t *testing.T) {
+		newBuilder := func(ctx *mesa.Ctx, _ mesa.Empty) *strings.Builder {
+			return &strings.Builder{}
+		}
+
+		a := mesa.MethodMesa[*strings.Builder, mesa.Empty, string, mesa.Empty]{
+			NewInstance: newBuilder,
+			Cases: []mesa.MethodCase[*strings.Builder, mesa.Empty, string, mesa.Empty]{
+				{Name: "Write", Input: "a"},
+			},
+		}
+
+		b := mesa.MethodMesa[*strings.Builder, mesa.Empty, string, mesa.Empty]{
+			Cases: []mesa.MethodCase[*strings.Builder, mesa.Empty, string, mesa.Empty]{
+				{Name: "Write", Input: "b"},
+			},
+		}
+
+		a.Append(b).Run(t)
+	}, `duplicate case name "Write"`)
+}
+
+func adder(base int) func(int) int {
+	return func(n int) int {
+		base += n
+		return base
+	}
+}
+
+func TestClosureCase(t *testing.T) {
+	m := mesa.FunctionMesa[int, func(int) int]{
+		Target: func(ctx *mesa.Ctx, base int) func(int) int {
+			return adder(base)
+		},
+		Cases: []mesa.FunctionCase[int, func(int) int]{
+			mesa.ClosureCase("State carries over", 10,
+				mesa.ClosureCall[int, int]{Arg: 1, Expected: 11},
+				mesa.ClosureCall[int, int]{Arg: 2, Expected: 13},
+			),
+			mesa.ClosureCase[int, int, int]("No calls", 0),
+		},
+	}
+
+	m.Run(t)
+}
+
+func TestClosureCase_Failure(t *testing.T) {
+	expectFailure(t, func(t *testing.T) {
+		m := mesa.FunctionMesa[int, func(int) int]{
+			Target: func(ctx *mesa.Ctx, base int) func(int) int {
+				return adder(base)
+			},
+			Cases: []mesa.FunctionCase[int, func(int) int]{
+				mesa.ClosureCase("Wrong second call", 10,
+					mesa.ClosureCall[int, int]{Arg: 1, Expected: 11},
+					mesa.ClosureCall[int, int]{Arg: 2, Expected: 12},
+				),
+			},
+		}
+
+		m.Run(t)
+	}, "call 1 with 2")
+}
+
+func TestClosureCase_Nil(t *testing.T) {
+	expectFailure(t, func(t *testing.T) {
+		m := mesa.FunctionMesa[int, func(int) int]{
+			Target: func(ctx *mesa.Ctx, base int) func(int) int {
+				return nil
+			},
+			Cases: []mesa.FunctionCase[int, func(int) int]{
+				mesa.ClosureCase("Nil", 0, mesa.ClosureCall[int, int]{Arg: 1, Expected: 1}),
+			},
+		}
+
+		m.Run(t)
+	}, "target returned a nil function")
+}
+
+type retryPolicy struct {
+	Mode     string        `mesa:"fixed, exponential"`
+	Attempts int           `mesa:"1,3"`
+	Delay    time.Duration `mesa:"10ms,1s"`
+	Jitter   bool
+	Ratio    float64 `mesa:"0.5"`
+	Limit    uint8   `mesa:"0x10"`
+}
+
+func TestCasesFromStructTags(t *testing.T) {
+	cases := mesa.CasesFromStructTags[retryPolicy, int](retryPolicy{Jitter: true}, nil)
+
+	require.Len(t, cases, 8)
+
+	assert.Equal(t, "Mode=fixed,Attempts=1,Delay=10ms,Ratio=0.5,Limit=0x10", cases[0].Name)
+	assert.Equal(t, retryPolicy{
+		Mode:     "fixed",
+		Attempts: 1,
+		Delay:    10 * time.Millisecond,
+		Jitter:   true,
+		Ratio:    0.5,
+		Limit:    16,
+	}, cases[0].Input)
+
+	assert.Equal(t, "Mode=fixed,Attempts=1,Delay=1s,Ratio=0.5,Limit=0x10", cases[1].Name)
+	assert.Equal(t, "Mode=exponential,Attempts=3,Delay=1s,Ratio=0.5,Limit=0x10", cases[7].Name)
+	assert.Equal(t, time.Second, cases[7].Input.Delay)
+
+	seen := map[string]bool{}
+	for _, c := range cases {
+		assert.True(t, c.Input.Jitter, "untagged fields keep the value of the template")
+		seen[c.Name] = true
+	}
+
+	assert.Len(t, seen, 8, "every combination must be generated once")
+}
+
+func TestCasesFromStructTags_Run(t *testing.T) {
+	var ran []string
+
+	m := mesa.FunctionMesa[retryPolicy, int]{
+		Target: func(ctx *mesa.Ctx, in retryPolicy) int {
+			return in.Attempts
+		},
+		Cases: mesa.CasesFromStructTags(retryPolicy{}, func(ctx *mesa.Ctx, in retryPolicy, out int) {
+			ran = append(ran, in.Mode)
+			ctx.As.Equal(in.Attempts, out)
+		}),
+	}
+
+	m.Run(t)
+
+	assert.Len(t, ran, 8)
+}
+
+func TestCasesFromStructTags_Invalid(t *testing.T) {
+	type badValue struct {
+		N int `mesa:"1,two"`
+	}
+
+	type unsupported struct {
+		Tags []string `mesa:"a,b"`
+	}
+
+	type unexported struct {
+		n int `mesa:"1"`
+	}
+
+	type untagged struct {
+		N int
+	}
+
+	assert.PanicsWithValue(t,
+		`mesa: invalid value "two" in the mesa tag of field N: strconv.ParseInt: parsing "two": invalid syntax`,
+		func() { mesa.CasesFromStructTags[badValue, int](badValue{}, nil) })
+	assert.PanicsWithValue(t, "mesa: field Tags has a mesa tag but its type []string is not supported",
+		func() { mesa.CasesFromStructTags[unsupported, int](unsupported{}, nil) })
+	assert.PanicsWithValue(t, "mesa: field n of mesa_test.unexported has a mesa tag but is unexported",
+		func() { mesa.CasesFromStructTags[unexported, int](unexported{n: 0}, nil) })
+	assert.PanicsWithValue(t, "mesa: mesa_test.untagged has no field with a mesa tag",
+		func() { mesa.CasesFromStructTags[untagged, int](untagged{}, nil) })
+	assert.PanicsWithValue(t, "mesa: CasesFromStructTags requires a struct input, got int",
+		func() { mesa.CasesFromStructTags[int, int](0, nil) })
+}
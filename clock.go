@@ -0,0 +1,39 @@
+package mesa
+
+import "time"
+
+// Now returns the current time of the case. It is time.Now() unless the clock of the case was frozen with SetNow or
+// the FrozenTime of the suite, in which case it returns the frozen time until the clock is moved with Advance. Using
+// it in the target and in Check gives a case a single source of time.
+func (c *Ctx) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.now == nil {
+		return time.Now()
+	}
+
+	return *c.now
+}
+
+// SetNow freezes the clock of the case at t, so that Now returns t until the clock is moved with Advance or set again.
+func (c *Ctx) SetNow(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = &t
+}
+
+// Advance moves the clock of the case forward by d. The clock is frozen at the current time first if it is not frozen
+// yet.
+func (c *Ctx) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.now == nil {
+		now := time.Now()
+		c.now = &now
+	}
+
+	*c.now = c.now.Add(d)
+}
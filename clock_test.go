@@ -0,0 +1,79 @@
+package mesa_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/a20r/mesa"
+)
+
+func TestCtx_Now(t *testing.T) {
+	m := mesa.FunctionMesa[mesa.Empty, mesa.Empty]{
+		Cases: []mesa.FunctionCase[mesa.Empty, mesa.Empty]{
+			{
+				Name: "Real clock",
+				Check: func(ctx *mesa.Ctx, _ mesa.Empty, _ mesa.Empty) {
+					before := time.Now()
+					now := ctx.Now()
+
+					ctx.As.False(now.Before(before))
+					ctx.As.WithinDuration(time.Now(), now, time.Second)
+				},
+			},
+			{
+				Name: "Frozen clock",
+				Check: func(ctx *mesa.Ctx, _ mesa.Empty, _ mesa.Empty) {
+					frozen := time.Date(2024, 2, 29, 12, 0, 0, 0, time.UTC)
+					ctx.SetNow(frozen)
+					time.Sleep(time.Millisecond)
+
+					ctx.As.Equal(frozen, ctx.Now())
+
+					ctx.Advance(time.Hour)
+					ctx.As.Equal(frozen.Add(time.Hour), ctx.Now())
+				},
+			},
+			{
+				Name: "Advance freezes the real clock",
+				Check: func(ctx *mesa.Ctx, _ mesa.Empty, _ mesa.Empty) {
+					start := time.Now()
+					ctx.Advance(24 * time.Hour)
+
+					now := ctx.Now()
+					ctx.As.WithinDuration(start.Add(24*time.Hour), now, time.Second)
+					ctx.As.Equal(now, ctx.Now())
+				},
+			},
+		},
+	}
+
+	m.Run(t)
+}
+
+func TestFunctionMesa_FrozenTime(t *testing.T) {
+	frozen := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	var seen []time.Time
+
+	m := mesa.FunctionMesa[time.Duration, time.Time]{
+		FrozenTime: frozen,
+		Target: func(ctx *mesa.Ctx, d time.Duration) time.Time {
+			ctx.Advance(d)
+			return ctx.Now()
+		},
+		Check: func(ctx *mesa.Ctx, d time.Duration, out time.Time) {
+			seen = append(seen, out)
+		},
+		Cases: []mesa.FunctionCase[time.Duration, time.Time]{
+			{Name: "Minute", Input: time.Minute},
+			{Name: "Hour", Input: time.Hour},
+		},
+	}
+
+	m.Run(t)
+
+	assert.Equal(t, []time.Time{frozen.Add(time.Minute), frozen.Add(time.Hour)}, seen,
+		"every case must start from the frozen time")
+}
@@ -0,0 +1,17 @@
+package mesa
+
+// Append returns a copy of the suite whose cases are followed by the cases of other. Every other setting, including
+// the target, is kept from m. This lets case groups be defined separately and merged into one suite. Case names must
+// be unique across the merged suite, which is reported when it runs.
+func (m MethodMesa[Inst, F, I, O]) Append(other MethodMesa[Inst, F, I, O]) MethodMesa[Inst, F, I, O] {
+	m.Cases = append(append([]MethodCase[Inst, F, I, O](nil), m.Cases...), other.Cases...)
+	return m
+}
+
+// Append returns a copy of the suite whose cases are followed by the cases of other. Every other setting, including
+// the target, is kept from m. This lets case groups be defined separately and merged into one suite. Case names must
+// be unique across the merged suite, which is reported when it runs.
+func (m FunctionMesa[I, O]) Append(other FunctionMesa[I, O]) FunctionMesa[I, O] {
+	m.Cases = append(append([]FunctionCase[I, O](nil), m.Cases...), other.Cases...)
+	return m
+}
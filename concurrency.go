@@ -0,0 +1,33 @@
+package mesa
+
+import "sync"
+
+// callConcurrently calls fn from n goroutines released at the same time and returns the output of the first one. fn
+// is called on the current goroutine when n is at most one.
+func callConcurrently[O any](n int, fn func() O) O {
+	if n <= 1 {
+		return fn()
+	}
+
+	var (
+		outs  = make([]O, n)
+		start = make(chan struct{})
+		wg    sync.WaitGroup
+	)
+
+	wg.Add(n)
+
+	for i := range outs {
+		go func(i int) {
+			defer wg.Done()
+
+			<-start
+			outs[i] = fn()
+		}(i)
+	}
+
+	close(start)
+	wg.Wait()
+
+	return outs[0]
+}
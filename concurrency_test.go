@@ -0,0 +1,52 @@
+package mesa_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/a20r/mesa"
+)
+
+type syncCounter struct {
+	mu sync.Mutex
+	n  int
+}
+
+func (c *syncCounter) Inc() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.n++
+
+	return c.n
+}
+
+func TestConcurrency(t *testing.T) {
+	m := mesa.MethodMesa[*syncCounter, mesa.Empty, mesa.Empty, int]{
+		NewInstance: func(ctx *mesa.Ctx, _ mesa.Empty) *syncCounter {
+			return &syncCounter{}
+		},
+		Target: func(ctx *mesa.Ctx, inst *syncCounter, _ mesa.Empty) int {
+			return inst.Inc()
+		},
+		Cases: []mesa.MethodCase[*syncCounter, mesa.Empty, mesa.Empty, int]{
+			{
+				Name: "Single call",
+				Check: func(ctx *mesa.Ctx, inst *syncCounter, _ mesa.Empty, out int) {
+					ctx.As.Equal(1, out)
+					ctx.As.Equal(1, inst.n)
+				},
+			},
+			{
+				Name:        "Concurrent calls",
+				Concurrency: 50,
+				Check: func(ctx *mesa.Ctx, inst *syncCounter, _ mesa.Empty, out int) {
+					ctx.As.Positive(out)
+					ctx.As.Equal(50, inst.n)
+				},
+			},
+		},
+	}
+
+	m.Run(t)
+}
@@ -0,0 +1,123 @@
+package mesa
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// WithValue replaces the embedded context with a child context carrying the given value. This lets hooks such as
+// BeforeCall set up values that the target reads from ctx.Context.
+func (c *Ctx) WithValue(key, val any) {
+	c.Context = context.WithValue(c.Context, key, val)
+}
+
+// WithCancel replaces the embedded context with a child context that is canceled when the returned function is
+// called. The cancel function is also registered as a cleanup of the test or benchmark, so calling it is optional.
+func (c *Ctx) WithCancel() context.CancelFunc {
+	ctx, cancel := context.WithCancel(c.Context)
+	c.Context = ctx
+	c.cleanup(cancel)
+
+	return cancel
+}
+
+// WithDeadline replaces the embedded context with a child context that is canceled at the given time. The returned
+// cancel function is also registered as a cleanup of the test or benchmark, so calling it is optional.
+func (c *Ctx) WithDeadline(d time.Time) context.CancelFunc {
+	ctx, cancel := context.WithDeadline(c.Context, d)
+	c.Context = ctx
+	c.cleanup(cancel)
+
+	return cancel
+}
+
+// WithTimeout replaces the embedded context with a child context that is canceled after the given duration. The
+// returned cancel function is also registered as a cleanup of the test or benchmark, so calling it is optional.
+func (c *Ctx) WithTimeout(d time.Duration) context.CancelFunc {
+	return c.WithDeadline(time.Now().Add(d))
+}
+
+// DeadlineApproaching reports whether less than buffer is left before the deadline of the context. The deadline of
+// the test binary, set with -timeout, is plumbed into the context so cases can skip themselves to leave room for
+// cleanup. It returns false if the context has no deadline.
+func (c *Ctx) DeadlineApproaching(buffer time.Duration) bool {
+	d, ok := c.Deadline()
+	return ok && time.Until(d) < buffer
+}
+
+// cleanup registers fn to be called when the test or benchmark of the context finishes, or when the current
+// iteration finishes while the timed loop of BenchmarkSetup runs.
+func (c *Ctx) cleanup(fn func()) {
+	if c.loop != nil {
+		c.loop.add(fn)
+		return
+	}
+
+	if c.cleanupOn != nil {
+		c.cleanupOn.cleanup(fn)
+		return
+	}
+
+	if t, ok := c.t.(interface{ Cleanup(func()) }); ok {
+		t.Cleanup(fn)
+	}
+}
+
+// sharedCtx returns a context for building a value that the cases of the suite share, such as the instance of a
+// group of GroupByFields or the fields of a CacheKey. Its failures are reported on the case that builds the value,
+// like those of c, while its embedded context, provided values and cleanups are those of the suite so that they
+// outlive the case.
+func (c *Ctx) sharedCtx(suite *Ctx) *Ctx {
+	return &Ctx{
+		Context:   suite.Context,
+		t:         c.t,
+		values:    make(map[string]any),
+		metrics:   metrics{byName: make(map[string]*metric)},
+		opts:      c.opts,
+		As:        c.As,
+		Re:        c.Re,
+		shared:    suite.shared,
+		suite:     suite,
+		xfail:     c.xfail,
+		caseName:  c.caseName,
+		caseID:    c.caseID,
+		failures:  c.failures,
+		cleanupOn: suite,
+	}
+}
+
+// cleanups collects the cleanups registered by the instances created in the timed loop of BenchmarkSetup, so that
+// they run after each instance instead of piling up until the benchmark finishes.
+type cleanups struct {
+	mu  sync.Mutex
+	fns []func()
+}
+
+// add collects fn.
+func (c *cleanups) add(fn func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.fns = append(c.fns, fn)
+}
+
+// pending reports whether there are collected cleanups to run.
+func (c *cleanups) pending() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return len(c.fns) > 0
+}
+
+// run calls the collected cleanups in reverse order, like t.Cleanup, and forgets them.
+func (c *cleanups) run() {
+	c.mu.Lock()
+	fns := c.fns
+	c.fns = nil
+	c.mu.Unlock()
+
+	for i := len(fns) - 1; i >= 0; i-- {
+		fns[i]()
+	}
+}
@@ -0,0 +1,246 @@
+package mesa_test
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/a20r/mesa"
+	"github.com/stretchr/testify/assert"
+)
+
+type contextKey struct{}
+
+func TestCtx_WithValue(t *testing.T) {
+	m := mesa.FunctionMesa[string, any]{
+		BeforeCall: func(ctx *mesa.Ctx, in string) {
+			ctx.WithValue(contextKey{}, in)
+		},
+		Target: func(ctx *mesa.Ctx, _ string) any {
+			return ctx.Value(contextKey{})
+		},
+		Cases: []mesa.FunctionCase[string, any]{
+			{
+				Name:     "Value is visible to the target",
+				Input:    "request-id",
+				Expected: "request-id",
+			},
+		},
+	}
+
+	m.Run(t)
+}
+
+func TestCtx_WithTimeout(t *testing.T) {
+	m := mesa.FunctionMesa[time.Duration, error]{
+		BeforeCall: func(ctx *mesa.Ctx, d time.Duration) {
+			ctx.WithTimeout(d)
+		},
+		Target: func(ctx *mesa.Ctx, _ time.Duration) error {
+			<-ctx.Done()
+			return ctx.Err()
+		},
+		Cases: []mesa.FunctionCase[time.Duration, error]{
+			{
+				Name:     "Deadline exceeded",
+				Input:    10 * time.Millisecond,
+				Expected: context.DeadlineExceeded,
+			},
+		},
+	}
+
+	m.Run(t)
+}
+
+func TestCtx_WithDeadline(t *testing.T) {
+	deadline := time.Now().Add(time.Second)
+
+	m := mesa.FunctionMesa[time.Time, time.Time]{
+		BeforeCall: func(ctx *mesa.Ctx, d time.Time) {
+			ctx.Re.NotNil(ctx.WithDeadline(d))
+		},
+		Target: func(ctx *mesa.Ctx, _ time.Time) time.Time {
+			d, _ := ctx.Deadline()
+			return d
+		},
+		Cases: []mesa.FunctionCase[time.Time, time.Time]{
+			{
+				Name:     "Deadline is visible to the target",
+				Input:    deadline,
+				Expected: deadline,
+			},
+		},
+	}
+
+	m.Run(t)
+}
+
+func TestCtx_DeadlineApproaching(t *testing.T) {
+	d, ok := t.Deadline()
+	if !ok {
+		t.Skip("requires a test deadline set with -timeout")
+	}
+
+	m := mesa.FunctionMesa[time.Duration, bool]{
+		Target: func(ctx *mesa.Ctx, buffer time.Duration) bool {
+			deadline, ok := ctx.Deadline()
+			ctx.Re.True(ok)
+			ctx.As.Equal(d, deadline)
+
+			return ctx.DeadlineApproaching(buffer)
+		},
+		Cases: []mesa.FunctionCase[time.Duration, bool]{
+			{
+				Name:     "Buffer beyond the deadline",
+				Input:    1000 * time.Hour,
+				Expected: true,
+			},
+			{
+				Name:  "Buffer within the deadline",
+				Input: time.Nanosecond,
+			},
+		},
+	}
+
+	m.Run(t)
+}
+
+func TestSkipNearDeadline(t *testing.T) {
+	if _, ok := t.Deadline(); !ok {
+		t.Skip("requires a test deadline set with -timeout")
+	}
+
+	var called bool
+
+	m := mesa.FunctionMesa[string, mesa.Empty]{
+		SkipNearDeadline: 1000 * time.Hour,
+		BeforeCall: func(ctx *mesa.Ctx, in string) {
+			called = true
+		},
+		Cases: []mesa.FunctionCase[string, mesa.Empty]{
+			{Name: "Skipped", Input: "skipped"},
+		},
+	}
+
+	m.Run(t)
+
+	assert.False(t, called)
+}
+
+func TestCtx_WithCancel(t *testing.T) {
+	m := mesa.FunctionMesa[bool, error]{
+		BeforeCall: func(ctx *mesa.Ctx, cancel bool) {
+			c := ctx.WithCancel()
+			if cancel {
+				c()
+			}
+		},
+		Target: func(ctx *mesa.Ctx, _ bool) error {
+			return ctx.Err()
+		},
+		Cases: []mesa.FunctionCase[bool, error]{
+			{Name: "Canceled", Input: true, Expected: context.Canceled},
+			{Name: "Not canceled", Input: false},
+		},
+	}
+
+	m.Run(t)
+}
+
+func TestCtx_CanceledAfterCase(t *testing.T) {
+	before := runtime.NumGoroutine()
+
+	var (
+		mu       sync.Mutex
+		contexts []context.Context
+	)
+
+	m := mesa.FunctionMesa[int, mesa.Empty]{
+		Target: func(ctx *mesa.Ctx, n int) mesa.Empty {
+			mu.Lock()
+			contexts = append(contexts, ctx)
+			mu.Unlock()
+
+			// Workers tied to the context of the case would leak if it was never canceled.
+			for i := 0; i < n; i++ {
+				go func() {
+					<-ctx.Done()
+				}()
+			}
+
+			return nil
+		},
+		Cleanup: func(ctx *mesa.Ctx) {
+			ctx.As.NoError(ctx.Err(), "context is canceled after Cleanup")
+		},
+		Cases: []mesa.FunctionCase[int, mesa.Empty]{
+			{Name: "One worker", Input: 1},
+			{Name: "Many workers", Input: 10},
+		},
+	}
+
+	m.Run(t)
+
+	for _, ctx := range contexts {
+		assert.ErrorIs(t, ctx.Err(), context.Canceled)
+	}
+
+	// Poll without assert.Eventually, which runs the condition on a goroutine of its own.
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	assert.LessOrEqual(t, runtime.NumGoroutine(), before, "goroutines leaked by the cases")
+}
+
+func TestFunctionMesa_DefaultTimeout(t *testing.T) {
+	// remaining rounds the time left before d to the minute so the time spent by the case doesn't matter.
+	remaining := func(d time.Time) time.Duration {
+		return time.Until(d).Round(time.Minute)
+	}
+
+	m := mesa.FunctionMesa[time.Duration, time.Duration]{
+		DefaultTimeout: time.Hour,
+		Target: func(ctx *mesa.Ctx, _ time.Duration) time.Duration {
+			d, ok := ctx.Deadline()
+			if !ok {
+				return 0
+			}
+
+			return remaining(d)
+		},
+		Check: func(ctx *mesa.Ctx, timeout time.Duration, got time.Duration) {
+			// The deadline of the test binary, set with -timeout, applies when it is earlier.
+			want := timeout
+			if d, ok := ctx.T().Deadline(); ok && (want == 0 || remaining(d) < want) {
+				want = remaining(d)
+			}
+
+			ctx.As.Equal(want, got)
+		},
+		Cases: []mesa.FunctionCase[time.Duration, time.Duration]{
+			{Name: "Suite default", Input: time.Hour},
+			{Name: "Case timeout", Input: time.Minute, Timeout: time.Minute},
+			{Name: "No timeout", Input: 0, Timeout: -1},
+		},
+	}
+
+	m.Run(t)
+}
+
+func TestFunctionCase_Timeout(t *testing.T) {
+	m := mesa.FunctionMesa[time.Duration, error]{
+		Target: func(ctx *mesa.Ctx, _ time.Duration) error {
+			<-ctx.Done()
+			return ctx.Err()
+		},
+		Cases: []mesa.FunctionCase[time.Duration, error]{
+			{Name: "Bounded", Timeout: 10 * time.Millisecond, Expected: context.DeadlineExceeded},
+		},
+	}
+
+	m.Run(t)
+}
@@ -0,0 +1,180 @@
+package mesa
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// DiffMode controls how the expected output of a case is compared to the actual output.
+type DiffMode int
+
+const (
+	// DiffTestify compares outputs with testify's Equal assertion. This is the default.
+	DiffTestify DiffMode = iota
+
+	// DiffCmp compares outputs with go-cmp and reports failures with cmp.Diff, which is easier to read for large
+	// nested structs. Types with unexported fields need an option such as cmpopts.IgnoreUnexported or
+	// cmp.AllowUnexported in the suite's CmpOptions.
+	DiffCmp
+
+	// DiffFields compares outputs field by field, recursing into structs, pointers, slices, arrays and maps, and
+	// makes one assertion per differing field so that every difference of a case is reported at once. Slices and
+	// arrays are compared by index and maps by key. Structs with unexported fields, such as time.Time, are compared
+	// as a whole.
+	DiffFields
+)
+
+// equal asserts that expected and actual are equal using the diff mode of the context.
+func (c *Ctx) equal(expected, actual any, msgAndArgs ...any) bool {
+	switch c.opts.diffMode {
+	case DiffCmp:
+	case DiffFields:
+		return c.equalFields("", reflect.ValueOf(expected), reflect.ValueOf(actual), msgAndArgs)
+	default:
+		return c.As.Equal(expected, actual, msgAndArgs...)
+	}
+
+	diff, err := cmpDiff(expected, actual, c.opts.cmpOptions)
+	if err != nil {
+		return c.As.Fail(err.Error(), msgAndArgs...)
+	}
+
+	if diff != "" {
+		return c.As.Fail(fmt.Sprintf("Not equal (-expected +actual):\n%s", diff), msgAndArgs...)
+	}
+
+	return true
+}
+
+// equalFields asserts that expected and actual are equal with one assertion per differing field, whose path from the
+// output, e.g. Address.Lines[0], is added to the failure message.
+func (c *Ctx) equalFields(path string, expected, actual reflect.Value, msgAndArgs []any) bool {
+	if !expected.IsValid() || !actual.IsValid() || expected.Type() != actual.Type() {
+		return c.equalField(path, valueOf(expected), valueOf(actual), msgAndArgs)
+	}
+
+	switch expected.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if expected.IsNil() || actual.IsNil() {
+			return c.equalField(path, expected.Interface(), actual.Interface(), msgAndArgs)
+		}
+
+		return c.equalFields(path, expected.Elem(), actual.Elem(), msgAndArgs)
+	case reflect.Struct:
+		t := expected.Type()
+		for i := 0; i < t.NumField(); i++ {
+			if !t.Field(i).IsExported() {
+				return c.equalField(path, expected.Interface(), actual.Interface(), msgAndArgs)
+			}
+		}
+
+		ok := true
+		for i := 0; i < t.NumField(); i++ {
+			ok = c.equalFields(joinPath(path, t.Field(i).Name), expected.Field(i), actual.Field(i), msgAndArgs) && ok
+		}
+
+		return ok
+	case reflect.Slice, reflect.Array:
+		if expected.Kind() == reflect.Slice && expected.IsNil() != actual.IsNil() {
+			return c.equalField(path, expected.Interface(), actual.Interface(), msgAndArgs)
+		}
+
+		ok := c.equalField(joinPath(path, "len()"), expected.Len(), actual.Len(), msgAndArgs)
+		for i := 0; i < expected.Len() && i < actual.Len(); i++ {
+			ok = c.equalFields(fmt.Sprintf("%s[%d]", path, i), expected.Index(i), actual.Index(i), msgAndArgs) && ok
+		}
+
+		return ok
+	case reflect.Map:
+		if expected.IsNil() != actual.IsNil() {
+			return c.equalField(path, expected.Interface(), actual.Interface(), msgAndArgs)
+		}
+
+		keys := append(expected.MapKeys(), actual.MapKeys()...)
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprint(keys[i]) < fmt.Sprint(keys[j])
+		})
+
+		ok := true
+		seen := make(map[any]bool, len(keys))
+
+		for _, k := range keys {
+			if seen[k.Interface()] {
+				continue
+			}
+
+			seen[k.Interface()] = true
+			ok = c.equalFields(fmt.Sprintf("%s[%v]", path, k), expected.MapIndex(k), actual.MapIndex(k), msgAndArgs) && ok
+		}
+
+		return ok
+	default:
+		return c.equalField(path, expected.Interface(), actual.Interface(), msgAndArgs)
+	}
+}
+
+// equalField asserts that the values of the field at path are equal.
+func (c *Ctx) equalField(path string, expected, actual any, msgAndArgs []any) bool {
+	if path == "" {
+		return c.As.Equal(expected, actual, msgAndArgs...)
+	}
+
+	msg := "field " + path + " differs"
+	if len(msgAndArgs) > 0 {
+		msg = messageFromArgs(msgAndArgs) + ": " + msg
+	}
+
+	return c.As.Equal(expected, actual, msg)
+}
+
+// valueOf returns the value held by v, or nil if v is the zero Value, e.g. for a key missing from a map.
+func valueOf(v reflect.Value) any {
+	if !v.IsValid() {
+		return nil
+	}
+
+	return v.Interface()
+}
+
+// joinPath returns the path of the field called name of the value at path.
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+
+	return path + "." + name
+}
+
+// messageFromArgs formats msgAndArgs the way testify does: a single value is printed as is and a format string is
+// applied to the values that follow it.
+func messageFromArgs(msgAndArgs []any) string {
+	if len(msgAndArgs) == 1 {
+		return fmt.Sprint(msgAndArgs[0])
+	}
+
+	if format, ok := msgAndArgs[0].(string); ok {
+		return fmt.Sprintf(format, msgAndArgs[1:]...)
+	}
+
+	return fmt.Sprint(msgAndArgs...)
+}
+
+// cmpDiff returns the go-cmp diff between expected and actual. go-cmp panics on types it cannot compare, such as
+// structs with unexported fields, so the panic is converted to an error.
+func cmpDiff(expected, actual any, opts []cmp.Option) (diff string, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("cannot compare outputs with go-cmp, provide CmpOptions to handle the type: %v", r)
+		}
+	}()
+
+	return cmp.Diff(expected, actual, opts...), nil
+}
+
+// isZero reports whether v is the zero value of its type.
+func isZero[T any](v T) bool {
+	return reflect.ValueOf(&v).Elem().IsZero()
+}
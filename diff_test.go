@@ -0,0 +1,190 @@
+package mesa_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/a20r/mesa"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/stretchr/testify/assert"
+)
+
+type nested struct {
+	Name  string
+	Tags  []string
+	inner int
+}
+
+func TestExpected(t *testing.T) {
+	m := mesa.FunctionMesa[string, nested]{
+		DiffMode:   mesa.DiffCmp,
+		CmpOptions: []cmp.Option{cmpopts.IgnoreUnexported(nested{})},
+		Target: func(ctx *mesa.Ctx, in string) nested {
+			return nested{Name: in, Tags: strings.Split(in, "-"), inner: len(in)}
+		},
+		Cases: []mesa.FunctionCase[string, nested]{
+			{
+				Name:     "Split",
+				Input:    "a-b",
+				Expected: nested{Name: "a-b", Tags: []string{"a", "b"}},
+			},
+		},
+	}
+
+	m.Run(t)
+}
+
+func TestExpected_Mismatch(t *testing.T) {
+	expectFailure(t, func(t *testing.T) {
+		m := mesa.FunctionMesa[int, int]{
+			Target: func(ctx *mesa.Ctx, in int) int {
+				return in + 1
+			},
+			Cases: []mesa.FunctionCase[int, int]{
+				{
+					Name:     "Off by one",
+					Input:    1,
+					Expected: 3,
+				},
+			},
+		}
+
+		m.Run(t)
+	}, "--- FAIL: TestExpected_Mismatch/Off_by_one")
+}
+
+func TestExpected_Zero(t *testing.T) {
+	expectFailure(t, func(t *testing.T) {
+		m := mesa.FunctionMesa[int, int]{
+			Target: func(ctx *mesa.Ctx, in int) int {
+				return in + 1
+			},
+			Cases: []mesa.FunctionCase[int, int]{
+				{Name: "Zero expected is not compared", Input: 1, Expected: 0},
+				{
+					Name:  "Zero ExpectedFn is compared",
+					Input: 1,
+					ExpectedFn: func(ctx *mesa.Ctx, in int) int {
+						return 0
+					},
+				},
+			},
+		}
+
+		m.Run(t)
+	}, "--- PASS: TestExpected_Zero/Zero_expected_is_not_compared",
+		"--- FAIL: TestExpected_Zero/Zero_ExpectedFn_is_compared")
+}
+
+func TestDiffCmp(t *testing.T) {
+	r := record(func(ctx *mesa.Ctx) {
+		mesa.Equal(ctx, mesa.DiffCmp, nil, mesa.ErrorPair[string]{Value: "a"}, mesa.ErrorPair[string]{Value: "b"})
+	})
+
+	assert.True(t, r.failed)
+	assert.Contains(t, strings.Join(r.errors, "\n"), "(-expected +actual)")
+}
+
+func TestDiffCmp_Unexported(t *testing.T) {
+	r := record(func(ctx *mesa.Ctx) {
+		mesa.Equal(ctx, mesa.DiffCmp, nil, nested{inner: 1}, nested{inner: 1})
+	})
+
+	assert.True(t, r.failed)
+	assert.Contains(t, strings.Join(r.errors, "\n"), "provide CmpOptions")
+
+	r = record(func(ctx *mesa.Ctx) {
+		mesa.Equal(ctx, mesa.DiffCmp, []cmp.Option{cmpopts.IgnoreUnexported(nested{})}, nested{inner: 1}, nested{})
+	})
+
+	assert.False(t, r.failed)
+}
+
+type postalAddress struct {
+	City  string
+	Lines []string
+}
+
+type profile struct {
+	Name    string
+	Age     int
+	Address *postalAddress
+	Scores  map[string]int
+	Joined  time.Time
+}
+
+func TestDiffFields(t *testing.T) {
+	joined := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	want := profile{
+		Name:    "Ada",
+		Age:     36,
+		Address: &postalAddress{City: "London", Lines: []string{"1 Main St", "Flat 2"}},
+		Scores:  map[string]int{"go": 9, "rust": 7},
+		Joined:  joined,
+	}
+
+	r := record(func(ctx *mesa.Ctx) {
+		assert.True(t, mesa.Equal(ctx, mesa.DiffFields, nil, want, want))
+	})
+
+	assert.False(t, r.failed)
+
+	got := profile{
+		Name:    "Ada",
+		Age:     37,
+		Address: &postalAddress{City: "Paris", Lines: []string{"1 Main St", "Flat 3", "Extra"}},
+		Scores:  map[string]int{"go": 9, "zig": 5},
+		Joined:  joined.Add(time.Hour),
+	}
+
+	r = record(func(ctx *mesa.Ctx) {
+		assert.False(t, mesa.Equal(ctx, mesa.DiffFields, nil, want, got))
+	})
+
+	assert.True(t, r.failed)
+
+	var fields []string
+	for _, e := range r.errors {
+		i := strings.Index(e, "field ")
+		if assert.GreaterOrEqual(t, i, 0, e) {
+			fields = append(fields, strings.Fields(e[i:])[1])
+		}
+	}
+
+	assert.Equal(t, []string{
+		"Age",
+		"Address.City",
+		"Address.Lines.len()",
+		"Address.Lines[1]",
+		"Scores[rust]",
+		"Scores[zig]",
+		"Joined",
+	}, fields)
+}
+
+func TestDiffFields_Case(t *testing.T) {
+	expectFailure(t, func(t *testing.T) {
+		m := mesa.FunctionMesa[string, postalAddress]{
+			DiffMode: mesa.DiffFields,
+			Target: func(ctx *mesa.Ctx, in string) postalAddress {
+				return postalAddress{City: strings.ToUpper(in), Lines: []string{in}}
+			},
+			Cases: []mesa.FunctionCase[string, postalAddress]{
+				{Name: "Two fields", Input: "rome", Expected: postalAddress{City: "Rome", Lines: []string{"Rome"}}},
+			},
+		}
+
+		m.Run(t)
+	}, "field City differs", "field Lines[0] differs")
+}
+
+func TestDiffFields_Unexported(t *testing.T) {
+	r := record(func(ctx *mesa.Ctx) {
+		mesa.Equal(ctx, mesa.DiffFields, nil, nested{Name: "a", inner: 1}, nested{Name: "b", inner: 2})
+	})
+
+	assert.True(t, r.failed)
+	assert.Len(t, r.errors, 1, "structs with unexported fields are compared as a whole")
+}
@@ -0,0 +1,71 @@
+package mesa
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// DocFormatter customizes how values of one type are rendered by DocExamples. Create one with FormatDoc.
+type DocFormatter struct {
+	typ    reflect.Type
+	format func(v any) string
+}
+
+// FormatDoc returns a DocFormatter that renders values of type T with fn.
+func FormatDoc[T any](fn func(v T) string) DocFormatter {
+	return DocFormatter{
+		typ: reflect.TypeOf((*T)(nil)).Elem(),
+		format: func(v any) string {
+			return fn(v.(T))
+		},
+	}
+}
+
+// DocExamples renders each case as a human readable "given input X, expect Y" line, e.g. to generate documentation
+// from a table. The expectation is omitted for cases without an Expected output. The suite is not run.
+func (m MethodMesa[Inst, F, I, O]) DocExamples() []string {
+	examples := make([]string, len(m.Cases))
+	for i, tt := range m.Cases {
+		examples[i] = docExample(m.DocFormatters, tt.Name, tt.Input, tt.Expected)
+	}
+
+	return examples
+}
+
+// DocExamples renders each case as a human readable "given input X, expect Y" line, e.g. to generate documentation
+// from a table. The expectation is omitted for cases without an Expected output. The suite is not run.
+func (m FunctionMesa[I, O]) DocExamples() []string {
+	examples := make([]string, len(m.Cases))
+	for i, tt := range m.Cases {
+		examples[i] = docExample(m.DocFormatters, tt.Name, tt.Input, tt.Expected)
+	}
+
+	return examples
+}
+
+// docExample renders a single case.
+func docExample[I, O any](formatters []DocFormatter, name string, in I, expected O) string {
+	example := fmt.Sprintf("%s: given input %s", name, formatDoc(formatters, in))
+	if !isZero(expected) {
+		example += ", expect " + formatDoc(formatters, expected)
+	}
+
+	return example
+}
+
+// formatDoc renders v with the formatter of its type, falling back to quoting strings and printing other values
+// with their field names.
+func formatDoc(formatters []DocFormatter, v any) string {
+	typ := reflect.TypeOf(v)
+	for _, f := range formatters {
+		if f.typ == typ {
+			return f.format(v)
+		}
+	}
+
+	if typ != nil && typ.Kind() == reflect.String {
+		return fmt.Sprintf("%q", v)
+	}
+
+	return fmt.Sprintf("%+v", v)
+}
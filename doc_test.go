@@ -0,0 +1,39 @@
+package mesa_test
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/a20r/mesa"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFunctionMesa_DocExamples(t *testing.T) {
+	m := mesa.FunctionMesa[string, int]{
+		Cases: []mesa.FunctionCase[string, int]{
+			{Name: "Digits", Input: "42", Expected: 42},
+			{Name: "Invalid", Input: "abc"},
+		},
+	}
+
+	assert.Equal(t, []string{
+		`Digits: given input "42", expect 42`,
+		`Invalid: given input "abc"`,
+	}, m.DocExamples())
+}
+
+func TestMethodMesa_DocExamples(t *testing.T) {
+	m := mesa.MethodMesa[*MyStruct, mesa.Empty, time.Duration, int]{
+		DocFormatters: []mesa.DocFormatter{
+			mesa.FormatDoc(func(d time.Duration) string {
+				return strconv.Quote(d.String())
+			}),
+		},
+		Cases: []mesa.MethodCase[*MyStruct, mesa.Empty, time.Duration, int]{
+			{Name: "Minute", Input: time.Minute, Expected: 60},
+		},
+	}
+
+	assert.Equal(t, []string{`Minute: given input "1m0s", expect 60`}, m.DocExamples())
+}
@@ -0,0 +1,94 @@
+package mesa
+
+import "regexp"
+
+// errorPair is implemented by every ErrorPair so the error and value of an output can be read without knowing its
+// value type.
+type errorPair interface {
+	pairErr() error
+	pairValue() any
+}
+
+func (p ErrorPair[T]) pairErr() error {
+	return p.Err
+}
+
+func (p ErrorPair[T]) pairValue() any {
+	return p.Value
+}
+
+// errExpectation holds the ExpectErrMsg and the compiled ExpectErrRegex of a case.
+type errExpectation struct {
+	msg string
+	re  *regexp.Regexp
+}
+
+// newErrExpectation compiles the regular expression of the expectation, if any.
+func newErrExpectation(msg, pattern string) (errExpectation, error) {
+	e := errExpectation{msg: msg}
+	if pattern == "" {
+		return e, nil
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return e, err
+	}
+
+	e.re = re
+
+	return e, nil
+}
+
+// check asserts that out is an ErrorPair whose error matches the expectation. It does nothing if the case has no
+// expectation on the error message.
+func (e errExpectation) check(ctx *Ctx, out any) {
+	if e.msg == "" && e.re == nil {
+		return
+	}
+
+	pair, ok := out.(errorPair)
+	if !ok {
+		ctx.Re.Failf("invalid output type", "ExpectErrMsg and ExpectErrRegex require an ErrorPair output, got %T", out)
+		return
+	}
+
+	err := pair.pairErr()
+
+	ctx.Re.Error(err, "expected an error")
+
+	// ctx.Re does not stop the case in SoftOnly mode.
+	if err == nil {
+		return
+	}
+
+	if e.msg != "" {
+		ctx.As.Equal(e.msg, err.Error(), "unexpected error message")
+	}
+
+	if e.re != nil {
+		ctx.As.Regexp(e.re, err.Error(), "unexpected error message")
+	}
+}
+
+// checkSuccess asserts that out is an ErrorPair without an error when noErr is set, and that its value is equal to
+// value when it is not nil. It does nothing if neither is set.
+func checkSuccess(ctx *Ctx, out any, noErr bool, value any) {
+	if !noErr && value == nil {
+		return
+	}
+
+	pair, ok := out.(errorPair)
+	if !ok {
+		ctx.Re.Failf("invalid output type", "ExpectNoErr and ExpectedValue require an ErrorPair output, got %T", out)
+		return
+	}
+
+	if noErr {
+		ctx.Re.NoError(pair.pairErr(), "%s: unexpected error", ctx.name())
+	}
+
+	if value != nil {
+		ctx.equal(value, pair.pairValue())
+	}
+}
@@ -0,0 +1,246 @@
+package mesa_test
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/a20r/mesa"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func parse(ctx *mesa.Ctx, in string) mesa.ErrorPair[int] {
+	if in == "" {
+		return mesa.NewErrorPair(0, nil)
+	}
+
+	return mesa.NewErrorPair(0, errors.New("invalid input: "+in))
+}
+
+func TestExpectErrMsg(t *testing.T) {
+	m := mesa.FunctionMesa[string, mesa.ErrorPair[int]]{
+		Target: parse,
+		Cases: []mesa.FunctionCase[string, mesa.ErrorPair[int]]{
+			{
+				Name:         "Exact message",
+				Input:        "abc",
+				ExpectErrMsg: "invalid input: abc",
+			},
+			{
+				Name:           "Matching message",
+				Input:          "xyz",
+				ExpectErrRegex: `^invalid input: [a-z]+$`,
+			},
+		},
+	}
+
+	m.Run(t)
+}
+
+func TestExpectErrMsg_Mismatch(t *testing.T) {
+	expectFailure(t, func(t *testing.T) {
+		m := mesa.FunctionMesa[string, mesa.ErrorPair[int]]{
+			Target: parse,
+			Cases: []mesa.FunctionCase[string, mesa.ErrorPair[int]]{
+				{
+					Name:         "Different message",
+					Input:        "abc",
+					ExpectErrMsg: "invalid input: xyz",
+				},
+			},
+		}
+
+		m.Run(t)
+	}, "unexpected error message")
+}
+
+func TestExpectErrMsg_NilError(t *testing.T) {
+	expectFailure(t, func(t *testing.T) {
+		m := mesa.FunctionMesa[string, mesa.ErrorPair[int]]{
+			Target: parse,
+			Cases: []mesa.FunctionCase[string, mesa.ErrorPair[int]]{
+				{
+					Name:         "No error",
+					Input:        "",
+					ExpectErrMsg: "invalid input: ",
+				},
+			},
+		}
+
+		m.Run(t)
+	}, "expected an error")
+}
+
+func TestExpectErrMsg_NilError_SoftOnly(t *testing.T) {
+	out, ok, err := runInSubprocess(t, func(t *testing.T) {
+		m := mesa.FunctionMesa[string, mesa.ErrorPair[int]]{
+			AssertionMode: mesa.SoftOnly,
+			Target:        parse,
+			Cases: []mesa.FunctionCase[string, mesa.ErrorPair[int]]{
+				{
+					Name:         "No error",
+					Input:        "",
+					ExpectErrMsg: "invalid input: ",
+				},
+			},
+		}
+
+		m.Run(t)
+	})
+	if !ok {
+		return
+	}
+
+	require.Error(t, err, out)
+	assert.Contains(t, out, "expected an error")
+	assert.NotContains(t, out, "panic")
+}
+
+func TestExpectErrMsg_NotErrorPair(t *testing.T) {
+	expectFailure(t, func(t *testing.T) {
+		m := mesa.FunctionMesa[string, string]{
+			Target: func(ctx *mesa.Ctx, in string) string {
+				return in
+			},
+			Cases: []mesa.FunctionCase[string, string]{
+				{
+					Name:         "Plain output",
+					Input:        "abc",
+					ExpectErrMsg: "abc",
+				},
+			},
+		}
+
+		m.Run(t)
+	}, "require an ErrorPair output, got string")
+}
+
+func TestExpectErrRegex_Invalid(t *testing.T) {
+	expectFailure(t, func(t *testing.T) {
+		m := mesa.FunctionMesa[string, mesa.ErrorPair[int]]{
+			Target: parse,
+			Cases: []mesa.FunctionCase[string, mesa.ErrorPair[int]]{
+				{
+					Name:  "Valid",
+					Input: "abc",
+				},
+				{
+					Name:           "Invalid regex",
+					Input:          "abc",
+					ExpectErrRegex: `(`,
+				},
+			},
+		}
+
+		m.Run(t)
+	}, `invalid ExpectErrRegex of case "Invalid regex"`)
+}
+
+func TestExpectNoErr(t *testing.T) {
+	m := mesa.FunctionMesa[string, mesa.ErrorPair[int]]{
+		RequireAssertions: true,
+		Target: func(ctx *mesa.Ctx, in string) mesa.ErrorPair[int] {
+			return mesa.NewErrorPair(strconv.Atoi(in))
+		},
+		Check: func(ctx *mesa.Ctx, in string, out mesa.ErrorPair[int]) {},
+		Cases: []mesa.FunctionCase[string, mesa.ErrorPair[int]]{
+			{Name: "Value", Input: "42", ExpectNoErr: true, ExpectedValue: 42},
+			{Name: "Zero value", Input: "0", ExpectNoErr: true, ExpectedValue: 0},
+			{Name: "Only no error", Input: "7", ExpectNoErr: true},
+		},
+	}
+
+	m.Run(t)
+}
+
+func TestExpectNoErr_Error(t *testing.T) {
+	expectFailure(t, func(t *testing.T) {
+		m := mesa.FunctionMesa[string, mesa.ErrorPair[int]]{
+			Target: func(ctx *mesa.Ctx, in string) mesa.ErrorPair[int] {
+				return mesa.NewErrorPair(strconv.Atoi(in))
+			},
+			Cases: []mesa.FunctionCase[string, mesa.ErrorPair[int]]{
+				{Name: "Invalid", Input: "abc", ExpectNoErr: true, ExpectedValue: 0},
+			},
+		}
+
+		m.Run(t)
+	}, "TestExpectNoErr_Error/Invalid: unexpected error", `parsing "abc": invalid syntax`)
+}
+
+func TestExpectedValue_Mismatch(t *testing.T) {
+	expectFailure(t, func(t *testing.T) {
+		m := mesa.FunctionMesa[string, mesa.ErrorPair[int]]{
+			Target: func(ctx *mesa.Ctx, in string) mesa.ErrorPair[int] {
+				return mesa.NewErrorPair(strconv.Atoi(in))
+			},
+			Cases: []mesa.FunctionCase[string, mesa.ErrorPair[int]]{
+				{Name: "Different value", Input: "41", ExpectNoErr: true, ExpectedValue: 42},
+			},
+		}
+
+		m.Run(t)
+	}, "--- FAIL: TestExpectedValue_Mismatch/Different_value")
+}
+
+func TestExpectedRegex(t *testing.T) {
+	m := mesa.FunctionMesa[string, string]{
+		RequireAssertions: true,
+		Target: func(ctx *mesa.Ctx, in string) string {
+			return "request " + in + " handled in 12ms\nstatus: ok\n"
+		},
+		Check: func(ctx *mesa.Ctx, in string, out string) {},
+		Cases: []mesa.FunctionCase[string, string]{
+			{Name: "Capture groups", Input: "a1", ExpectedRegex: `^request (\w+) handled in (\d+)ms`},
+			{Name: "Multiline", Input: "b2", ExpectedRegex: `(?m)^status: ok$`},
+		},
+	}
+
+	m.Run(t)
+}
+
+func TestExpectedRegex_Mismatch(t *testing.T) {
+	expectFailure(t, func(t *testing.T) {
+		m := mesa.FunctionMesa[string, string]{
+			Target: func(ctx *mesa.Ctx, in string) string {
+				return in
+			},
+			Cases: []mesa.FunctionCase[string, string]{
+				{Name: "Not a number", Input: "abc", ExpectedRegex: `^\d+$`},
+			},
+		}
+
+		m.Run(t)
+	}, "output doesn't match ExpectedRegex")
+}
+
+func TestExpectedRegex_NotString(t *testing.T) {
+	expectFailure(t, func(t *testing.T) {
+		m := mesa.FunctionMesa[int, int]{
+			Target: func(ctx *mesa.Ctx, in int) int {
+				return in
+			},
+			Cases: []mesa.FunctionCase[int, int]{
+				{Name: "Int output", Input: 1, ExpectedRegex: `1`},
+			},
+		}
+
+		m.Run(t)
+	}, "ExpectedRegex requires a string output, got int")
+}
+
+func TestExpectedRegex_Invalid(t *testing.T) {
+	expectFailure(t, func(t *testing.T) {
+		m := mesa.FunctionMesa[string, string]{
+			Target: func(ctx *mesa.Ctx, in string) string {
+				return in
+			},
+			Cases: []mesa.FunctionCase[string, string]{
+				{Name: "Invalid regex", Input: "abc", ExpectedRegex: `[a-`},
+			},
+		}
+
+		m.Run(t)
+	}, `invalid ExpectedRegex of case "Invalid regex"`)
+}
@@ -1,7 +1,24 @@
 package mesa_test
 
 import (
+	"bytes"
+	"container/list"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"regexp"
+	"runtime/pprof"
+	"sort"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/a20r/mesa"
 )
@@ -139,3 +156,1039 @@ func TestAdd(t *testing.T) {
 
 	m.Run(t)
 }
+
+func ExampleFunc() {
+	var t *testing.T
+
+	mesa.Func(func(ctx *mesa.Ctx, in []int) int {
+		sum := 0
+		for _, n := range in {
+			sum += n
+		}
+		return sum
+	}).
+		Case("Empty", nil, 0).
+		Case("Single", []int{1}, 1).
+		Case("Many", []int{1, 2, 3}, 6).
+		Run(t)
+}
+
+func Evens(n int) <-chan int {
+	ch := make(chan int)
+
+	go func() {
+		defer close(ch)
+		for i := 0; i < n; i++ {
+			ch <- i * 2
+		}
+	}()
+
+	return ch
+}
+
+func ExampleCollect() {
+	m := mesa.FunctionMesa[int, <-chan int]{
+		Target: func(ctx *mesa.Ctx, n int) <-chan int {
+			return Evens(n)
+		},
+		Cases: []mesa.FunctionCase[int, <-chan int]{
+			{
+				Name:         "First three",
+				Input:        3,
+				DrainTimeout: time.Second,
+				Check: func(ctx *mesa.Ctx, in int, out <-chan int) {
+					ctx.As.Equal([]int{0, 2, 4}, mesa.Drain(ctx, out))
+				},
+			},
+		},
+	}
+
+	var t *testing.T
+	m.Run(t)
+}
+
+func ExampleFunctionMesa_labels() {
+	m := mesa.FunctionMesa[int, int]{
+		Labels: map[string]string{"component": "math"},
+		Target: func(ctx *mesa.Ctx, in int) int {
+			return in * in
+		},
+		Cases: []mesa.FunctionCase[int, int]{
+			{
+				Name:     "Square 3",
+				Input:    3,
+				Expected: 9,
+				Labels:   map[string]string{"owner": "core"},
+			},
+		},
+	}
+
+	var t *testing.T
+	m.Run(t)
+}
+
+func ExampleFunctionCase_requireEnv() {
+	m := mesa.FunctionMesa[string, error]{
+		Target: func(ctx *mesa.Ctx, query string) error {
+			// Connect to os.Getenv("DATABASE_URL") and run the query.
+			return nil
+		},
+		Cases: []mesa.FunctionCase[string, error]{
+			{
+				Name:       "Select one",
+				Input:      "SELECT 1",
+				RequireEnv: []string{"DATABASE_URL"},
+				Check: func(ctx *mesa.Ctx, query string, err error) {
+					ctx.As.NoError(err)
+				},
+			},
+		},
+	}
+
+	var t *testing.T
+	m.Run(t)
+}
+
+type Point struct {
+	X, Y int
+}
+
+func (p Point) MarshalJSON() ([]byte, error) {
+	return []byte(fmt.Sprintf(`{"x":%d,"y":%d}`, p.X, p.Y)), nil
+}
+
+func ExampleSnapshotCase() {
+	// Run the tests with MESA_UPDATE=1 to write the snapshots to testdata/snapshots.
+	m := mesa.FunctionMesa[Point, Point]{
+		Target: func(ctx *mesa.Ctx, p Point) Point {
+			return Point{X: p.Y, Y: p.X}
+		},
+		Cases: []mesa.FunctionCase[Point, Point]{
+			mesa.SnapshotCase[Point, Point]("Swap", Point{X: 1, Y: 2}),
+			mesa.SnapshotCase[Point, Point]("Origin", Point{}),
+		},
+	}
+
+	var t *testing.T
+	m.Run(t)
+}
+
+func ExampleFunctionMesa_filter() {
+	// Only run the cases whose name starts with "Fast".
+	pattern := regexp.MustCompile(`^Fast`)
+
+	m := mesa.FunctionMesa[int, int]{
+		Filter: func(c mesa.CaseMeta) bool {
+			return pattern.MatchString(c.Name)
+		},
+		Target: func(ctx *mesa.Ctx, n int) int {
+			return n + 1
+		},
+		Cases: []mesa.FunctionCase[int, int]{
+			{Name: "Fast increment", Input: 1, Expected: 2},
+			{Name: "Slow increment", Input: 1 << 30, Expected: 1<<30 + 1},
+		},
+	}
+
+	var t *testing.T
+	m.Run(t)
+}
+
+func ExampleFunctionMesa_onFailure() {
+	m := mesa.FunctionMesa[int, int]{
+		// Dump the stacks of all goroutines when a case fails to debug hangs and leaks.
+		OnFailure: func(ctx *mesa.Ctx, name string) {
+			var buf bytes.Buffer
+			if err := pprof.Lookup("goroutine").WriteTo(&buf, 1); err == nil {
+				ctx.T().Logf("goroutines after %s failed:\n%s", name, buf.String())
+			}
+		},
+		Target: func(ctx *mesa.Ctx, n int) int {
+			return n * 2
+		},
+		Cases: []mesa.FunctionCase[int, int]{
+			{Name: "Double", Input: 2, Expected: 4},
+		},
+	}
+
+	var t *testing.T
+	m.Run(t)
+}
+
+func ExampleCasesFromRegressions() {
+	check := func(ctx *mesa.Ctx, in []int, out int) {
+		ctx.As.GreaterOrEqual(out, 0)
+	}
+
+	m := mesa.FunctionMesa[[]int, int]{
+		// Inputs of failing cases are written to testdata/regressions/<test name>.json.
+		RecordRegressions: true,
+		Target: func(ctx *mesa.Ctx, in []int) int {
+			sum := 0
+			for _, n := range in {
+				sum += n
+			}
+
+			return sum
+		},
+		Cases: append(
+			[]mesa.FunctionCase[[]int, int]{
+				{Name: "Positive", Input: []int{1, 2, 3}, Check: check},
+			},
+			// Inputs recorded by earlier failing runs are replayed as regular cases.
+			mesa.CasesFromRegressions("testdata/regressions/TestSum.json", check)...,
+		),
+	}
+
+	var t *testing.T
+	m.Run(t)
+}
+
+type Encoder interface {
+	Encode(v any) string
+}
+
+type JSONEncoder struct{}
+
+func (JSONEncoder) Encode(v any) string { return fmt.Sprintf("%q", v) }
+
+type TextEncoder struct{ Prefix string }
+
+func (e TextEncoder) Encode(v any) string { return e.Prefix + fmt.Sprint(v) }
+
+func ExampleExpectType() {
+	newEncoder := func(ctx *mesa.Ctx, format string) Encoder {
+		if format == "json" {
+			return JSONEncoder{}
+		}
+
+		return TextEncoder{Prefix: "> "}
+	}
+
+	m := mesa.FunctionMesa[string, Encoder]{
+		Target: newEncoder,
+		Cases: []mesa.FunctionCase[string, Encoder]{
+			{
+				Name:         "JSON",
+				Input:        "json",
+				ExpectedType: JSONEncoder{},
+			},
+			{
+				Name:  "Text",
+				Input: "text",
+				Check: func(ctx *mesa.Ctx, _ string, out Encoder) {
+					enc := mesa.ExpectType[TextEncoder](ctx, out)
+					ctx.As.Equal("> ", enc.Prefix)
+				},
+			},
+		},
+	}
+
+	var t *testing.T
+	m.Run(t)
+}
+
+func ExampleFunctionCase_expectedFn() {
+	// insertionSort sorts a copy of its input.
+	insertionSort := func(ctx *mesa.Ctx, in []int) []int {
+		out := append([]int(nil), in...)
+		for i := 1; i < len(out); i++ {
+			for j := i; j > 0 && out[j] < out[j-1]; j-- {
+				out[j], out[j-1] = out[j-1], out[j]
+			}
+		}
+
+		return out
+	}
+
+	// The standard library is the oracle that the expected output is computed with.
+	oracle := func(ctx *mesa.Ctx, in []int) []int {
+		out := append([]int(nil), in...)
+		sort.Slice(out, func(i, j int) bool { return out[i] < out[j] })
+
+		return out
+	}
+
+	m := mesa.FunctionMesa[[]int, []int]{
+		Target: insertionSort,
+		Cases: []mesa.FunctionCase[[]int, []int]{
+			{Name: "Reversed", Input: []int{3, 2, 1}, ExpectedFn: oracle},
+			{Name: "Duplicates", Input: []int{2, 1, 2, 1}, ExpectedFn: oracle},
+			{Name: "Sorted", Input: []int{1, 2, 3}, ExpectedFn: oracle},
+		},
+	}
+
+	var t *testing.T
+	m.Run(t)
+}
+
+func ExampleSizeCases() {
+	m := mesa.MethodBenchmarkMesa[hash.Hash, mesa.Empty, []byte, []byte]{
+		NewInstance: func(ctx *mesa.Ctx, _ mesa.Empty) hash.Hash {
+			return sha256.New()
+		},
+		Target: func(ctx *mesa.Ctx, h hash.Hash, in []byte) []byte {
+			h.Reset()
+			h.Write(in)
+
+			return h.Sum(nil)
+		},
+		// Reported as BenchmarkSHA256/size-64, BenchmarkSHA256/size-1024, ... with their throughput in MB/s.
+		Cases: mesa.SizeCases[hash.Hash, mesa.Empty, []byte, []byte]([]int{64, 1024, 16384}, func(n int) []byte {
+			return bytes.Repeat([]byte{'x'}, n)
+		}),
+	}
+
+	var b *testing.B
+	m.Run(b)
+}
+
+func ExampleCtx_ReadAllString() {
+	m := mesa.FunctionMesa[map[string]int, io.Reader]{
+		Target: func(ctx *mesa.Ctx, in map[string]int) io.Reader {
+			var buf bytes.Buffer
+			json.NewEncoder(&buf).Encode(in)
+
+			return &buf
+		},
+		Check: func(ctx *mesa.Ctx, in map[string]int, out io.Reader) {
+			ctx.EqualJSON(`{"apples": 3}`, ctx.ReadAllString(out))
+		},
+		Cases: []mesa.FunctionCase[map[string]int, io.Reader]{
+			{Name: "Encode", Input: map[string]int{"apples": 3}},
+		},
+	}
+
+	var t *testing.T
+	m.Run(t)
+}
+
+type Clock interface {
+	Now() time.Time
+}
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c fakeClock) Now() time.Time { return c.now }
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+type StatusChecker struct {
+	Clock  Clock
+	Client *http.Client
+}
+
+func (s StatusChecker) Check(url string) string {
+	resp, err := s.Client.Get(url)
+	if err != nil {
+		return "down"
+	}
+	defer resp.Body.Close()
+
+	return fmt.Sprintf("%d at %s", resp.StatusCode, s.Clock.Now().Format(time.Kitchen))
+}
+
+func ExampleResolve() {
+	m := mesa.MethodMesa[StatusChecker, mesa.Empty, string, string]{
+		// Test doubles provided on the suite are shared by every case.
+		Init: func(ctx *mesa.Ctx) {
+			ctx.Provide("clock", Clock(fakeClock{now: time.Date(2024, 1, 1, 9, 30, 0, 0, time.UTC)}))
+			ctx.Provide("client", &http.Client{
+				Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+					return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+				}),
+			})
+		},
+		NewInstance: func(ctx *mesa.Ctx, _ mesa.Empty) StatusChecker {
+			return StatusChecker{
+				Clock:  mesa.Resolve[Clock](ctx, "clock"),
+				Client: mesa.Resolve[*http.Client](ctx, "client"),
+			}
+		},
+		Target: func(ctx *mesa.Ctx, inst StatusChecker, url string) string {
+			return inst.Check(url)
+		},
+		Cases: []mesa.MethodCase[StatusChecker, mesa.Empty, string, string]{
+			{Name: "Up", Input: "https://example.com/health", Expected: "200 at 9:30AM"},
+		},
+	}
+
+	var t *testing.T
+	m.Run(t)
+}
+
+func ExampleFunctionMesa_sortCases() {
+	m := mesa.FunctionMesa[string, int]{
+		// Run the most important cases first.
+		SortCases: func(a, b mesa.CaseMeta) bool {
+			return a.Priority > b.Priority
+		},
+		Target: func(ctx *mesa.Ctx, in string) int {
+			return len(in)
+		},
+		Cases: []mesa.FunctionCase[string, int]{
+			{Name: "Nice to have", Input: "a", Expected: 1, Priority: 1},
+			{Name: "Critical", Input: "abc", Expected: 3, Priority: 100},
+			{Name: "Important", Input: "ab", Expected: 2, Priority: 10},
+		},
+	}
+
+	var t *testing.T
+	m.Run(t)
+}
+
+type UserClient struct {
+	BaseURL string
+}
+
+func (c UserClient) Name(id int) (string, error) {
+	resp, err := http.Get(fmt.Sprintf("%s/users/%d", c.BaseURL, id))
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var user struct{ Name string }
+	err = json.NewDecoder(resp.Body).Decode(&user)
+
+	return user.Name, err
+}
+
+func ExampleCtx_StartServer() {
+	m := mesa.MethodMesa[UserClient, string, int, mesa.ErrorPair[string]]{
+		NewInstance: func(ctx *mesa.Ctx, baseURL string) UserClient {
+			return UserClient{BaseURL: baseURL}
+		},
+		Target: func(ctx *mesa.Ctx, inst UserClient, id int) mesa.ErrorPair[string] {
+			return mesa.NewErrorPair(inst.Name(id))
+		},
+		Cases: []mesa.MethodCase[UserClient, string, int, mesa.ErrorPair[string]]{
+			{
+				Name: "Get user",
+				// The stub server is closed when the case finishes.
+				FieldsFn: func(ctx *mesa.Ctx) string {
+					return ctx.StartServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+						io.WriteString(w, `{"Name": "ada"}`)
+					})).URL
+				},
+				Input:    1,
+				Expected: mesa.NewErrorPair("ada", nil),
+			},
+		},
+	}
+
+	var t *testing.T
+	m.Run(t)
+}
+
+type Registry struct {
+	ports map[string]int
+}
+
+func (r *Registry) Port(service string) (int, bool) {
+	port, ok := r.ports[service]
+	return port, ok
+}
+
+func ExampleMustOk() {
+	m := mesa.MethodMesa[*Registry, map[string]int, string, mesa.OkPair[int]]{
+		NewInstance: func(ctx *mesa.Ctx, ports map[string]int) *Registry {
+			return &Registry{ports: ports}
+		},
+		Target: func(ctx *mesa.Ctx, inst *Registry, service string) mesa.OkPair[int] {
+			return mesa.NewOkPair(inst.Port(service))
+		},
+		Cases: []mesa.MethodCase[*Registry, map[string]int, string, mesa.OkPair[int]]{
+			{
+				Name:   "Known service",
+				Fields: map[string]int{"http": 80},
+				Input:  "http",
+				Check: func(ctx *mesa.Ctx, inst *Registry, service string, out mesa.OkPair[int]) {
+					ctx.As.Equal(80, mesa.MustOk(ctx, out))
+				},
+			},
+			{
+				Name:   "Unknown service",
+				Fields: map[string]int{"http": 80},
+				Input:  "gopher",
+				// The zero Expected output is not compared, so a missing value is checked explicitly.
+				Check: func(ctx *mesa.Ctx, inst *Registry, service string, out mesa.OkPair[int]) {
+					ctx.As.False(out.Ok)
+				},
+			},
+		},
+	}
+
+	var t *testing.T
+	m.Run(t)
+}
+
+func StatusCode(url string) (int, error) {
+	resp, err := http.DefaultClient.Get(url)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	return resp.StatusCode, nil
+}
+
+// redirectTo is an http.RoundTripper that sends every request to a stub server.
+type redirectTo string
+
+func (r redirectTo) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.URL.Scheme, req.URL.Host = "http", string(r)
+
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func ExampleSaveGlobal() {
+	m := mesa.FunctionMesa[string, mesa.ErrorPair[int]]{
+		// Cases replace http.DefaultClient, so it is restored after every case, even when the case fails.
+		SaveGlobals: []func() func(){mesa.SaveGlobal(&http.DefaultClient)},
+		BeforeCall: func(ctx *mesa.Ctx, url string) {
+			srv := ctx.StartServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusTeapot)
+			}))
+
+			http.DefaultClient = &http.Client{Transport: redirectTo(srv.Listener.Addr().String())}
+		},
+		Target: func(ctx *mesa.Ctx, url string) mesa.ErrorPair[int] {
+			return mesa.NewErrorPair(StatusCode(url))
+		},
+		Cases: []mesa.FunctionCase[string, mesa.ErrorPair[int]]{
+			{Name: "Stubbed", Input: "https://example.com/coffee", Expected: mesa.NewErrorPair(http.StatusTeapot, nil)},
+		},
+	}
+
+	var t *testing.T
+	m.Run(t)
+}
+
+func ExampleFunctionCase_expectNoErr() {
+	m := mesa.FunctionMesa[string, mesa.ErrorPair[int]]{
+		Target: func(ctx *mesa.Ctx, in string) mesa.ErrorPair[int] {
+			return mesa.NewErrorPair(strconv.Atoi(in))
+		},
+		Cases: []mesa.FunctionCase[string, mesa.ErrorPair[int]]{
+			{Name: "Positive", Input: "42", ExpectNoErr: true, ExpectedValue: 42},
+			{Name: "Negative", Input: "-7", ExpectNoErr: true, ExpectedValue: -7},
+			{Name: "Invalid", Input: "abc", ExpectErrMsg: `strconv.Atoi: parsing "abc": invalid syntax`},
+		},
+	}
+
+	var t *testing.T
+	m.Run(t)
+}
+
+func ExampleBaseHooks() {
+	db := map[string]int{}
+
+	// Every suite resets the database after each case.
+	hooks := mesa.BaseHooks[mesa.Empty]{
+		Cleanup: func(ctx *mesa.Ctx, _ mesa.Empty) {
+			clear(db)
+		},
+	}
+
+	insert := mesa.FunctionMesa[string, int]{
+		Target: func(ctx *mesa.Ctx, key string) int {
+			db[key]++
+			return db[key]
+		},
+		Cases: []mesa.FunctionCase[string, int]{
+			{Name: "First insert", Input: "a", Expected: 1},
+			{Name: "Starts from an empty database", Input: "a", Expected: 1},
+		},
+	}.WithHooks(hooks)
+
+	size := mesa.FunctionMesa[[]string, int]{
+		BeforeCall: func(ctx *mesa.Ctx, keys []string) {
+			for _, key := range keys {
+				db[key] = 0
+			}
+		},
+		Target: func(ctx *mesa.Ctx, _ []string) int {
+			return len(db)
+		},
+		Cases: []mesa.FunctionCase[[]string, int]{
+			{Name: "Two keys", Input: []string{"a", "b"}, Expected: 2},
+			{Name: "One key", Input: []string{"a"}, Expected: 1},
+		},
+	}.WithHooks(hooks)
+
+	lookup := mesa.FunctionMesa[string, bool]{
+		Target: func(ctx *mesa.Ctx, key string) bool {
+			_, ok := db[key]
+			return ok
+		},
+		Cases: []mesa.FunctionCase[string, bool]{
+			{Name: "Missing key", Input: "a", Expected: false},
+		},
+	}.WithHooks(hooks)
+
+	var t *testing.T
+	mesa.Run(t, insert, size, lookup)
+}
+
+func ExampleMethodBenchmarkMesa_profile() {
+	m := mesa.MethodBenchmarkMesa[mesa.Empty, mesa.Empty, []byte, [32]byte]{
+		// Writes profiles/size-1024.cpu.prof, profiles/size-1024.mem.prof and so on, which can be inspected with
+		// go tool pprof.
+		ProfileDir: "profiles",
+		CPUProfile: true,
+		MemProfile: true,
+		NewInstance: func(ctx *mesa.Ctx, _ mesa.Empty) mesa.Empty {
+			return nil
+		},
+		Target: func(ctx *mesa.Ctx, _ mesa.Empty, in []byte) [32]byte {
+			return sha256.Sum256(in)
+		},
+		Cases: mesa.SizeCases[mesa.Empty, mesa.Empty, []byte, [32]byte]([]int{1024, 1 << 20}, func(n int) []byte {
+			return make([]byte, n)
+		}),
+	}
+
+	var b *testing.B
+	m.Run(b)
+}
+
+func ExampleClosureCase() {
+	// prefixer returns a function that prepends prefix to its argument.
+	prefixer := func(prefix string) func(string) string {
+		return func(s string) string {
+			return prefix + s
+		}
+	}
+
+	m := mesa.FunctionMesa[string, func(string) string]{
+		Target: func(ctx *mesa.Ctx, prefix string) func(string) string {
+			return prefixer(prefix)
+		},
+		Cases: []mesa.FunctionCase[string, func(string) string]{
+			mesa.ClosureCase("Greeting", "hello, ",
+				mesa.ClosureCall[string, string]{Arg: "world", Expected: "hello, world"},
+				mesa.ClosureCall[string, string]{Arg: "gopher", Expected: "hello, gopher"},
+			),
+			mesa.ClosureCase("Empty prefix", "",
+				mesa.ClosureCall[string, string]{Arg: "world", Expected: "world"},
+			),
+		},
+	}
+
+	var t *testing.T
+	m.Run(t)
+}
+
+func ExampleCtx_WriteFile() {
+	// loadPort reads the port from a config file of key=value lines.
+	loadPort := func(path string) (int, error) {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return 0, err
+		}
+
+		for _, line := range strings.Split(string(data), "\n") {
+			if v, ok := strings.CutPrefix(line, "port="); ok {
+				return strconv.Atoi(v)
+			}
+		}
+
+		return 0, fmt.Errorf("%s has no port", path)
+	}
+
+	m := mesa.FunctionMesa[string, mesa.ErrorPair[int]]{
+		Target: func(ctx *mesa.Ctx, config string) mesa.ErrorPair[int] {
+			return mesa.NewErrorPair(loadPort(ctx.WriteFile("app.conf", []byte(config))))
+		},
+		Cases: []mesa.FunctionCase[string, mesa.ErrorPair[int]]{
+			{Name: "Port", Input: "host=localhost\nport=8080\n", ExpectNoErr: true, ExpectedValue: 8080},
+			{Name: "No port", Input: "host=localhost\n", ExpectErrRegex: "has no port$"},
+		},
+	}
+
+	var t *testing.T
+	m.Run(t)
+}
+
+func ExampleFunctionMesa_defaultInput() {
+	type options struct {
+		Host    string
+		Port    int
+		TLS     bool
+		Timeout time.Duration
+	}
+
+	url := func(o options) string {
+		scheme := "http"
+		if o.TLS {
+			scheme = "https"
+		}
+
+		return fmt.Sprintf("%s://%s:%d", scheme, o.Host, o.Port)
+	}
+
+	m := mesa.FunctionMesa[options, string]{
+		// Cases without an Input start from these options.
+		DefaultInput: options{Host: "localhost", Port: 8080, Timeout: time.Second},
+		Target: func(ctx *mesa.Ctx, in options) string {
+			return url(in)
+		},
+		Cases: []mesa.FunctionCase[options, string]{
+			{Name: "Default", Expected: "http://localhost:8080"},
+			{
+				Name: "TLS",
+				ModifyInput: func(base options) options {
+					base.TLS = true
+					return base
+				},
+				Expected: "https://localhost:8080",
+			},
+			{
+				Name:     "Own input",
+				Input:    options{Host: "example.com", Port: 443, TLS: true},
+				Expected: "https://example.com:443",
+			},
+		},
+	}
+
+	var t *testing.T
+	m.Run(t)
+}
+
+func ExampleMethodBenchmarkMesa_RunAgainst() {
+	insertionSort := func(s []int) {
+		for i := 1; i < len(s); i++ {
+			for j := i; j > 0 && s[j] < s[j-1]; j-- {
+				s[j], s[j-1] = s[j-1], s[j]
+			}
+		}
+	}
+
+	m := mesa.MethodBenchmarkMesa[mesa.Empty, mesa.Empty, []int, []int]{
+		NewInstance: func(ctx *mesa.Ctx, _ mesa.Empty) mesa.Empty {
+			return nil
+		},
+		Cases: mesa.SizeCases[mesa.Empty, mesa.Empty, []int, []int]([]int{16, 1024}, func(n int) []int {
+			s := make([]int, n)
+			for i := range s {
+				s[i] = n - i
+			}
+
+			return s
+		}),
+	}
+
+	// Both implementations sort a copy so every iteration starts from the same unsorted input.
+	var b *testing.B
+	m.RunAgainst(b, map[string]func(ctx *mesa.Ctx, _ mesa.Empty, in []int) []int{
+		"std": func(ctx *mesa.Ctx, _ mesa.Empty, in []int) []int {
+			s := append([]int(nil), in...)
+			sort.Ints(s)
+
+			return s
+		},
+		"insertion": func(ctx *mesa.Ctx, _ mesa.Empty, in []int) []int {
+			s := append([]int(nil), in...)
+			insertionSort(s)
+
+			return s
+		},
+	})
+}
+
+func ExampleMethodMesa_RunForTypes() {
+	m := mesa.MethodMesa[hash.Hash, mesa.Empty, string, int]{
+		Target: func(ctx *mesa.Ctx, h hash.Hash, in string) int {
+			h.Write([]byte(in))
+			return len(h.Sum(nil))
+		},
+		Check: func(ctx *mesa.Ctx, h hash.Hash, in string, out int) {
+			ctx.As.Equal(h.Size(), out)
+		},
+		Cases: []mesa.MethodCase[hash.Hash, mesa.Empty, string, int]{
+			{Name: "Empty", Input: ""},
+			{Name: "Text", Input: "hello"},
+		},
+	}
+
+	// Runs the cases as sha256/Empty, sha256/Text, sha512/Empty and sha512/Text.
+	var t *testing.T
+	m.RunForTypes(t, map[string]func(ctx *mesa.Ctx, _ mesa.Empty) hash.Hash{
+		"sha256": func(ctx *mesa.Ctx, _ mesa.Empty) hash.Hash {
+			return sha256.New()
+		},
+		"sha512": func(ctx *mesa.Ctx, _ mesa.Empty) hash.Hash {
+			return sha512.New()
+		},
+	})
+}
+
+func ExampleMust1() {
+	m := mesa.MethodMesa[*os.File, string, int, string]{
+		// Opening the file can't fail silently, and the case stops with the error if it does.
+		NewInstance: func(ctx *mesa.Ctx, path string) *os.File {
+			f, err := os.Open(path)
+			return mesa.Must1(ctx, f, err)
+		},
+		Target: func(ctx *mesa.Ctx, f *os.File, n int) string {
+			buf := make([]byte, n)
+			read, err := io.ReadFull(f, buf)
+
+			return string(buf[:mesa.Must1(ctx, read, err)])
+		},
+		Cleanup: func(ctx *mesa.Ctx, f *os.File) {
+			f.Close()
+		},
+		Cases: []mesa.MethodCase[*os.File, string, int, string]{
+			{Name: "Prefix", Fields: "testdata/fixtures/greeting.txt", Input: 5, Expected: "hello"},
+		},
+	}
+
+	var t *testing.T
+	m.Run(t)
+}
+
+func ExampleMust2() {
+	m := mesa.FunctionMesa[string, bool]{
+		Target: func(ctx *mesa.Ctx, in string) bool {
+			re, err := regexp.Compile(`^[a-z]+:\d+$`)
+			re = mesa.Must1(ctx, re, err)
+
+			host, port, err := net.SplitHostPort(in)
+			host, port = mesa.Must2(ctx, host, port, err)
+
+			return re.MatchString(host + ":" + port)
+		},
+		Cases: []mesa.FunctionCase[string, bool]{
+			{Name: "Valid", Input: "localhost:8080", Expected: true},
+			{Name: "Numeric host", Input: "127.0.0.1:80", Expected: false},
+		},
+	}
+
+	var t *testing.T
+	m.Run(t)
+}
+
+func ExampleFunctionMesa_normalize() {
+	type response struct {
+		ID        int
+		Status    string
+		CreatedAt time.Time
+	}
+
+	create := func(id int) response {
+		return response{ID: id, Status: "created", CreatedAt: time.Now()}
+	}
+
+	m := mesa.FunctionMesa[int, response]{
+		// The timestamp differs on every call, so it is redacted before the response is compared.
+		Normalize: func(out response) response {
+			out.CreatedAt = time.Time{}
+			return out
+		},
+		Target: func(ctx *mesa.Ctx, id int) response {
+			return create(id)
+		},
+		Cases: []mesa.FunctionCase[int, response]{
+			{Name: "Created", Input: 1, Expected: response{ID: 1, Status: "created"}},
+			{Name: "Another", Input: 2, Expected: response{ID: 2, Status: "created"}},
+		},
+	}
+
+	var t *testing.T
+	m.Run(t)
+}
+
+type Order struct {
+	ID        string
+	CreatedAt time.Time
+}
+
+func NewOrder(ctx *mesa.Ctx, id string) Order {
+	return Order{ID: id, CreatedAt: ctx.Now()}
+}
+
+func ExampleCtx_Now() {
+	m := mesa.FunctionMesa[string, Order]{
+		// The clock of every case is frozen, so the target and Check see the same time.
+		FrozenTime: time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC),
+		Target:     NewOrder,
+		Check: func(ctx *mesa.Ctx, id string, out Order) {
+			ctx.As.Equal(ctx.Now(), out.CreatedAt)
+		},
+		Cases: []mesa.FunctionCase[string, Order]{
+			{Name: "Timestamped", Input: "o-1"},
+		},
+	}
+
+	var t *testing.T
+	m.Run(t)
+}
+
+func ExampleFunctionCase_expectNoAllocs() {
+	m := mesa.FunctionMesa[[]byte, int]{
+		Target: func(ctx *mesa.Ctx, in []byte) int {
+			return bytes.Count(in, []byte{'\n'})
+		},
+		Cases: []mesa.FunctionCase[[]byte, int]{
+			// The count must not allocate, whatever the size of the input.
+			{Name: "Count lines", Input: []byte("a\nb\nc\n"), Expected: 3, ExpectNoAllocs: true},
+		},
+	}
+
+	var t *testing.T
+	m.Run(t)
+}
+
+func ExampleFunctionCase_sameAs() {
+	m := mesa.FunctionMesa[string, mesa.ErrorPair[int64]]{
+		Target: func(ctx *mesa.Ctx, in string) mesa.ErrorPair[int64] {
+			return mesa.NewErrorPair(strconv.ParseInt(in, 0, 64))
+		},
+		Cases: []mesa.FunctionCase[string, mesa.ErrorPair[int64]]{
+			{Name: "Decimal", Input: "42", Expected: mesa.ErrorPair[int64]{Value: 42}},
+			// Other spellings of the same number are in the same equivalence class, so they must parse the same.
+			{Name: "Hexadecimal", Input: "0x2a", SameAs: "Decimal"},
+			{Name: "Octal", Input: "0o52", SameAs: "Decimal"},
+			{Name: "Underscores", Input: "4_2", SameAs: "Octal"},
+		},
+	}
+
+	var t *testing.T
+	m.Run(t)
+}
+
+type SignupForm struct {
+	Email    string `mesa:"ada@example.com,not-an-email"`
+	Password string `mesa:"correct horse,short"`
+	Country  string
+}
+
+func ValidateSignup(form SignupForm) error {
+	if !strings.Contains(form.Email, "@") {
+		return fmt.Errorf("invalid email %q", form.Email)
+	}
+
+	if len(form.Password) < 8 {
+		return fmt.Errorf("password is too short")
+	}
+
+	return nil
+}
+
+func ExampleCasesFromStructTags() {
+	m := mesa.FunctionMesa[SignupForm, error]{
+		Target: func(ctx *mesa.Ctx, in SignupForm) error {
+			return ValidateSignup(in)
+		},
+		// The two tagged fields produce 2 x 2 cases, from "Email=ada@example.com,Password=correct horse" to
+		// "Email=not-an-email,Password=short". Country keeps the value of the template.
+		Cases: mesa.CasesFromStructTags(SignupForm{Country: "NZ"}, func(ctx *mesa.Ctx, in SignupForm, err error) {
+			valid := in.Email == "ada@example.com" && in.Password == "correct horse"
+			ctx.As.Equal(valid, err == nil)
+		}),
+	}
+
+	var t *testing.T
+	m.Run(t)
+}
+
+func ExampleFunctionMesa_progress() {
+	var cases []mesa.FunctionCase[int, bool]
+	for n := 0; n < 1000; n++ {
+		cases = append(cases, mesa.FunctionCase[int, bool]{Name: strconv.Itoa(n), Input: n, Expected: n%2 == 0})
+	}
+
+	m := mesa.FunctionMesa[int, bool]{
+		Target: func(ctx *mesa.Ctx, n int) bool {
+			return n%2 == 0
+		},
+		// Print a line every 100 cases instead of one per case.
+		Progress: func(done, total int) {
+			if done%100 == 0 || done == total {
+				fmt.Printf("%d/%d cases done\n", done, total)
+			}
+		},
+		Cases: cases,
+	}
+
+	var t *testing.T
+	m.Run(t)
+}
+
+func ExampleMethodBenchmarkCase_weight() {
+	m := mesa.MethodBenchmarkMesa[mesa.Empty, mesa.Empty, []byte, [32]byte]{
+		NewInstance: func(ctx *mesa.Ctx, _ mesa.Empty) mesa.Empty {
+			return nil
+		},
+		Target: func(ctx *mesa.Ctx, _ mesa.Empty, in []byte) [32]byte {
+			return sha256.Sum256(in)
+		},
+		// With -benchtime=100000x, the small input is hashed 100000 times but the large one only 100 times. Both
+		// report ns/op and MB/s for a single call.
+		Cases: []mesa.MethodBenchmarkCase[mesa.Empty, mesa.Empty, []byte, [32]byte]{
+			{Name: "64B", Input: make([]byte, 64), Bytes: 64},
+			{Name: "64KiB", Input: make([]byte, 64<<10), Bytes: 64 << 10, Weight: 0.001},
+		},
+	}
+
+	var b *testing.B
+	m.Run(b)
+}
+
+type LRUCache struct {
+	capacity int
+	items    map[string]*list.Element
+	order    *list.List
+}
+
+func NewLRUCache(capacity int) *LRUCache {
+	return &LRUCache{capacity: capacity, items: make(map[string]*list.Element, capacity), order: list.New()}
+}
+
+func ExampleMethodBenchmarkCase_benchmarkSetup() {
+	m := mesa.MethodBenchmarkMesa[*LRUCache, int, mesa.Empty, mesa.Empty]{
+		NewInstance: func(ctx *mesa.Ctx, capacity int) *LRUCache {
+			return NewLRUCache(capacity)
+		},
+		// Only the construction of the cache is benchmarked, so there is no target.
+		Cases: []mesa.MethodBenchmarkCase[*LRUCache, int, mesa.Empty, mesa.Empty]{
+			{Name: "Small", Fields: 16, BenchmarkSetup: true},
+			{Name: "Large", Fields: 1 << 16, BenchmarkSetup: true},
+		},
+	}
+
+	var b *testing.B
+	m.Run(b)
+}
+
+func ExampleMethodBenchmarkMesa_RunWithMetrics() {
+	m := mesa.MethodBenchmarkMesa[mesa.Empty, mesa.Empty, []byte, [32]byte]{
+		NewInstance: func(ctx *mesa.Ctx, _ mesa.Empty) mesa.Empty {
+			return nil
+		},
+		Target: func(ctx *mesa.Ctx, _ mesa.Empty, in []byte) [32]byte {
+			return sha256.Sum256(in)
+		},
+		Cases: mesa.SizeCases[mesa.Empty, mesa.Empty, []byte, [32]byte]([]int{64, 1024}, func(n int) []byte {
+			return make([]byte, n)
+		}),
+	}
+
+	var b *testing.B
+
+	// Write the metrics to a file that CI uploads as an artifact.
+	f, err := os.Create("bench-metrics.json")
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer f.Close()
+
+	m.RunWithMetrics(b, f, mesa.MetricsJSON)
+}
@@ -0,0 +1,22 @@
+package mesa
+
+import "github.com/google/go-cmp/cmp"
+
+// NewTestCtx exposes newCtx so tests can create contexts backed by a custom TestingT.
+var NewTestCtx = newCtx
+
+// Equal exposes Ctx.equal using the given diff settings.
+func Equal(ctx *Ctx, mode DiffMode, opts []cmp.Option, expected, actual any) bool {
+	ctx.opts = options{diffMode: mode, cmpOptions: opts}
+	return ctx.equal(expected, actual)
+}
+
+// Memo exposes memo so tests can look up its keys concurrently.
+type Memo struct {
+	m memo
+}
+
+// Get calls memo.get.
+func (m *Memo) Get(key string, compute func() any) any {
+	return m.m.get(key, compute)
+}
@@ -0,0 +1,70 @@
+package mesa
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// TempDir returns a directory that is unique to the case and removed once the case finishes. Unlike t.TempDir, every
+// call made by the same case returns the same directory, and it works the same for tests and benchmarks.
+func (c *Ctx) TempDir() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.tempDir != "" {
+		return c.tempDir
+	}
+
+	dir, err := os.MkdirTemp("", "mesa-")
+	c.Re.NoError(err, "%s: failed to create temp dir", c.name())
+
+	// ctx.Re does not stop the case in SoftOnly mode, and an empty dir would make paths relative to the working dir.
+	if err != nil {
+		return ""
+	}
+
+	c.cleanup(func() {
+		_ = os.RemoveAll(dir)
+
+		// The instances created in the timed loop of BenchmarkSetup each get a new dir.
+		c.mu.Lock()
+		if c.tempDir == dir {
+			c.tempDir = ""
+		}
+		c.mu.Unlock()
+	})
+
+	c.tempDir = dir
+
+	return dir
+}
+
+// WriteFile writes data to the file called name in the temp dir of the case and returns its full path, which can be
+// passed to a target that takes a path. Slashes in name create subdirectories. The case stops if the file can't be
+// written.
+func (c *Ctx) WriteFile(name string, data []byte) string {
+	dir := c.TempDir()
+	if dir == "" {
+		return ""
+	}
+
+	path := filepath.Join(dir, filepath.FromSlash(name))
+
+	c.Re.NoError(os.MkdirAll(filepath.Dir(path), 0o755), "%s: failed to create directory of %s", c.name(), name)
+	c.Re.NoError(os.WriteFile(path, data, 0o644), "%s: failed to write file %s", c.name(), path)
+
+	return path
+}
+
+// ReadFile returns the contents of the file at path, e.g. a file written by the target. Relative paths are resolved
+// against the temp dir of the case. The case stops if the file can't be read.
+func (c *Ctx) ReadFile(path string) []byte {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(c.TempDir(), filepath.FromSlash(path))
+	}
+
+	data, err := os.ReadFile(path)
+	c.Re.NoError(err, "%s: failed to read file %s", c.name(), path)
+
+	return data
+}
@@ -0,0 +1,100 @@
+package mesa_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/a20r/mesa"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCtx_WriteFile(t *testing.T) {
+	var dirs []string
+
+	m := mesa.FunctionMesa[string, string]{
+		BeforeCall: func(ctx *mesa.Ctx, name string) {
+			ctx.As.Equal(ctx.TempDir(), ctx.TempDir())
+			dirs = append(dirs, ctx.TempDir())
+		},
+		Target: func(ctx *mesa.Ctx, name string) string {
+			path := ctx.WriteFile(name, []byte("hello"))
+			ctx.As.Equal(filepath.Join(ctx.TempDir(), filepath.FromSlash(name)), path)
+
+			return string(ctx.ReadFile(path))
+		},
+		Cases: []mesa.FunctionCase[string, string]{
+			{Name: "File", Input: "a.txt", Expected: "hello"},
+			{Name: "Nested file", Input: "dir/b.txt", Expected: "hello"},
+		},
+	}
+
+	m.Run(t)
+
+	if assert.Len(t, dirs, 2) {
+		assert.NotEqual(t, dirs[0], dirs[1])
+
+		for _, dir := range dirs {
+			assert.NoDirExists(t, dir, "temp dir is removed once the case finishes")
+		}
+	}
+}
+
+func TestCtx_ReadFile_Relative(t *testing.T) {
+	m := mesa.FunctionMesa[string, string]{
+		Target: func(ctx *mesa.Ctx, name string) string {
+			return strings.ToUpper(string(ctx.ReadFile(name)))
+		},
+		BeforeCall: func(ctx *mesa.Ctx, name string) {
+			ctx.Re.NoError(os.WriteFile(filepath.Join(ctx.TempDir(), name), []byte("written"), 0o644))
+		},
+		Cases: []mesa.FunctionCase[string, string]{
+			{Name: "Relative path", Input: "out.txt", Expected: "WRITTEN"},
+		},
+	}
+
+	m.Run(t)
+}
+
+func TestCtx_ReadFile_Missing(t *testing.T) {
+	expectFailure(t, func(t *testing.T) {
+		m := mesa.FunctionMesa[string, mesa.Empty]{
+			BeforeCall: func(ctx *mesa.Ctx, name string) {
+				ctx.ReadFile(name)
+			},
+			Cases: []mesa.FunctionCase[string, mesa.Empty]{
+				{Name: "Missing", Input: "missing.txt"},
+			},
+		}
+
+		m.Run(t)
+	}, "TestCtx_ReadFile_Missing/Missing: failed to read file", "missing.txt")
+}
+
+func TestCtx_TempDir_Benchmark(t *testing.T) {
+	setBenchtime(t, "10x")
+
+	var dir string
+
+	m := mesa.MethodBenchmarkMesa[mesa.Empty, mesa.Empty, string, int]{
+		NewInstance: func(ctx *mesa.Ctx, _ mesa.Empty) mesa.Empty {
+			return nil
+		},
+		BeforeCall: func(ctx *mesa.Ctx, _ mesa.Empty, in string) {
+			dir = ctx.TempDir()
+			ctx.WriteFile("in.txt", []byte(in))
+		},
+		Target: func(ctx *mesa.Ctx, _ mesa.Empty, in string) int {
+			return len(in)
+		},
+		Cases: []mesa.MethodBenchmarkCase[mesa.Empty, mesa.Empty, string, int]{
+			{Name: "Write", Input: "data"},
+		},
+	}
+
+	testing.Benchmark(m.Run)
+
+	assert.NotEmpty(t, dir)
+	assert.NoDirExists(t, dir)
+}
@@ -0,0 +1,44 @@
+package mesa
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// Fixture returns the contents of the file at path relative to the fixture directory of the suite, testdata unless
+// FixtureDir is set. The case stops with a clear message if the file does not exist or cannot be read.
+func (c *Ctx) Fixture(path string) []byte {
+	dir := c.opts.fixtureDir
+	if dir == "" {
+		dir = "testdata"
+	}
+
+	full := filepath.Join(dir, filepath.FromSlash(path))
+
+	data, err := os.ReadFile(full)
+	if errors.Is(err, fs.ErrNotExist) {
+		c.Re.FailNow(c.name() + ": fixture " + full + " does not exist")
+		return nil
+	}
+
+	c.Re.NoError(err, "%s: failed to read fixture %s", c.name(), full)
+
+	return data
+}
+
+// FixtureString returns the contents of a fixture as a string. See Fixture.
+func (c *Ctx) FixtureString(path string) string {
+	return string(c.Fixture(path))
+}
+
+// FixtureJSON unmarshals the JSON fixture at path into a value of type T. The case stops if the fixture cannot be
+// read or is not valid JSON for T. See Ctx.Fixture.
+func FixtureJSON[T any](ctx *Ctx, path string) T {
+	var v T
+	ctx.Re.NoError(json.Unmarshal(ctx.Fixture(path), &v), "%s: failed to unmarshal fixture %s", ctx.name(), path)
+
+	return v
+}
@@ -0,0 +1,60 @@
+package mesa_test
+
+import (
+	"testing"
+
+	"github.com/a20r/mesa"
+	"github.com/stretchr/testify/assert"
+)
+
+type person struct {
+	Name string `json:"name"`
+	Age  int    `json:"age"`
+}
+
+func TestCtx_Fixture(t *testing.T) {
+	m := mesa.FunctionMesa[string, string]{
+		Target: func(ctx *mesa.Ctx, path string) string {
+			return ctx.FixtureString(path)
+		},
+		Cases: []mesa.FunctionCase[string, string]{
+			{Name: "Default directory", Input: "fixtures/greeting.txt", Expected: "hello fixture\n"},
+		},
+	}
+
+	m.Run(t)
+}
+
+func TestFixtureJSON(t *testing.T) {
+	m := mesa.FunctionMesa[string, person]{
+		FixtureDir: "testdata/fixtures",
+		Target: func(ctx *mesa.Ctx, path string) person {
+			return mesa.FixtureJSON[person](ctx, path)
+		},
+		Cases: []mesa.FunctionCase[string, person]{
+			{Name: "Configured directory", Input: "person.json", Expected: person{Name: "ada", Age: 36}},
+		},
+	}
+
+	m.Run(t)
+}
+
+func TestCtx_Fixture_Missing(t *testing.T) {
+	r := record(func(ctx *mesa.Ctx) {
+		ctx.Fixture("fixtures/missing.txt")
+		t.Error("Fixture should stop the case")
+	})
+
+	assert.True(t, r.failed)
+	assert.Contains(t, r.errors[0], "TestRecorder/case: fixture testdata/fixtures/missing.txt does not exist")
+}
+
+func TestFixtureJSON_Invalid(t *testing.T) {
+	r := record(func(ctx *mesa.Ctx) {
+		mesa.FixtureJSON[person](ctx, "fixtures/greeting.txt")
+		t.Error("FixtureJSON should stop the case")
+	})
+
+	assert.True(t, r.failed)
+	assert.Contains(t, r.errors[0], "failed to unmarshal fixture fixtures/greeting.txt")
+}
@@ -0,0 +1,25 @@
+package mesa
+
+import "reflect"
+
+// InDelta asserts that expected and actual are within delta of each other. NaN is only equal to NaN and an infinity
+// is only equal to the infinity of the same sign. The failure message includes the name of the case.
+func (c *Ctx) InDelta(expected, actual, delta float64) bool {
+	return c.As.InDelta(expected, actual, delta, "%s: values are not within %v of each other", c.name(), delta)
+}
+
+// InEpsilon asserts that the relative error between expected and actual is less than epsilon. The failure message
+// includes the name of the case.
+func (c *Ctx) InEpsilon(expected, actual, epsilon float64) bool {
+	return c.As.InEpsilon(expected, actual, epsilon, "%s: relative error is not less than %v", c.name(), epsilon)
+}
+
+// isFloat reports whether T is a floating point type.
+func isFloat[T any]() bool {
+	switch reflect.TypeOf((*T)(nil)).Elem().Kind() {
+	case reflect.Float32, reflect.Float64:
+		return true
+	default:
+		return false
+	}
+}
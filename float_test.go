@@ -0,0 +1,96 @@
+package mesa_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/a20r/mesa"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCtx_InDelta(t *testing.T) {
+	tests := []struct {
+		name             string
+		expected, actual float64
+		delta            float64
+		wantFailed       bool
+	}{
+		{name: "Within delta", expected: 1, actual: 1.05, delta: 0.1},
+		{name: "Outside delta", expected: 1, actual: 1.5, delta: 0.1, wantFailed: true},
+		{name: "Both NaN", expected: math.NaN(), actual: math.NaN(), delta: 0.1},
+		{name: "Actual NaN", expected: 1, actual: math.NaN(), delta: 0.1, wantFailed: true},
+		{name: "Expected NaN", expected: math.NaN(), actual: 1, delta: 0.1, wantFailed: true},
+		{name: "Same infinity", expected: math.Inf(1), actual: math.Inf(1), delta: 0.1},
+		{name: "Opposite infinities", expected: math.Inf(1), actual: math.Inf(-1), delta: 0.1, wantFailed: true},
+		{name: "Infinity and finite", expected: math.Inf(1), actual: math.MaxFloat64, delta: 0.1, wantFailed: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := record(func(ctx *mesa.Ctx) {
+				ctx.InDelta(tt.expected, tt.actual, tt.delta)
+			})
+
+			assert.Equal(t, tt.wantFailed, r.failed, r.errors)
+		})
+	}
+}
+
+func TestCtx_InEpsilon(t *testing.T) {
+	r := record(func(ctx *mesa.Ctx) {
+		ctx.InEpsilon(100, 101, 0.02)
+	})
+	assert.False(t, r.failed, r.errors)
+
+	r = record(func(ctx *mesa.Ctx) {
+		ctx.InEpsilon(100, 110, 0.02)
+	})
+	assert.True(t, r.failed)
+	assert.Contains(t, r.errors[0], "relative error is not less than 0.02")
+}
+
+func TestTolerance(t *testing.T) {
+	m := mesa.FunctionMesa[float64, float64]{
+		Target: func(ctx *mesa.Ctx, in float64) float64 {
+			return math.Sqrt(in)
+		},
+		Cases: []mesa.FunctionCase[float64, float64]{
+			{Name: "Irrational", Input: 2, Expected: 1.41421, Tolerance: 1e-5},
+			{Name: "Zero", Input: 1e-12, Expected: 0, Tolerance: 1e-5},
+			{Name: "NaN", Input: -1, Expected: math.NaN(), Tolerance: 1e-5},
+			{Name: "Infinity", Input: math.Inf(1), Expected: math.Inf(1), Tolerance: 1e-5},
+		},
+	}
+
+	m.Run(t)
+}
+
+func TestTolerance_Exceeded(t *testing.T) {
+	expectFailure(t, func(t *testing.T) {
+		m := mesa.FunctionMesa[float64, float64]{
+			Target: func(ctx *mesa.Ctx, in float64) float64 {
+				return math.Sqrt(in)
+			},
+			Cases: []mesa.FunctionCase[float64, float64]{
+				{Name: "Zero", Input: 1, Expected: 0, Tolerance: 1e-5},
+			},
+		}
+
+		m.Run(t)
+	}, "TestTolerance_Exceeded/Zero: output is not within 1e-05 of the expected output")
+}
+
+type celsius float32
+
+func TestTolerance_NamedFloat(t *testing.T) {
+	m := mesa.FunctionMesa[float64, celsius]{
+		Target: func(ctx *mesa.Ctx, in float64) celsius {
+			return celsius((in - 32) * 5 / 9)
+		},
+		Cases: []mesa.FunctionCase[float64, celsius]{
+			{Name: "Body temperature", Input: 98.6, Expected: 37, Tolerance: 1e-4},
+		},
+	}
+
+	m.Run(t)
+}
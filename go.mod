@@ -1,11 +1,14 @@
 module github.com/a20r/mesa
 
-go 1.20
+go 1.21
 
-require github.com/stretchr/testify v1.8.4
+require (
+	github.com/davecgh/go-spew v1.1.1
+	github.com/google/go-cmp v0.6.0
+	github.com/stretchr/testify v1.8.4
+)
 
 require (
-	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
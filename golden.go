@@ -0,0 +1,149 @@
+package mesa
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// GoldenJSON asserts that value, marshaled to JSON, is equivalent to the golden file
+// testdata/golden/<test name>/<name>.json. The golden file is written instead when the tests are run with
+// MESA_UPDATE=1, with sorted map keys and indentation so that it is stable. Documents are compared structurally, so
+// key order and whitespace don't matter, and nil and empty slices or maps are considered equal. On mismatch, every
+// differing path is reported.
+func (c *Ctx) GoldenJSON(name string, value any) bool {
+	path := filepath.Join("testdata", "golden", filepath.FromSlash(c.name()), name+".json")
+
+	data, err := json.MarshalIndent(value, "", "  ")
+	if !c.As.NoError(err, "%s: failed to marshal value to JSON", c.name()) {
+		return false
+	}
+
+	if envSet(updateEnv) {
+		err := os.MkdirAll(filepath.Dir(path), 0o755)
+		if err == nil {
+			err = os.WriteFile(path, append(data, '\n'), 0o644)
+		}
+
+		return c.As.NoError(err, "failed to update golden file %s", path)
+	}
+
+	want, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return c.As.Fail("golden file " + path + " does not exist, run the tests with MESA_UPDATE=1 to create it")
+	}
+
+	if !c.As.NoError(err, "failed to read golden file %s", path) {
+		return false
+	}
+
+	var w, g any
+
+	if err := json.Unmarshal(want, &w); err != nil {
+		return c.As.Fail(fmt.Sprintf("golden file %s is not valid JSON: %v", path, err))
+	}
+
+	// Unmarshaling the marshaled value can't fail.
+	_ = json.Unmarshal(data, &g)
+
+	if diffs := jsonDiff("$", w, g); len(diffs) > 0 {
+		return c.As.Fail(fmt.Sprintf("%s: value does not match golden file %s, run the tests with MESA_UPDATE=1 to "+
+			"update it:\n%s", c.name(), path, strings.Join(diffs, "\n")))
+	}
+
+	return true
+}
+
+// jsonDiff returns the paths at which the unmarshaled JSON documents want and got differ.
+func jsonDiff(path string, want, got any) []string {
+	if isEmptyJSON(want) && isEmptyJSON(got) {
+		return nil
+	}
+
+	switch w := want.(type) {
+	case map[string]any:
+		g, ok := got.(map[string]any)
+		if !ok {
+			break
+		}
+
+		keys := make([]string, 0, len(w)+len(g))
+		for k := range w {
+			keys = append(keys, k)
+		}
+
+		for k := range g {
+			if _, ok := w[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+
+		sort.Strings(keys)
+
+		var diffs []string
+
+		for _, k := range keys {
+			wv, wok := w[k]
+			gv, gok := g[k]
+
+			switch {
+			case !gok:
+				diffs = append(diffs, fmt.Sprintf("%s.%s: missing, want %s", path, k, formatJSON(wv)))
+			case !wok:
+				diffs = append(diffs, fmt.Sprintf("%s.%s: unexpected %s", path, k, formatJSON(gv)))
+			default:
+				diffs = append(diffs, jsonDiff(path+"."+k, wv, gv)...)
+			}
+		}
+
+		return diffs
+	case []any:
+		g, ok := got.([]any)
+		if !ok {
+			break
+		}
+
+		if len(w) != len(g) {
+			return []string{fmt.Sprintf("%s: want %d elements, got %d", path, len(w), len(g))}
+		}
+
+		var diffs []string
+		for i := range w {
+			diffs = append(diffs, jsonDiff(fmt.Sprintf("%s[%d]", path, i), w[i], g[i])...)
+		}
+
+		return diffs
+	}
+
+	if reflect.DeepEqual(want, got) {
+		return nil
+	}
+
+	return []string{fmt.Sprintf("%s: want %s, got %s", path, formatJSON(want), formatJSON(got))}
+}
+
+// isEmptyJSON reports whether v is null, an empty array or an empty object.
+func isEmptyJSON(v any) bool {
+	switch v := v.(type) {
+	case nil:
+		return true
+	case []any:
+		return len(v) == 0
+	case map[string]any:
+		return len(v) == 0
+	default:
+		return false
+	}
+}
+
+// formatJSON renders an unmarshaled JSON value compactly.
+func formatJSON(v any) string {
+	data, _ := json.Marshal(v)
+	return string(data)
+}
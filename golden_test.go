@@ -0,0 +1,105 @@
+package mesa_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/a20r/mesa"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type account struct {
+	ID     int             `json:"id"`
+	Name   string          `json:"name"`
+	Emails []string        `json:"emails"`
+	Roles  map[string]bool `json:"roles"`
+	Tags   []string        `json:"tags"`
+}
+
+func TestGoldenJSON(t *testing.T) {
+	m := mesa.FunctionMesa[string, account]{
+		Target: func(ctx *mesa.Ctx, name string) account {
+			return account{
+				ID:     7,
+				Name:   name,
+				Emails: []string{name + "@example.com"},
+				Roles:  map[string]bool{"admin": false, "viewer": true},
+				Tags:   []string{},
+			}
+		},
+		Check: func(ctx *mesa.Ctx, _ string, out account) {
+			ctx.GoldenJSON("user", out)
+		},
+		Cases: []mesa.FunctionCase[string, account]{
+			{Name: "Reordered", Input: "ada"},
+		},
+	}
+
+	m.Run(t)
+}
+
+func TestGoldenJSON_Mismatch(t *testing.T) {
+	r := record(func(ctx *mesa.Ctx) {
+		ctx.GoldenJSON("user", account{
+			ID:     7,
+			Name:   "ada",
+			Emails: []string{"ada@example.com"},
+			Roles:  map[string]bool{"admin": false, "viewer": true},
+		})
+	})
+
+	assert.True(t, r.failed)
+
+	msg := strings.Join(r.errors, "\n")
+	assert.Contains(t, msg, "$.emails: want 2 elements, got 1")
+	assert.Contains(t, msg, "$.roles.admin: want true, got false")
+	assert.Contains(t, msg, "$.team: missing, want \"engines\"")
+	assert.Contains(t, msg, "$.tags: unexpected null")
+	assert.NotContains(t, msg, "$.roles.viewer")
+	assert.NotContains(t, msg, "$.name")
+}
+
+func TestGoldenJSON_Missing(t *testing.T) {
+	r := record(func(ctx *mesa.Ctx) {
+		ctx.GoldenJSON("missing", 1)
+	})
+
+	assert.True(t, r.failed)
+	assert.Contains(t, strings.Join(r.errors, "\n"), "run the tests with MESA_UPDATE=1 to create it")
+}
+
+// chdir changes the working directory to dir until the test finishes, so that files written under testdata don't
+// leak into the repository.
+func chdir(t *testing.T, dir string) {
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(dir))
+	t.Cleanup(func() { require.NoError(t, os.Chdir(wd)) })
+}
+
+func TestGoldenJSON_Update(t *testing.T) {
+	chdir(t, t.TempDir())
+
+	t.Setenv("MESA_UPDATE", "1")
+
+	r := record(func(ctx *mesa.Ctx) {
+		ctx.GoldenJSON("user", account{ID: 7, Name: "ada"})
+		ctx.Snapshot([]byte("ada"))
+	})
+	require.False(t, r.failed, r.errors)
+
+	t.Setenv("MESA_UPDATE", "")
+
+	r = record(func(ctx *mesa.Ctx) {
+		ctx.GoldenJSON("user", account{ID: 7, Name: "ada"})
+		ctx.Snapshot([]byte("ada"))
+	})
+	assert.False(t, r.failed, r.errors)
+
+	data, err := os.ReadFile(filepath.Join("testdata", "snapshots", "TestRecorder", "case.snap"))
+	require.NoError(t, err)
+	assert.Equal(t, "ada", string(data))
+}
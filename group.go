@@ -0,0 +1,60 @@
+package mesa
+
+// pooledInstance is the instance shared by the cases of a group of GroupByFields, along with the error returned by
+// NewInstanceErr when it was built.
+type pooledInstance[InstanceType any] struct {
+	inst InstanceType
+	err  error
+}
+
+// newInstance builds the instance of a case with NewInstanceErr, or with NewInstance when it is not provided.
+func (m MethodMesa[Inst, F, I, O]) newInstance(ctx *Ctx, fields F) (Inst, error) {
+	if m.NewInstanceErr != nil {
+		ctx.trace("NewInstanceErr")
+		return m.NewInstanceErr(ctx, fields)
+	}
+
+	ctx.trace("NewInstance")
+
+	return m.NewInstance(ctx, fields), nil
+}
+
+// groupInstance returns the instance of the group that fields belong to. The first case of the group builds it with
+// a shared context, so that it outlives the case while its failures are reported on the case, and registers the
+// Cleanup of the suite to run when the suite finishes. Every other case of the group resets it with Reset first.
+func (m MethodMesa[Inst, F, I, O]) groupInstance(ctx, suite *Ctx, fields F) (Inst, error) {
+	key := m.GroupByFields(fields)
+	built := false
+
+	p := suite.instances.get(key, func() any {
+		built = true
+		ctx.trace("building the instance of group " + key)
+
+		inst, err := m.newInstance(ctx.sharedCtx(suite), fields)
+		if m.Cleanup != nil {
+			// The groups of parallel cases are built concurrently.
+			suite.mu.Lock()
+			suite.groupCleanups = append(suite.groupCleanups, func() {
+				suite.trace("Cleanup of group " + key)
+				m.Cleanup(suite, inst)
+			})
+			suite.mu.Unlock()
+		}
+
+		return pooledInstance[Inst]{inst: inst, err: err}
+	}).(pooledInstance[Inst])
+
+	if !built && p.err == nil && m.Reset != nil {
+		ctx.trace("Reset")
+		m.Reset(ctx, p.inst)
+	}
+
+	return p.inst, p.err
+}
+
+// cleanupGroups calls the cleanups registered for the instances of GroupByFields in reverse order.
+func (c *Ctx) cleanupGroups() {
+	for i := len(c.groupCleanups) - 1; i >= 0; i-- {
+		c.groupCleanups[i]()
+	}
+}
@@ -0,0 +1,185 @@
+package mesa_test
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/a20r/mesa"
+)
+
+// registry is an instance that is costly to build in real suites, e.g. because it loads a schema.
+type registry struct {
+	schema string
+	items  []string
+}
+
+func TestGroupByFields(t *testing.T) {
+	var (
+		built  = map[string]int{}
+		resets int
+		events []string
+	)
+
+	m := mesa.MethodMesa[*registry, string, string, int]{
+		NewInstance: func(ctx *mesa.Ctx, schema string) *registry {
+			built[schema]++
+			return &registry{schema: schema}
+		},
+		GroupByFields: func(schema string) string {
+			return schema
+		},
+		Reset: func(ctx *mesa.Ctx, r *registry) {
+			resets++
+			r.items = nil
+		},
+		Target: func(ctx *mesa.Ctx, r *registry, item string) int {
+			r.items = append(r.items, item)
+			return len(r.items)
+		},
+		Cleanup: func(ctx *mesa.Ctx, r *registry) {
+			events = append(events, "cleanup "+r.schema)
+		},
+		Teardown: func(ctx *mesa.Ctx) {
+			events = append(events, "teardown")
+		},
+		Cases: []mesa.MethodCase[*registry, string, string, int]{
+			{Name: "Users 1", Fields: "users", Input: "ada", Expected: 1},
+			{Name: "Users 2", Fields: "users", Input: "bob", Expected: 1},
+			{Name: "Orders 1", Fields: "orders", Input: "o-1", Expected: 1},
+			{Name: "Users 3", Fields: "users", Input: "cy", Expected: 1},
+			{Name: "Orders 2", Fields: "orders", Input: "o-2", Expected: 1},
+		},
+	}
+
+	t.Run("suite", m.Run)
+
+	assert.Equal(t, map[string]int{"users": 1, "orders": 1}, built)
+	assert.Equal(t, 3, resets, "every case but the first of its group resets the instance")
+	assert.Equal(t, []string{"cleanup orders", "cleanup users", "teardown"}, events)
+}
+
+func TestGroupByFields_CaseCleanup(t *testing.T) {
+	var cleanups []string
+
+	m := mesa.MethodMesa[*registry, string, string, int]{
+		NewInstance: func(ctx *mesa.Ctx, schema string) *registry {
+			return &registry{schema: schema}
+		},
+		GroupByFields: func(schema string) string {
+			return schema
+		},
+		Target: func(ctx *mesa.Ctx, r *registry, item string) int {
+			r.items = append(r.items, item)
+			return len(r.items)
+		},
+		Cleanup: func(ctx *mesa.Ctx, r *registry) {
+			cleanups = append(cleanups, "suite")
+		},
+		Cases: []mesa.MethodCase[*registry, string, string, int]{
+			{
+				Name:   "Case cleanup",
+				Fields: "users",
+				Input:  "ada",
+				Cleanup: func(ctx *mesa.Ctx, r *registry) {
+					cleanups = append(cleanups, "case")
+				},
+			},
+			{Name: "Without Reset", Fields: "users", Input: "bob", Expected: 2},
+		},
+	}
+
+	t.Run("suite", m.Run)
+
+	assert.Equal(t, []string{"case", "suite"}, cleanups)
+}
+
+func TestGroupByFields_NewInstanceErr(t *testing.T) {
+	expectFailure(t, func(t *testing.T) {
+		calls := 0
+		m := mesa.MethodMesa[*registry, string, string, int]{
+			NewInstanceErr: func(ctx *mesa.Ctx, schema string) (*registry, error) {
+				calls++
+				return nil, errors.New("schema not found")
+			},
+			GroupByFields: func(schema string) string {
+				return schema
+			},
+			Cases: []mesa.MethodCase[*registry, string, string, int]{
+				{Name: "Users 1", Fields: "users"},
+				{Name: "Users 2", Fields: "users"},
+			},
+		}
+
+		t.Run("suite", m.Run)
+		t.Logf("NewInstanceErr was called %d times", calls)
+	}, "failed to create instance", "NewInstanceErr was called 1 times")
+}
+
+func TestMethodMesa_Validate_Reset(t *testing.T) {
+	m := mesa.MethodMesa[*registry, string, string, int]{
+		NewInstance: func(ctx *mesa.Ctx, schema string) *registry {
+			return &registry{schema: schema}
+		},
+		Reset: func(ctx *mesa.Ctx, r *registry) {},
+		Cases: []mesa.MethodCase[*registry, string, string, int]{
+			{Name: "Case"},
+		},
+	}
+
+	assert.EqualError(t, m.Validate(), "Reset requires GroupByFields")
+}
+
+func TestGroupByFields_NewInstanceFails(t *testing.T) {
+	out, ok, err := runInSubprocess(t, func(t *testing.T) {
+		m := mesa.MethodMesa[*registry, string, string, int]{
+			NewInstance: func(ctx *mesa.Ctx, schema string) *registry {
+				ctx.As.Fail("schema " + schema + " is invalid")
+				ctx.T().Fatal("schema not loaded")
+				return nil
+			},
+			GroupByFields: func(schema string) string {
+				return schema
+			},
+			Cases: []mesa.MethodCase[*registry, string, string, int]{
+				{Name: "Users 1", Fields: "users"},
+			},
+		}
+
+		t.Run("suite", m.Run)
+	})
+	if !ok {
+		return
+	}
+
+	require.Error(t, err, out)
+	assert.Contains(t, out, "--- FAIL: TestGroupByFields_NewInstanceFails/suite/Users_1")
+	assert.Contains(t, out, "schema users is invalid")
+	assert.Contains(t, out, "schema not loaded")
+	assert.NotContains(t, out, "FailNow on a parent test")
+}
+
+func TestGroupByFields_SharedResources(t *testing.T) {
+	m := mesa.MethodMesa[*registry, string, string, int]{
+		NewInstance: func(ctx *mesa.Ctx, schema string) *registry {
+			return &registry{schema: ctx.WriteFile("schema.sql", []byte(schema))}
+		},
+		GroupByFields: func(schema string) string {
+			return schema
+		},
+		Target: func(ctx *mesa.Ctx, r *registry, item string) int {
+			data, err := os.ReadFile(r.schema)
+			ctx.NoErr(err)
+			return len(data)
+		},
+		Cases: []mesa.MethodCase[*registry, string, string, int]{
+			{Name: "Users 1", Fields: "users", Expected: 5},
+			{Name: "Users 2", Fields: "users", Expected: 5},
+		},
+	}
+
+	m.Run(t)
+}
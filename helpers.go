@@ -1,8 +1,111 @@
 package mesa
 
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
 // MustAssert asserts the type of the given value and fails the test if the input cannot be asserted to the type.
 func MustAssert[T any](ctx *Ctx, in any) T {
 	val, ok := in.(T)
 	ctx.Re.Truef(ok, "Cannot assert type: %v => %v", in, *new(T))
 	return val
 }
+
+// MustOk asserts that the Ok of the pair is true and returns its Value. The case stops if it is not, like MustAssert.
+// This unwraps outputs built with NewOkPair, e.g. of a map lookup, in a Check.
+func MustOk[T any](ctx *Ctx, p OkPair[T]) T {
+	ctx.Re.Truef(p.Ok, "%s: expected ok, got a value of %T that is not ok", ctx.name(), p.Value)
+	return p.Value
+}
+
+// TryAssert asserts the type of the given value and reports whether it succeeded. Unlike MustAssert, it does not fail
+// the test, which lets a Check branch on the type of a value.
+func TryAssert[T any](_ *Ctx, in any) (T, bool) {
+	val, ok := in.(T)
+	return val, ok
+}
+
+// ExpectType asserts that out holds a value of the concrete type T and returns it for further assertions. The case
+// stops if it does not, like MustAssert. This avoids repeating type switches in the Check of targets that return an
+// interface.
+func ExpectType[T any](ctx *Ctx, out any) T {
+	val, ok := out.(T)
+	ctx.Re.Truef(ok, "%s: expected output of type %T, got %T", ctx.name(), *new(T), out)
+	return val
+}
+
+// Implements asserts that v implements the interface Iface, e.g. that the value returned by a factory satisfies a
+// contract, and reports whether it does. The failure message names the interface and the methods that are missing.
+// Like ExpectType, it is generic so the interface doesn't have to be passed as a nil pointer.
+func Implements[Iface any](ctx *Ctx, v any) bool {
+	iface := reflect.TypeOf((*Iface)(nil)).Elem()
+	if iface.Kind() != reflect.Interface {
+		return ctx.As.Fail(fmt.Sprintf("%s: Implements requires an interface type, got %s", ctx.name(), iface))
+	}
+
+	if _, ok := v.(Iface); ok {
+		return true
+	}
+
+	if v == nil {
+		return ctx.As.Fail(fmt.Sprintf("%s: nil does not implement %s", ctx.name(), iface))
+	}
+
+	typ := reflect.TypeOf(v)
+
+	var missing []string
+
+	for i := 0; i < iface.NumMethod(); i++ {
+		want := iface.Method(i)
+
+		got, ok := typ.MethodByName(want.Name)
+		switch {
+		case !ok:
+			missing = append(missing, want.Name)
+		case !sameSignature(want.Type, got.Type):
+			missing = append(missing, want.Name+" (wrong signature)")
+		}
+	}
+
+	return ctx.As.Fail(fmt.Sprintf("%s: %s does not implement %s (missing methods: %s)", ctx.name(), typ, iface,
+		strings.Join(missing, ", ")))
+}
+
+// sameSignature reports whether the method of an interface and the method of a concrete type, whose first argument is
+// the receiver, take and return the same types.
+func sameSignature(iface, method reflect.Type) bool {
+	if iface.NumIn() != method.NumIn()-1 || iface.NumOut() != method.NumOut() ||
+		iface.IsVariadic() != method.IsVariadic() {
+		return false
+	}
+
+	for i := 0; i < iface.NumIn(); i++ {
+		if iface.In(i) != method.In(i+1) {
+			return false
+		}
+	}
+
+	for i := 0; i < iface.NumOut(); i++ {
+		if iface.Out(i) != method.Out(i) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Must1 stops the case if err is not nil and returns v otherwise. It unwraps the results of calls such as os.Open in
+// hooks without a separate error check. Go only spreads multiple results into a call that takes nothing else, so the
+// results are assigned first, e.g. f, err := os.Open(path) followed by return mesa.Must1(ctx, f, err).
+func Must1[T any](ctx *Ctx, v T, err error) T {
+	ctx.Re.NoError(err, "%s: unexpected error", ctx.name())
+	return v
+}
+
+// Must2 is the variant of Must1 for calls that return two values and an error.
+func Must2[A, B any](ctx *Ctx, a A, b B, err error) (A, B) {
+	ctx.Re.NoError(err, "%s: unexpected error", ctx.name())
+	return a, b
+}
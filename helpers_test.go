@@ -0,0 +1,228 @@
+package mesa_test
+
+import (
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/a20r/mesa"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMustAssert(t *testing.T) {
+	r := record(func(ctx *mesa.Ctx) {
+		assert.Equal(t, 1, mesa.MustAssert[int](ctx, 1))
+	})
+
+	assert.False(t, r.failed)
+
+	r = record(func(ctx *mesa.Ctx) {
+		mesa.MustAssert[string](ctx, 1)
+		t.Error("MustAssert should stop the case")
+	})
+
+	assert.True(t, r.failed)
+}
+
+func TestMustOk(t *testing.T) {
+	ports := map[string]int{"http": 80}
+
+	r := record(func(ctx *mesa.Ctx) {
+		port, ok := ports["http"]
+		assert.Equal(t, 80, mesa.MustOk(ctx, mesa.NewOkPair(port, ok)))
+	})
+
+	assert.False(t, r.failed)
+
+	r = record(func(ctx *mesa.Ctx) {
+		port, ok := ports["gopher"]
+		mesa.MustOk(ctx, mesa.NewOkPair(port, ok))
+		t.Error("MustOk should stop the case")
+	})
+
+	assert.True(t, r.failed)
+	assert.Contains(t, strings.Join(r.errors, "\n"), "TestRecorder/case: expected ok, got a value of int that is not ok")
+}
+
+func TestTryAssert(t *testing.T) {
+	r := record(func(ctx *mesa.Ctx) {
+		val, ok := mesa.TryAssert[string](ctx, "a")
+		assert.True(t, ok)
+		assert.Equal(t, "a", val)
+
+		num, ok := mesa.TryAssert[int](ctx, "a")
+		assert.False(t, ok)
+		assert.Zero(t, num)
+	})
+
+	assert.False(t, r.failed)
+}
+
+type shape interface {
+	Area() float64
+}
+
+type square struct {
+	side float64
+}
+
+func (s square) Area() float64 {
+	return s.side * s.side
+}
+
+type circle struct {
+	radius float64
+}
+
+func (c *circle) Area() float64 {
+	return 3 * c.radius * c.radius
+}
+
+func newShape(ctx *mesa.Ctx, kind string) shape {
+	if kind == "square" {
+		return square{side: 2}
+	}
+
+	return &circle{radius: 1}
+}
+
+func TestExpectType(t *testing.T) {
+	r := record(func(ctx *mesa.Ctx) {
+		sq := mesa.ExpectType[square](ctx, newShape(ctx, "square"))
+		assert.Equal(t, 2.0, sq.side)
+	})
+
+	assert.False(t, r.failed)
+
+	r = record(func(ctx *mesa.Ctx) {
+		mesa.ExpectType[square](ctx, newShape(ctx, "circle"))
+		t.Error("ExpectType should stop the case")
+	})
+
+	assert.True(t, r.failed)
+	assert.Contains(t, r.errors[0], "expected output of type mesa_test.square, got *mesa_test.circle")
+}
+
+func TestExpectedType(t *testing.T) {
+	m := mesa.FunctionMesa[string, shape]{
+		Target: newShape,
+		Cases: []mesa.FunctionCase[string, shape]{
+			{
+				Name:         "Square",
+				Input:        "square",
+				ExpectedType: square{},
+				Check: func(ctx *mesa.Ctx, _ string, out shape) {
+					ctx.As.Equal(2.0, mesa.ExpectType[square](ctx, out).side)
+				},
+			},
+			{
+				Name:         "Circle",
+				Input:        "circle",
+				ExpectedType: (*circle)(nil),
+			},
+		},
+	}
+
+	m.Run(t)
+}
+
+func TestExpectedType_Mismatch(t *testing.T) {
+	expectFailure(t, func(t *testing.T) {
+		m := mesa.FunctionMesa[string, shape]{
+			Target: newShape,
+			Cases: []mesa.FunctionCase[string, shape]{
+				{Name: "Circle", Input: "circle", ExpectedType: square{}},
+			},
+		}
+
+		m.Run(t)
+	}, "TestExpectedType_Mismatch/Circle: unexpected output type")
+}
+
+func TestMust1(t *testing.T) {
+	r := record(func(ctx *mesa.Ctx) {
+		n, err := strconv.Atoi("42")
+		assert.Equal(t, 42, mesa.Must1(ctx, n, err))
+	})
+
+	assert.False(t, r.failed)
+
+	r = record(func(ctx *mesa.Ctx) {
+		n, err := strconv.Atoi("x")
+		mesa.Must1(ctx, n, err)
+		t.Error("Must1 should stop the case")
+	})
+
+	assert.True(t, r.failed)
+	assert.Contains(t, strings.Join(r.errors, "\n"), "TestRecorder/case: unexpected error")
+	assert.Contains(t, strings.Join(r.errors, "\n"), `parsing "x": invalid syntax`)
+}
+
+func TestMust2(t *testing.T) {
+	r := record(func(ctx *mesa.Ctx) {
+		host, port, err := net.SplitHostPort("localhost:80")
+		host, port = mesa.Must2(ctx, host, port, err)
+		assert.Equal(t, "localhost", host)
+		assert.Equal(t, "80", port)
+	})
+
+	assert.False(t, r.failed)
+
+	r = record(func(ctx *mesa.Ctx) {
+		host, port, err := net.SplitHostPort("localhost")
+		mesa.Must2(ctx, host, port, err)
+		t.Error("Must2 should stop the case")
+	})
+
+	assert.True(t, r.failed)
+	assert.Contains(t, strings.Join(r.errors, "\n"), "missing port in address")
+}
+
+// scaler is implemented by shapes that can be resized.
+type scaler interface {
+	shape
+	Scale(factor float64)
+}
+
+type badScaler struct{}
+
+func (badScaler) Area() float64 { return 0 }
+
+func (badScaler) Scale(factor int) {}
+
+func TestImplements(t *testing.T) {
+	r := record(func(ctx *mesa.Ctx) {
+		assert.True(t, mesa.Implements[shape](ctx, newShape(ctx, "square")))
+		assert.True(t, mesa.Implements[shape](ctx, &circle{radius: 1}))
+	})
+
+	assert.False(t, r.failed)
+
+	r = record(func(ctx *mesa.Ctx) {
+		assert.False(t, mesa.Implements[shape](ctx, circle{radius: 1}))
+	})
+
+	assert.True(t, r.failed)
+	assert.Contains(t, r.errors[0], "mesa_test.circle does not implement mesa_test.shape (missing methods: Area)")
+
+	r = record(func(ctx *mesa.Ctx) {
+		assert.False(t, mesa.Implements[scaler](ctx, square{side: 2}))
+		assert.False(t, mesa.Implements[scaler](ctx, badScaler{}))
+		assert.False(t, mesa.Implements[scaler](ctx, nil))
+	})
+
+	assert.True(t, r.failed)
+	require.Len(t, r.errors, 3)
+	assert.Contains(t, r.errors[0], "mesa_test.square does not implement mesa_test.scaler (missing methods: Scale)")
+	assert.Contains(t, r.errors[1], "missing methods: Scale (wrong signature)")
+	assert.Contains(t, r.errors[2], "nil does not implement mesa_test.scaler")
+
+	r = record(func(ctx *mesa.Ctx) {
+		assert.False(t, mesa.Implements[square](ctx, square{}))
+	})
+
+	assert.True(t, r.failed)
+	assert.Contains(t, r.errors[0], "Implements requires an interface type, got mesa_test.square")
+}
@@ -0,0 +1,125 @@
+package mesa
+
+import "sync"
+
+// GlobalHook holds callbacks that are invoked around every case run by any Mesa. It allows a shared test-support
+// package to inject behavior, such as logging a trace id, without every test opting in.
+type GlobalHook struct {
+	// [Optional] BeforeCase is called at the start of each case, before the instance is created.
+	BeforeCase func(ctx *Ctx, name string)
+
+	// [Optional] AfterCase is called once each case finishes, after its Cleanup function.
+	AfterCase func(ctx *Ctx, name string)
+}
+
+// globalHooks holds the registered global hooks.
+var globalHooks struct {
+	sync.Mutex
+	hooks []*GlobalHook
+}
+
+// RegisterGlobalHook registers a hook that is invoked around every case. It returns a function that unregisters the
+// hook, which makes it easy to scope a hook to a single test with t.Cleanup.
+func RegisterGlobalHook(hook GlobalHook) (unregister func()) {
+	h := &hook
+
+	globalHooks.Lock()
+	defer globalHooks.Unlock()
+
+	globalHooks.hooks = append(globalHooks.hooks, h)
+
+	return func() {
+		globalHooks.Lock()
+		defer globalHooks.Unlock()
+
+		for i, registered := range globalHooks.hooks {
+			if registered == h {
+				globalHooks.hooks = append(globalHooks.hooks[:i:i], globalHooks.hooks[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// ClearGlobalHooks unregisters all global hooks.
+func ClearGlobalHooks() {
+	globalHooks.Lock()
+	defer globalHooks.Unlock()
+
+	globalHooks.hooks = nil
+}
+
+// runGlobalHooks calls the BeforeCase callback of every registered hook and registers their AfterCase callbacks as
+// a cleanup of the case. It must be called before the Cleanup function of the case is registered so that AfterCase
+// runs after it.
+func runGlobalHooks(ctx *Ctx, name string) {
+	globalHooks.Lock()
+	hooks := globalHooks.hooks
+	globalHooks.Unlock()
+
+	for _, h := range hooks {
+		if h.AfterCase != nil {
+			afterCase := h.AfterCase
+			ctx.cleanup(func() { afterCase(ctx, name) })
+		}
+	}
+
+	for _, h := range hooks {
+		if h.BeforeCase != nil {
+			h.BeforeCase(ctx, name)
+		}
+	}
+}
+
+// SaveGlobal returns a function for SaveGlobals that saves the value of the variable p points to and restores it when
+// the case finishes, e.g. SaveGlobal(&http.DefaultClient).
+func SaveGlobal[T any](p *T) func() (restore func()) {
+	return func() func() {
+		saved := *p
+		return func() { *p = saved }
+	}
+}
+
+// BaseHooks holds the suite hooks that related suites often share, e.g. resetting a database after every case. They
+// are merged into a suite with WithHooks. Hooks already set on the suite take priority, so a suite can still override
+// any of them.
+type BaseHooks[InstanceType any] struct {
+	// [Optional] Init is used as the Init function of the suite.
+	Init func(ctx *Ctx)
+
+	// [Optional] Cleanup is used as the Cleanup function of the suite. Function suites have no instance and pass nil.
+	Cleanup func(ctx *Ctx, inst InstanceType)
+
+	// [Optional] Teardown is used as the Teardown function of the suite.
+	Teardown func(ctx *Ctx)
+
+	// [Optional] OnFailure is used as the OnFailure function of the suite.
+	OnFailure func(ctx *Ctx, name string)
+}
+
+// WithHooks returns a copy of the suite whose unset Init, Cleanup, Teardown and OnFailure functions are taken from
+// hooks.
+func (m MethodMesa[Inst, F, I, O]) WithHooks(hooks BaseHooks[Inst]) MethodMesa[Inst, F, I, O] {
+	checkAndSet(&m.Init, m.Init == nil, hooks.Init)
+	checkAndSet(&m.Cleanup, m.Cleanup == nil, hooks.Cleanup)
+	checkAndSet(&m.Teardown, m.Teardown == nil, hooks.Teardown)
+	checkAndSet(&m.OnFailure, m.OnFailure == nil, hooks.OnFailure)
+
+	return m
+}
+
+// WithHooks returns a copy of the suite whose unset Init, Cleanup, Teardown and OnFailure functions are taken from
+// hooks.
+func (m FunctionMesa[I, O]) WithHooks(hooks BaseHooks[Empty]) FunctionMesa[I, O] {
+	checkAndSet(&m.Init, m.Init == nil, hooks.Init)
+	checkAndSet(&m.Teardown, m.Teardown == nil, hooks.Teardown)
+	checkAndSet(&m.OnFailure, m.OnFailure == nil, hooks.OnFailure)
+
+	if m.Cleanup == nil && hooks.Cleanup != nil {
+		m.Cleanup = func(ctx *Ctx) {
+			hooks.Cleanup(ctx, nil)
+		}
+	}
+
+	return m
+}
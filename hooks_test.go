@@ -0,0 +1,404 @@
+package mesa_test
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/a20r/mesa"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegisterGlobalHook(t *testing.T) {
+	var calls []string
+
+	unregister := mesa.RegisterGlobalHook(mesa.GlobalHook{
+		BeforeCase: func(ctx *mesa.Ctx, name string) {
+			calls = append(calls, "before "+name)
+		},
+		AfterCase: func(ctx *mesa.Ctx, name string) {
+			calls = append(calls, "after "+name)
+		},
+	})
+	t.Cleanup(unregister)
+
+	m := mesa.FunctionMesa[int, int]{
+		Target: func(ctx *mesa.Ctx, in int) int {
+			calls = append(calls, "target")
+			return in
+		},
+		Cleanup: func(ctx *mesa.Ctx) {
+			calls = append(calls, "cleanup")
+		},
+		Cases: []mesa.FunctionCase[int, int]{
+			{Name: "A", Input: 1},
+			{Name: "B", Input: 2},
+		},
+	}
+
+	m.Run(t)
+
+	assert.Equal(t, []string{
+		"before A", "target", "cleanup", "after A",
+		"before B", "target", "cleanup", "after B",
+	}, calls)
+
+	unregister()
+	calls = nil
+
+	m.Run(t)
+
+	assert.Equal(t, []string{"target", "cleanup", "target", "cleanup"}, calls)
+}
+
+func TestClearGlobalHooks(t *testing.T) {
+	calls := 0
+
+	for i := 0; i < 2; i++ {
+		mesa.RegisterGlobalHook(mesa.GlobalHook{
+			BeforeCase: func(ctx *mesa.Ctx, name string) {
+				calls++
+			},
+		})
+	}
+
+	mesa.ClearGlobalHooks()
+
+	m := mesa.FunctionMesa[int, int]{
+		Target: func(ctx *mesa.Ctx, in int) int {
+			return in
+		},
+		Cases: []mesa.FunctionCase[int, int]{
+			{Name: "A", Input: 1},
+		},
+	}
+
+	m.Run(t)
+
+	assert.Zero(t, calls)
+}
+
+func TestOnFailure(t *testing.T) {
+	out, ok, err := runInSubprocess(t, func(t *testing.T) {
+		m := mesa.FunctionMesa[int, int]{
+			OnFailure: func(ctx *mesa.Ctx, name string) {
+				ctx.T().Logf("on failure: %s", name)
+			},
+			Cleanup: func(ctx *mesa.Ctx) {
+				ctx.T().Log("cleanup")
+			},
+			Target: func(ctx *mesa.Ctx, in int) int {
+				return in
+			},
+			Cases: []mesa.FunctionCase[int, int]{
+				{Name: "Passing", Input: 1, Expected: 1},
+				{Name: "Failing", Input: 1, Expected: 2},
+			},
+		}
+
+		m.Run(t)
+	})
+	if !ok {
+		return
+	}
+
+	require.Error(t, err, "expected test to fail:\n%s", out)
+	assert.NotContains(t, out, "on failure: Passing")
+	assert.Contains(t, out, "on failure: Failing")
+
+	failing := out[strings.Index(out, "=== RUN   TestOnFailure/Failing"):]
+	assert.Less(t, strings.Index(failing, "on failure"), strings.Index(failing, "cleanup"))
+}
+
+func TestTraceLifecycle(t *testing.T) {
+	out, ok, err := runInSubprocess(t, func(t *testing.T) {
+		m := mesa.MethodMesa[*strings.Builder, mesa.Empty, string, int]{
+			TraceLifecycle: true,
+			Init:           func(ctx *mesa.Ctx) {},
+			Teardown:       func(ctx *mesa.Ctx) {},
+			NewInstance: func(ctx *mesa.Ctx, _ mesa.Empty) *strings.Builder {
+				return &strings.Builder{}
+			},
+			BeforeCall: func(ctx *mesa.Ctx, inst *strings.Builder, in string) {},
+			Target: func(ctx *mesa.Ctx, inst *strings.Builder, in string) int {
+				n, _ := inst.WriteString(in)
+				return n
+			},
+			Check:   func(ctx *mesa.Ctx, inst *strings.Builder, in string, out int) {},
+			Cleanup: func(ctx *mesa.Ctx, inst *strings.Builder) {},
+			Cases: []mesa.MethodCase[*strings.Builder, mesa.Empty, string, int]{
+				{
+					Name:  "Traced",
+					Input: "abc",
+					Check: func(ctx *mesa.Ctx, inst *strings.Builder, in string, out int) {},
+				},
+			},
+		}
+
+		m.Run(t)
+	})
+	if !ok {
+		return
+	}
+
+	require.NoError(t, err, out)
+
+	var phases []string
+	for _, line := range strings.Split(out, "\n") {
+		if _, phase, found := strings.Cut(line, "mesa trace: "); found {
+			phases = append(phases, phase)
+		}
+	}
+
+	assert.Equal(t, []string{
+		"TestTraceLifecycle: Init",
+		"TestTraceLifecycle/Traced: NewInstance",
+		"TestTraceLifecycle/Traced: BeforeCall of the suite",
+		"TestTraceLifecycle/Traced: Target",
+		"TestTraceLifecycle/Traced: Check of the case",
+		"TestTraceLifecycle/Traced: Cleanup of the suite",
+		"TestTraceLifecycle: Teardown",
+	}, phases)
+}
+
+func TestWithHooks(t *testing.T) {
+	var calls []string
+
+	hooks := mesa.BaseHooks[*MyStruct]{
+		Init: func(ctx *mesa.Ctx) {
+			calls = append(calls, "init")
+		},
+		Cleanup: func(ctx *mesa.Ctx, inst *MyStruct) {
+			calls = append(calls, "cleanup "+strconv.Itoa(inst.Value))
+		},
+		Teardown: func(ctx *mesa.Ctx) {
+			calls = append(calls, "teardown")
+		},
+	}
+
+	m := mesa.MethodMesa[*MyStruct, int, int, mesa.Empty]{
+		NewInstance: func(ctx *mesa.Ctx, fields int) *MyStruct {
+			return &MyStruct{Value: fields}
+		},
+		Target: func(ctx *mesa.Ctx, inst *MyStruct, in int) mesa.Empty {
+			inst.Add(in)
+			return nil
+		},
+		Teardown: func(ctx *mesa.Ctx) {
+			calls = append(calls, "own teardown")
+		},
+		Cases: []mesa.MethodCase[*MyStruct, int, int, mesa.Empty]{
+			{Name: "A", Fields: 1, Input: 2},
+		},
+	}.WithHooks(hooks)
+
+	m.Run(t)
+
+	assert.Equal(t, []string{"init", "cleanup 3", "own teardown"}, calls)
+}
+
+func TestFunctionMesa_WithHooks(t *testing.T) {
+	var calls []string
+
+	hooks := mesa.BaseHooks[mesa.Empty]{
+		Cleanup: func(ctx *mesa.Ctx, inst mesa.Empty) {
+			assert.Nil(t, inst)
+			calls = append(calls, "cleanup")
+		},
+	}
+
+	m := mesa.FunctionMesa[int, int]{
+		Target: func(ctx *mesa.Ctx, in int) int {
+			calls = append(calls, "target")
+			return in
+		},
+		Cases: []mesa.FunctionCase[int, int]{
+			{Name: "A", Input: 1},
+			{Name: "B", Input: 2, Cleanup: func(ctx *mesa.Ctx) {
+				calls = append(calls, "case cleanup")
+			}},
+		},
+	}.WithHooks(hooks)
+
+	m.Run(t)
+
+	assert.Equal(t, []string{"target", "cleanup", "target", "case cleanup"}, calls)
+}
+
+func TestInlineCleanup(t *testing.T) {
+	var events []string
+
+	m := mesa.FunctionMesa[string, string]{
+		InlineCleanup: true,
+		Target: func(ctx *mesa.Ctx, in string) string {
+			ctx.WithValue("case", in)
+			events = append(events, "target "+in)
+			return in
+		},
+		Check: func(ctx *mesa.Ctx, in string, out string) {
+			events = append(events, "check "+in)
+		},
+		Cleanup: func(ctx *mesa.Ctx) {
+			events = append(events, "cleanup "+ctx.Value("case").(string))
+		},
+		Cases: []mesa.FunctionCase[string, string]{
+			{Name: "A", Input: "a"},
+			{Name: "B", Input: "b"},
+		},
+	}
+
+	m.Run(t)
+
+	assert.Equal(t, []string{"target a", "check a", "cleanup a", "target b", "check b", "cleanup b"}, events)
+}
+
+func TestInlineCleanup_Failure(t *testing.T) {
+	expectFailure(t, func(t *testing.T) {
+		cleaned := false
+		m := mesa.FunctionMesa[string, string]{
+			InlineCleanup: true,
+			Target: func(ctx *mesa.Ctx, in string) string {
+				return in
+			},
+			Check: func(ctx *mesa.Ctx, in string, out string) {
+				ctx.Re.Fail("stopped by Check")
+			},
+			Cleanup: func(ctx *mesa.Ctx) {
+				cleaned = true
+			},
+			OnFailure: func(ctx *mesa.Ctx, name string) {
+				t.Logf("cleaned up before OnFailure: %v", cleaned)
+			},
+			Cases: []mesa.FunctionCase[string, string]{
+				{Name: "Stopped", Input: "a"},
+			},
+		}
+
+		m.Run(t)
+	}, "stopped by Check", "cleaned up before OnFailure: true")
+}
+
+func TestInit_FailureStopsSuite(t *testing.T) {
+	tests := []struct {
+		name         string
+		init         func(ctx *mesa.Ctx)
+		alreadyFails bool
+	}{
+		{
+			name: "Require",
+			init: func(ctx *mesa.Ctx) {
+				ctx.Re.NoError(errors.New("database is unreachable"))
+				ctx.T().Log("Init continued")
+			},
+		},
+		{
+			name: "Assert",
+			init: func(ctx *mesa.Ctx) {
+				ctx.As.NoError(errors.New("database is unreachable"))
+			},
+		},
+		{
+			name: "Test already failed",
+			init: func(ctx *mesa.Ctx) {
+				ctx.As.NoError(errors.New("database is unreachable"))
+			},
+			alreadyFails: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, ok, err := runInSubprocess(t, func(t *testing.T) {
+				if tt.alreadyFails {
+					t.Error("an earlier suite failed")
+				}
+
+				m := mesa.FunctionMesa[int, int]{
+					Init: tt.init,
+					Teardown: func(ctx *mesa.Ctx) {
+						ctx.T().Log("Teardown ran")
+					},
+					Target: func(ctx *mesa.Ctx, in int) int {
+						ctx.T().Log("case ran")
+						return in
+					},
+					Cases: []mesa.FunctionCase[int, int]{
+						{Name: "First", Input: 1},
+						{Name: "Second", Input: 2},
+					},
+				}
+
+				m.Run(t)
+			})
+			if !ok {
+				return
+			}
+
+			require.Error(t, err, out)
+			assert.Contains(t, out, "database is unreachable")
+			assert.Contains(t, out, "Teardown ran")
+			assert.NotContains(t, out, "case ran")
+			assert.NotContains(t, out, "Init continued")
+		})
+	}
+}
+
+var (
+	defaultRegion  = "us-east-1"
+	defaultRetries = 3
+)
+
+func TestSaveGlobals(t *testing.T) {
+	var restored []string
+
+	m := mesa.FunctionMesa[string, string]{
+		SaveGlobals: []func() func(){
+			mesa.SaveGlobal(&defaultRegion),
+			mesa.SaveGlobal(&defaultRetries),
+			func() func() {
+				return func() { restored = append(restored, "custom") }
+			},
+		},
+		Target: func(ctx *mesa.Ctx, in string) string {
+			previous := defaultRegion
+			defaultRegion = in
+			defaultRetries++
+			return previous
+		},
+		Cases: []mesa.FunctionCase[string, string]{
+			{Name: "EU", Input: "eu-west-1", Expected: "us-east-1"},
+			{Name: "AP", Input: "ap-south-1", Expected: "us-east-1"},
+		},
+	}
+
+	m.Run(t)
+
+	assert.Equal(t, "us-east-1", defaultRegion)
+	assert.Equal(t, 3, defaultRetries)
+	assert.Equal(t, []string{"custom", "custom"}, restored)
+}
+
+func TestSaveGlobals_Failure(t *testing.T) {
+	expectFailure(t, func(t *testing.T) {
+		m := mesa.FunctionMesa[string, string]{
+			SaveGlobals: []func() func(){mesa.SaveGlobal(&defaultRegion)},
+			Target: func(ctx *mesa.Ctx, in string) string {
+				previous := defaultRegion
+				defaultRegion = in
+				ctx.Re.NotEqual("eu-west-1", in, "stopped by Target")
+				return previous
+			},
+			Check: func(ctx *mesa.Ctx, in string, out string) {
+				t.Logf("region at the start of %s: %s", in, out)
+			},
+			Cases: []mesa.FunctionCase[string, string]{
+				{Name: "EU", Input: "eu-west-1"},
+				{Name: "AP", Input: "ap-south-1"},
+			},
+		}
+
+		m.Run(t)
+	}, "stopped by Target", "region at the start of ap-south-1: us-east-1")
+}
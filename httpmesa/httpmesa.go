@@ -0,0 +1,133 @@
+// Package httpmesa provides a Mesa for table tests of http.Handler implementations.
+package httpmesa
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/a20r/mesa"
+)
+
+// Type assertion to ensure the handler mesa adheres to the Mesa interface
+var _ mesa.Mesa = HandlerMesa{}
+
+// RequestSpec describes the request sent to the handler of a case.
+type RequestSpec struct {
+	// [Optional] HTTP method of the request. Defaults to GET.
+	Method string
+
+	// [Required] Path of the request, optionally with a query string.
+	Path string
+
+	// [Optional] Body of the request.
+	Body string
+
+	// [Optional] Headers of the request.
+	Headers map[string]string
+}
+
+// Request builds the request described by the spec. The request carries the context of the case.
+func (s RequestSpec) Request(ctx *mesa.Ctx) *http.Request {
+	method := s.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	req := httptest.NewRequest(method, s.Path, strings.NewReader(s.Body)).WithContext(ctx)
+	for k, v := range s.Headers {
+		req.Header.Set(k, v)
+	}
+
+	return req
+}
+
+// HandlerCase represents a test case with its associated properties.
+type HandlerCase struct {
+	// [Optional] Name of the test case. When it is empty, the name is derived from the Request with %v, like the names
+	// of mesa cases are derived from their input. Explicit names are still recommended since they say what the case is
+	// about.
+	Name string
+
+	// [Required] Request sent to the handler.
+	Request RequestSpec
+
+	// [Optional] Skip the test case with the provided reason.
+	Skip string
+
+	// [Optional] ExpectedStatus asserts the status code of the response if set.
+	ExpectedStatus int
+
+	// [Optional] ExpectedBody asserts the body of the response if set.
+	ExpectedBody string
+
+	// [Optional] ExpectedHeaders asserts the value of each of the given response headers.
+	ExpectedHeaders map[string]string
+
+	// [Optional] Function to check the recorded response. It will be called instead of the Check function in the
+	// HandlerMesa if provided.
+	Check func(ctx *mesa.Ctx, in RequestSpec, rec *httptest.ResponseRecorder)
+}
+
+// HandlerMesa represents a collection of test cases that send a request to a handler and check the recorded response.
+type HandlerMesa struct {
+	// [Required] Handler under test.
+	Handler http.Handler
+
+	// [Optional] Function to check the recorded response. This is called when no Check function is provided by the
+	// case itself.
+	Check func(ctx *mesa.Ctx, in RequestSpec, rec *httptest.ResponseRecorder)
+
+	// [Required] List of test cases
+	Cases []HandlerCase
+}
+
+// Run executes all the test cases in the HandlerMesa instance.
+func (m HandlerMesa) Run(t *testing.T) {
+	fm := mesa.FunctionMesa[RequestSpec, *httptest.ResponseRecorder]{
+		Target: func(ctx *mesa.Ctx, in RequestSpec) *httptest.ResponseRecorder {
+			rec := httptest.NewRecorder()
+			m.Handler.ServeHTTP(rec, in.Request(ctx))
+
+			return rec
+		},
+		Cases: make([]mesa.FunctionCase[RequestSpec, *httptest.ResponseRecorder], len(m.Cases)),
+	}
+
+	for i, c := range m.Cases {
+		c := c
+		fm.Cases[i] = mesa.FunctionCase[RequestSpec, *httptest.ResponseRecorder]{
+			Name:  c.Name,
+			Input: c.Request,
+			Skip:  c.Skip,
+			Check: func(ctx *mesa.Ctx, in RequestSpec, rec *httptest.ResponseRecorder) {
+				c.assert(ctx, rec)
+
+				switch {
+				case c.Check != nil:
+					c.Check(ctx, in, rec)
+				case m.Check != nil:
+					m.Check(ctx, in, rec)
+				}
+			},
+		}
+	}
+
+	fm.Run(t)
+}
+
+// assert checks the expectations of the case against the recorded response.
+func (c HandlerCase) assert(ctx *mesa.Ctx, rec *httptest.ResponseRecorder) {
+	if c.ExpectedStatus != 0 {
+		ctx.As.Equal(c.ExpectedStatus, rec.Code, "unexpected status code")
+	}
+
+	if c.ExpectedBody != "" {
+		ctx.As.Equal(c.ExpectedBody, rec.Body.String(), "unexpected body")
+	}
+
+	for k, v := range c.ExpectedHeaders {
+		ctx.As.Equal(v, rec.Header().Get(k), "unexpected value of header %s", k)
+	}
+}
@@ -0,0 +1,104 @@
+package httpmesa_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/a20r/mesa"
+	"github.com/a20r/mesa/httpmesa"
+	"github.com/stretchr/testify/assert"
+)
+
+func greet(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		name := r.URL.Query().Get("name")
+		if name == "" {
+			http.Error(w, "missing name", http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/plain")
+		io.WriteString(w, "hello "+name)
+	case http.MethodPost:
+		if r.Header.Get("Content-Type") != "application/json" {
+			http.Error(w, "unsupported content type", http.StatusUnsupportedMediaType)
+			return
+		}
+
+		var body struct{ Name string }
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{"greeting": "hello " + body.Name})
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func TestHandlerMesa(t *testing.T) {
+	m := httpmesa.HandlerMesa{
+		Handler: http.HandlerFunc(greet),
+		Cases: []httpmesa.HandlerCase{
+			{
+				Name:            "Query",
+				Request:         httpmesa.RequestSpec{Path: "/greet?name=ada"},
+				ExpectedStatus:  http.StatusOK,
+				ExpectedBody:    "hello ada",
+				ExpectedHeaders: map[string]string{"Content-Type": "text/plain"},
+			},
+			{
+				Name:           "Missing name",
+				Request:        httpmesa.RequestSpec{Path: "/greet"},
+				ExpectedStatus: http.StatusBadRequest,
+				ExpectedBody:   "missing name\n",
+			},
+			{
+				Name: "JSON body",
+				Request: httpmesa.RequestSpec{
+					Method:  http.MethodPost,
+					Path:    "/greet",
+					Body:    `{"name": "grace"}`,
+					Headers: map[string]string{"Content-Type": "application/json"},
+				},
+				ExpectedStatus: http.StatusOK,
+				Check: func(ctx *mesa.Ctx, _ httpmesa.RequestSpec, rec *httptest.ResponseRecorder) {
+					ctx.EqualJSON(`{"greeting": "hello grace"}`, rec.Body.String())
+				},
+			},
+			{
+				Name:           "Unsupported method",
+				Request:        httpmesa.RequestSpec{Method: http.MethodDelete, Path: "/greet"},
+				ExpectedStatus: http.StatusMethodNotAllowed,
+			},
+		},
+	}
+
+	m.Run(t)
+}
+
+func TestHandlerMesa_SuiteCheck(t *testing.T) {
+	var checked []string
+
+	m := httpmesa.HandlerMesa{
+		Handler: http.HandlerFunc(greet),
+		Check: func(ctx *mesa.Ctx, in httpmesa.RequestSpec, rec *httptest.ResponseRecorder) {
+			checked = append(checked, in.Path)
+			ctx.As.Equal(http.StatusOK, rec.Code)
+		},
+		Cases: []httpmesa.HandlerCase{
+			{Name: "Ada", Request: httpmesa.RequestSpec{Path: "/greet?name=ada"}},
+			{Name: "Grace", Request: httpmesa.RequestSpec{Path: "/greet?name=grace"}},
+		},
+	}
+
+	m.Run(t)
+
+	assert.Equal(t, []string{"/greet?name=ada", "/greet?name=grace"}, checked)
+}
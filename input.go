@@ -0,0 +1,72 @@
+package mesa
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+)
+
+// stringerType is the type of fmt.Stringer, whose implementations are printed as a whole rather than field by field.
+var stringerType = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+
+// formatInputTable renders the fields of a struct input as an aligned table of name and value pairs. Nested structs
+// are flattened with dotted paths. It returns an empty string when the input is not a struct or a pointer to one, or
+// when the struct is printed as a whole, such as a time.Time.
+func formatInputTable(in any) string {
+	v := reflect.ValueOf(in)
+	for v.Kind() == reflect.Pointer && !v.IsNil() {
+		v = v.Elem()
+	}
+
+	if !flattened(v) {
+		return ""
+	}
+
+	var sb strings.Builder
+
+	w := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', 0)
+	writeInputFields(w, "", v)
+	w.Flush()
+
+	return sb.String()
+}
+
+// writeInputFields writes one row per leaf field of the struct v, prefixing the field names with prefix. Non-nil
+// pointers to structs are dereferenced, so that the row holds the struct rather than its address.
+func writeInputFields(w *tabwriter.Writer, prefix string, v reflect.Value) {
+	for i := 0; i < v.NumField(); i++ {
+		name := prefix + v.Type().Field(i).Name
+		field := v.Field(i)
+
+		if field.Kind() == reflect.Pointer && !field.IsNil() && field.Elem().Kind() == reflect.Struct &&
+			!field.Type().Implements(stringerType) {
+			field = field.Elem()
+		}
+
+		if flattened(field) {
+			writeInputFields(w, name+".", field)
+			continue
+		}
+
+		// fmt prints the value held by a reflect.Value, which also works for unexported fields.
+		fmt.Fprintf(w, "%s\t%+v\n", name, field)
+	}
+}
+
+// flattened reports whether v is a struct whose fields are printed one per row. Structs that implement fmt.Stringer or
+// only have unexported fields, such as time.Time, are printed as a whole instead, since their fields are internals.
+func flattened(v reflect.Value) bool {
+	if v.Kind() != reflect.Struct || v.Type().Implements(stringerType) ||
+		reflect.PointerTo(v.Type()).Implements(stringerType) {
+		return false
+	}
+
+	for i := 0; i < v.NumField(); i++ {
+		if v.Type().Field(i).IsExported() {
+			return true
+		}
+	}
+
+	return false
+}
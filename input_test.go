@@ -0,0 +1,119 @@
+package mesa_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/a20r/mesa"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type address struct {
+	City    string
+	Country string
+}
+
+type signup struct {
+	Name    string
+	Age     int
+	Address address
+}
+
+func TestPrintInputOnFailure(t *testing.T) {
+	out, ok, err := runInSubprocess(t, func(t *testing.T) {
+		m := mesa.FunctionMesa[signup, bool]{
+			PrintInputOnFailure: true,
+			Target: func(ctx *mesa.Ctx, in signup) bool {
+				return in.Age >= 18
+			},
+			Cases: []mesa.FunctionCase[signup, bool]{
+				{
+					Name:     "Adult",
+					Input:    signup{Name: "alice", Age: 30, Address: address{City: "Paris", Country: "FR"}},
+					Expected: true,
+				},
+				{
+					Name:     "Minor",
+					Input:    signup{Name: "bob", Age: 12, Address: address{City: "Oslo", Country: "NO"}},
+					Expected: true,
+				},
+			},
+		}
+
+		m.Run(t)
+	})
+	if !ok {
+		return
+	}
+
+	require.Error(t, err, "expected test to fail:\n%s", out)
+	assert.NotContains(t, out, "alice")
+	assert.Contains(t, out, "mesa input:")
+	assert.Contains(t, out, "Name             bob")
+	assert.Contains(t, out, "Age              12")
+	assert.Contains(t, out, "Address.City     Oslo")
+	assert.Contains(t, out, "Address.Country  NO")
+}
+
+func TestPrintInputOnFailure_NotStruct(t *testing.T) {
+	out, ok, err := runInSubprocess(t, func(t *testing.T) {
+		m := mesa.FunctionMesa[int, int]{
+			PrintInputOnFailure: true,
+			Target: func(ctx *mesa.Ctx, in int) int {
+				return in
+			},
+			Cases: []mesa.FunctionCase[int, int]{
+				{Name: "Mismatch", Input: 1, Expected: 2},
+			},
+		}
+
+		m.Run(t)
+	})
+	if !ok {
+		return
+	}
+
+	require.Error(t, err, "expected test to fail:\n%s", out)
+	assert.NotContains(t, out, "mesa input:")
+}
+
+type booking struct {
+	Guest   *address
+	Arrival time.Time
+	Nights  int
+}
+
+func TestPrintInputOnFailure_Leaves(t *testing.T) {
+	out, ok, err := runInSubprocess(t, func(t *testing.T) {
+		m := mesa.FunctionMesa[booking, int]{
+			PrintInputOnFailure: true,
+			Target: func(ctx *mesa.Ctx, in booking) int {
+				return in.Nights
+			},
+			Cases: []mesa.FunctionCase[booking, int]{
+				{
+					Name: "Mismatch",
+					Input: booking{
+						Guest:   &address{City: "Lima", Country: "PE"},
+						Arrival: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+						Nights:  2,
+					},
+					Expected: 3,
+				},
+			},
+		}
+
+		m.Run(t)
+	})
+	if !ok {
+		return
+	}
+
+	require.Error(t, err, "expected test to fail:\n%s", out)
+	assert.Contains(t, out, "Guest.City     Lima")
+	assert.Contains(t, out, "Guest.Country  PE")
+	assert.Contains(t, out, "Arrival        2024-03-01 00:00:00 +0000 UTC")
+	assert.NotContains(t, out, "wall")
+	assert.NotContains(t, out, "0x")
+}
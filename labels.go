@@ -0,0 +1,42 @@
+package mesa
+
+import (
+	"sort"
+	"strings"
+)
+
+// mergeLabels returns the union of the suite and case labels. Case labels take priority over suite labels.
+func mergeLabels(suite, c map[string]string) map[string]string {
+	if len(suite) == 0 {
+		return c
+	}
+
+	merged := make(map[string]string, len(suite)+len(c))
+
+	for k, v := range suite {
+		merged[k] = v
+	}
+
+	for k, v := range c {
+		merged[k] = v
+	}
+
+	return merged
+}
+
+// formatLabels formats labels as space separated key=value pairs sorted by key.
+func formatLabels(labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	pairs := make([]string, len(keys))
+	for i, k := range keys {
+		pairs[i] = k + "=" + labels[k]
+	}
+
+	return strings.Join(pairs, " ")
+}
@@ -0,0 +1,65 @@
+package mesa
+
+import (
+	"bytes"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// leakSettle is how long goroutines started by a case are given to exit once it finishes before they are reported.
+const leakSettle = time.Second
+
+// goroutines returns the stacks of all goroutines keyed by goroutine id, e.g. "goroutine 7".
+func goroutines() map[string]string {
+	buf := make([]byte, 1<<16)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+
+		buf = make([]byte, 2*len(buf))
+	}
+
+	stacks := make(map[string]string)
+
+	for _, stack := range bytes.Split(buf, []byte("\n\n")) {
+		s := string(stack)
+		if id, _, ok := strings.Cut(s, " ["); ok {
+			stacks[id] = s
+		}
+	}
+
+	return stacks
+}
+
+// detectLeaks records the goroutines running when a case starts and returns a function that fails the case if
+// goroutines started since are still running once they had leakSettle to exit.
+func detectLeaks() func(ctx *Ctx) {
+	before := goroutines()
+
+	return func(ctx *Ctx) {
+		var leaked []string
+
+		for deadline := time.Now().Add(leakSettle); ; time.Sleep(10 * time.Millisecond) {
+			leaked = leaked[:0]
+
+			for id, stack := range goroutines() {
+				if _, ok := before[id]; !ok {
+					leaked = append(leaked, stack)
+				}
+			}
+
+			if len(leaked) == 0 || time.Now().After(deadline) {
+				break
+			}
+		}
+
+		if len(leaked) > 0 {
+			ctx.As.Failf("goroutines leaked", "%s: %d goroutines are still running after the case finished:\n\n%s",
+				ctx.name(), len(leaked), strings.Join(leaked, "\n\n"))
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package mesa_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/a20r/mesa"
+)
+
+func TestDetectGoroutineLeaks(t *testing.T) {
+	m := mesa.FunctionMesa[int, mesa.Empty]{
+		DetectGoroutineLeaks: true,
+		Target: func(ctx *mesa.Ctx, n int) mesa.Empty {
+			for i := 0; i < n; i++ {
+				go func() {
+					<-ctx.Done()
+				}()
+			}
+
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				time.Sleep(10 * time.Millisecond)
+			}()
+
+			return nil
+		},
+		Cases: []mesa.FunctionCase[int, mesa.Empty]{
+			{Name: "Workers stop with the context", Input: 5},
+		},
+	}
+
+	m.Run(t)
+}
+
+func TestDetectGoroutineLeaks_Leak(t *testing.T) {
+	expectFailure(t, func(t *testing.T) {
+		block := make(chan struct{})
+		t.Cleanup(func() { close(block) })
+
+		m := mesa.FunctionMesa[int, mesa.Empty]{
+			DetectGoroutineLeaks: true,
+			Target: func(ctx *mesa.Ctx, n int) mesa.Empty {
+				for i := 0; i < n; i++ {
+					go leakyWorker(block)
+				}
+
+				return nil
+			},
+			Cases: []mesa.FunctionCase[int, mesa.Empty]{
+				{Name: "Forgotten workers", Input: 2},
+			},
+		}
+
+		m.Run(t)
+	}, "TestDetectGoroutineLeaks_Leak/Forgotten_workers: 2 goroutines are still running", "mesa_test.leakyWorker")
+}
+
+// leakyWorker blocks until block is closed.
+func leakyWorker(block chan struct{}) {
+	<-block
+}
@@ -0,0 +1,133 @@
+package mesa
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+)
+
+// LogRecord is a structured log record captured by a LogCapture.
+type LogRecord struct {
+	Level   slog.Level
+	Message string
+
+	// Attrs holds the attributes of the record, including those added with Logger.With. Attributes in groups are
+	// keyed by their dotted path, e.g. "request.id".
+	Attrs map[string]any
+}
+
+// LogCapture records the log records written to its handler so a Check can assert on them.
+type LogCapture struct {
+	mu      sync.Mutex
+	records []LogRecord
+}
+
+// CaptureLogs returns the log capture of the case, creating it on the first call. Inject its Handler or Logger into
+// the instance, e.g. by passing it through the fields in NewInstance, and assert on Records in Check. Code that uses
+// the log package can be wired with slog.NewLogLogger(capture.Handler(), slog.LevelInfo).
+func (c *Ctx) CaptureLogs() *LogCapture {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.logs == nil {
+		c.logs = &LogCapture{}
+	}
+
+	return c.logs
+}
+
+// Handler returns a slog.Handler that records every log record at any level.
+func (l *LogCapture) Handler() slog.Handler {
+	return &captureHandler{capture: l}
+}
+
+// Logger returns a slog.Logger that writes to the capture.
+func (l *LogCapture) Logger() *slog.Logger {
+	return slog.New(l.Handler())
+}
+
+// Records returns the records captured so far.
+func (l *LogCapture) Records() []LogRecord {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return append([]LogRecord(nil), l.records...)
+}
+
+// captureHandler is the slog.Handler of a LogCapture.
+type captureHandler struct {
+	capture *LogCapture
+	attrs   map[string]any
+	group   string
+}
+
+// Enabled implements slog.Handler.
+func (h *captureHandler) Enabled(context.Context, slog.Level) bool {
+	return true
+}
+
+// Handle implements slog.Handler.
+func (h *captureHandler) Handle(_ context.Context, r slog.Record) error {
+	attrs := make(map[string]any, len(h.attrs)+r.NumAttrs())
+	for k, v := range h.attrs {
+		attrs[k] = v
+	}
+
+	r.Attrs(func(a slog.Attr) bool {
+		addAttr(attrs, h.group, a)
+		return true
+	})
+
+	h.capture.mu.Lock()
+	defer h.capture.mu.Unlock()
+
+	h.capture.records = append(h.capture.records, LogRecord{Level: r.Level, Message: r.Message, Attrs: attrs})
+
+	return nil
+}
+
+// WithAttrs implements slog.Handler.
+func (h *captureHandler) WithAttrs(as []slog.Attr) slog.Handler {
+	attrs := make(map[string]any, len(h.attrs)+len(as))
+	for k, v := range h.attrs {
+		attrs[k] = v
+	}
+
+	for _, a := range as {
+		addAttr(attrs, h.group, a)
+	}
+
+	return &captureHandler{capture: h.capture, attrs: attrs, group: h.group}
+}
+
+// WithGroup implements slog.Handler.
+func (h *captureHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	return &captureHandler{capture: h.capture, attrs: h.attrs, group: h.group + name + "."}
+}
+
+// addAttr adds the attribute to attrs under the given group prefix, flattening nested groups.
+func addAttr(attrs map[string]any, prefix string, a slog.Attr) {
+	v := a.Value.Resolve()
+
+	if v.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			prefix += a.Key + "."
+		}
+
+		for _, ga := range v.Group() {
+			addAttr(attrs, prefix, ga)
+		}
+
+		return
+	}
+
+	if a.Key == "" {
+		return
+	}
+
+	attrs[prefix+a.Key] = v.Any()
+}
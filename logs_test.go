@@ -0,0 +1,58 @@
+package mesa_test
+
+import (
+	"log/slog"
+	"testing"
+
+	"github.com/a20r/mesa"
+)
+
+type greeter struct {
+	log *slog.Logger
+}
+
+func (g *greeter) Greet(name string) string {
+	if name == "" {
+		g.log.Warn("empty name", "fallback", "world")
+		name = "world"
+	}
+
+	g.log.WithGroup("request").With("name", name).Info("greeted")
+
+	return "hello " + name
+}
+
+func TestCtx_CaptureLogs(t *testing.T) {
+	m := mesa.MethodMesa[*greeter, mesa.Empty, string, string]{
+		NewInstance: func(ctx *mesa.Ctx, _ mesa.Empty) *greeter {
+			return &greeter{log: ctx.CaptureLogs().Logger()}
+		},
+		Target: func(ctx *mesa.Ctx, inst *greeter, name string) string {
+			return inst.Greet(name)
+		},
+		Cases: []mesa.MethodCase[*greeter, mesa.Empty, string, string]{
+			{
+				Name:     "Named",
+				Input:    "gopher",
+				Expected: "hello gopher",
+				Check: func(ctx *mesa.Ctx, _ *greeter, _ string, _ string) {
+					ctx.As.Equal([]mesa.LogRecord{
+						{Level: slog.LevelInfo, Message: "greeted", Attrs: map[string]any{"request.name": "gopher"}},
+					}, ctx.CaptureLogs().Records())
+				},
+			},
+			{
+				Name:     "Empty",
+				Expected: "hello world",
+				Check: func(ctx *mesa.Ctx, _ *greeter, _ string, _ string) {
+					records := ctx.CaptureLogs().Records()
+					ctx.Re.Len(records, 2)
+					ctx.As.Equal(slog.LevelWarn, records[0].Level)
+					ctx.As.Equal("world", records[0].Attrs["fallback"])
+				},
+			},
+		},
+	}
+
+	m.Run(t)
+}
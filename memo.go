@@ -0,0 +1,47 @@
+package mesa
+
+import "sync"
+
+// memo caches values computed by the cases of a suite by key.
+type memo struct {
+	mu      sync.Mutex
+	entries map[string]*memoEntry
+}
+
+// memoEntry is the value cached under a key of a memo. Its lock is held while the value is computed, so that
+// concurrent calls for the key wait for it without blocking the other keys.
+type memoEntry struct {
+	mu    sync.Mutex
+	done  bool
+	value any
+}
+
+// get returns the value cached under key, calling compute to cache it first if there is none. Concurrent calls for
+// the same key wait for the first one, so compute is called once per key unless it stops the case. The lock of the
+// memo is only held to look up the entry of the key, so compute may call get for other keys.
+func (m *memo) get(key string, compute func() any) any {
+	m.mu.Lock()
+
+	if m.entries == nil {
+		m.entries = make(map[string]*memoEntry)
+	}
+
+	e, ok := m.entries[key]
+	if !ok {
+		e = &memoEntry{}
+		m.entries[key] = e
+	}
+
+	m.mu.Unlock()
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	// A compute that stops the case leaves the entry undone, so the next call computes it again.
+	if !e.done {
+		e.value = compute()
+		e.done = true
+	}
+
+	return e.value
+}
@@ -0,0 +1,106 @@
+package mesa_test
+
+import (
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/a20r/mesa"
+)
+
+func TestMemo_SameKey(t *testing.T) {
+	var (
+		m     mesa.Memo
+		calls int
+		wg    sync.WaitGroup
+	)
+
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			v := m.Get("users", func() any {
+				calls++
+				time.Sleep(10 * time.Millisecond)
+				return "users"
+			})
+			assert.Equal(t, "users", v)
+		}()
+	}
+
+	wg.Wait()
+
+	assert.Equal(t, 1, calls)
+}
+
+func TestMemo_Nested(t *testing.T) {
+	var m mesa.Memo
+
+	done := make(chan any)
+
+	go func() {
+		done <- m.Get("orders", func() any {
+			return m.Get("users", func() any { return "users" }).(string) + "+orders"
+		})
+	}()
+
+	select {
+	case v := <-done:
+		assert.Equal(t, "users+orders", v)
+	case <-time.After(5 * time.Second):
+		t.Fatal("computing a key blocked the keys it depends on")
+	}
+}
+
+func TestMemo_ConcurrentKeys(t *testing.T) {
+	var m mesa.Memo
+
+	fastBuilt := make(chan struct{})
+	done := make(chan any)
+
+	go func() {
+		done <- m.Get("slow", func() any {
+			<-fastBuilt
+			return "slow"
+		})
+	}()
+
+	// The fast key is computed while the slow one is still being computed.
+	time.Sleep(10 * time.Millisecond)
+
+	go func() {
+		m.Get("fast", func() any { return "fast" })
+		close(fastBuilt)
+	}()
+
+	select {
+	case v := <-done:
+		assert.Equal(t, "slow", v)
+	case <-time.After(5 * time.Second):
+		t.Fatal("computing a key blocked the other keys")
+	}
+}
+
+func TestMemo_Stopped(t *testing.T) {
+	var m mesa.Memo
+
+	stopped := make(chan struct{})
+
+	go func() {
+		defer close(stopped)
+
+		m.Get("users", func() any {
+			runtime.Goexit()
+			return nil
+		})
+	}()
+
+	<-stopped
+
+	assert.Equal(t, "users", m.Get("users", func() any { return "users" }), "a stopped compute is called again")
+}
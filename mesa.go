@@ -3,8 +3,20 @@ package mesa
 
 import (
 	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"sort"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/google/go-cmp/cmp"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -42,15 +54,64 @@ func NewErrorPair[T any](value T, err error) ErrorPair[T] {
 	return ErrorPair[T]{Value: value, Err: err}
 }
 
+// OkPair is a convenience type used to wrap function outputs that return a value and whether it is valid, following
+// the comma-ok idiom of map lookups, type assertions and cache gets
+type OkPair[T any] struct {
+	Value T
+	Ok    bool
+}
+
+// NewOkPair creates a new ok pair with the provided value and ok
+func NewOkPair[T any](value T, ok bool) OkPair[T] {
+	return OkPair[T]{Value: value, Ok: ok}
+}
+
 // Ctx represents the test context containing the testing.T instance
 // and assertion objects for convenience.
 type Ctx struct {
 	context.Context
 	t       require.TestingT
 	values  map[string]any
-	metrics map[string]float64
+	mu      sync.Mutex
+	metrics metrics
+	opts    options
 	As      *assert.Assertions
 	Re      *require.Assertions
+
+	drainTimeout time.Duration
+	parallel     bool
+	logs         *LogCapture
+	shared       *sync.Map
+	assertions   atomic.Int64
+	deps         map[any]any
+	suite        *Ctx
+	fields       memo
+	instances    memo
+	tempDir      string
+	xfail        *xfailT
+	seed         int64
+	rand         *rand.Rand
+	caseName     string
+	caseID       string
+	failures     *atomic.Int64
+	now          *time.Time
+	outputs      outputs
+	loop         *cleanups
+	cleanupOn    *Ctx
+
+	groupCleanups []func()
+}
+
+// options holds the suite settings that are shared by the contexts of every case.
+type options struct {
+	diffMode   DiffMode
+	cmpOptions []cmp.Option
+	fixtureDir string
+
+	traceLifecycle    bool
+	assertionMode     AssertionMode
+	messagePrefix     string
+	requireAssertions bool
 }
 
 // T returns the underlying testing.T instance if it is being used tests. The test will fail if the Ctx is being
@@ -70,13 +131,10 @@ func (c *Ctx) B() *testing.B {
 }
 
 // ReportMetric adds the benchmarking metric with the given name to the context. The metrics are divided by the number
-// of calls (b.N) once the benchmark is complete. It will panic if the context is being used for tests
+// of calls (b.N) once the benchmark is complete. It will panic if the context is being used for tests. It is safe to
+// call from parallel benchmarks, in which case the timer is not stopped while the metric is recorded.
 func (c *Ctx) ReportMetric(value float64, name string) {
-	b := c.B()
-	b.StopTimer()
-	defer b.StartTimer()
-
-	c.metrics[name] += value
+	c.ReportMetricWith(value, name, "", AggPerOp)
 }
 
 // SetValue sets a value with the given name in the context
@@ -89,23 +147,73 @@ func (c *Ctx) GetValue(name string) any {
 	return c.values[name]
 }
 
+// Shared returns a store shared by the suite and all of its cases, unlike SetValue and GetValue which are specific to
+// a case. It is safe for concurrent use, so cases can accumulate results for Teardown to assert on even when they run
+// in parallel.
+func (c *Ctx) Shared() *sync.Map {
+	return c.shared
+}
+
+// name returns the name of the test or benchmark the context belongs to.
+func (c *Ctx) name() string {
+	if n, ok := c.t.(interface{ Name() string }); ok {
+		return n.Name()
+	}
+
+	return ""
+}
+
 // newCtx creates a new testing context with assert and require instances.
 func newCtx(t require.TestingT) *Ctx {
-	return &Ctx{
+	ctx := &Ctx{
 		Context: context.Background(),
 		t:       t,
 		values:  make(map[string]any),
-		metrics: make(map[string]float64),
+		metrics: metrics{byName: make(map[string]*metric)},
 		As:      assert.New(t),
 		Re:      require.New(t),
+		shared:  &sync.Map{},
 	}
+
+	// Cancel the context once the test or benchmark finishes, after its Cleanup functions, so that goroutines started
+	// by the target and tied to the context don't leak.
+	ctx.WithCancel()
+
+	// Plumb the deadline of the test binary, set with -timeout, into the context so that targets and hooks can
+	// observe it.
+	if dt, ok := t.(interface{ Deadline() (time.Time, bool) }); ok {
+		if d, ok := dt.Deadline(); ok {
+			ctx.WithDeadline(d)
+		}
+	}
+
+	return ctx
+}
+
+// CaseMeta describes a case to the selection functions of a suite, such as Filter.
+type CaseMeta struct {
+	// Name of the case.
+	Name string
+
+	// Labels of the case merged with the labels of the suite.
+	Labels map[string]string
+
+	// Priority of the case.
+	Priority int
 }
 
 // MethodCase represents a test case with its associated properties.
 type MethodCase[InstanceType, FieldsType, InputType, OutputType any] struct {
-	// [Required] Name of the test case.
+	// [Optional] Name of the test case. When it is empty and there is no NameFn, the name is derived from the Input
+	// field, or DefaultInput when the case gets it, with %v, truncated and with spaces and slashes replaced, and the
+	// index of the case is appended if another case already has that name. Explicit names are still recommended since
+	// they say what the case is about.
 	Name string
 
+	// [Optional] ID is returned by ctx.CaseID instead of the hash of the names of the suite and the case, so that
+	// external tools keep correlating the results of the case when it is renamed.
+	ID string
+
 	// [Optional] Fields associated with the instance. FieldsFn takes priority over Fields. If fields are not needed
 	// to instantiate a the test instance, no fields need to be provided.
 	Fields FieldsType
@@ -114,6 +222,13 @@ type MethodCase[InstanceType, FieldsType, InputType, OutputType any] struct {
 	// not needed to instantiate a the test instance, no fields need to be provided.
 	FieldsFn func(ctx *Ctx) FieldsType
 
+	// [Optional] CacheKey memoizes the result of FieldsFn across the cases of the suite that share the key, so that
+	// expensive fields are only built once per run of the suite. The cached fields are shared by these cases, so they
+	// must be immutable or safe to share. They outlive the case that builds them: like the instances of GroupByFields,
+	// FieldsFn gets a ctx whose failures are reported on that case, while its embedded context and the cleanups it
+	// registers, e.g. with TempDir or StartServer, belong to the suite.
+	CacheKey string
+
 	// [Optional] Input data for the test case. InputFn takes priority over Input. The Input field can be empty if the
 	// target function does not take any arguments.
 	Input InputType
@@ -122,9 +237,40 @@ type MethodCase[InstanceType, FieldsType, InputType, OutputType any] struct {
 	// be empty if the target function does not take any arguments.
 	InputFn func(ctx *Ctx, inst InstanceType) InputType
 
+	// [Optional] ModifyInput derives the input of the case from the input resolved from InputFn, Input or the
+	// defaults of the suite, e.g. to tweak a few fields of a large shared DefaultInput. It must return a modified copy
+	// rather than modify maps or pointers of the base in place, since they are shared with the other cases.
+	ModifyInput func(base InputType) InputType
+
+	// [Optional] Expected output of the target function. When it is not the zero value, the output is asserted to be
+	// equal to it before Check is called. A zero Expected can't be told apart from an omitted one and is not compared,
+	// so use ExpectedFn or Check to assert zero outputs, e.g. 0, "" or a nil slice.
+	Expected OutputType
+
+	// [Optional] ExpectedFn computes the expected output from the input, e.g. with a reference implementation, and
+	// takes priority over Expected. It is called before the target function and its result is always compared to the
+	// output, using the DiffMode of the suite. It receives a copy made with the CloneInput of the suite if provided,
+	// otherwise the input of the target itself, so it must not mutate slices, maps or pointers of the input, e.g. by
+	// sorting a slice in place.
+	ExpectedFn func(ctx *Ctx, in InputType) OutputType
+
+	// [Optional] Normalize rewrites the output of the target before it is compared to Expected and passed to Check,
+	// e.g. to redact timestamps or sort slices. Snapshots and golden files taken in Check see the normalized output.
+	// It takes priority over the Normalize function of the suite.
+	Normalize func(out OutputType) OutputType
+
 	// [Optional] Reason to skip the test case. The test is only skipped if this field is not empty
 	Skip string
 
+	// [Optional] XFail marks the case as known to fail, with the reason as its value. The case is skipped when it
+	// fails, and fails with a reminder to remove XFail when it passes. Only failures reported through the context,
+	// such as ctx.As, ctx.Re and ctx.Errorf, and panics count.
+	XFail string
+
+	// [Optional] RequireEnv lists environment variables that must be set for the case to run, e.g. for integration
+	// tests that need external services. The case is skipped if any of them is empty.
+	RequireEnv []string
+
 	// [Optional] Function to execute before calling the target function. It will be called instead of the BeforeCall
 	// function in the MethodMesa if provided.
 	BeforeCall func(ctx *Ctx, inst InstanceType, in InputType)
@@ -136,23 +282,165 @@ type MethodCase[InstanceType, FieldsType, InputType, OutputType any] struct {
 	// [Optional] Cleanup function to execute after the test case finishes. It will be called instead of the Cleanup
 	// function in the MethodMesa if provided.
 	Cleanup func(ctx *Ctx, inst InstanceType)
+
+	// [Optional] Cancel is called in a goroutine right before the target function is called, after BeforeCall and
+	// ExpectedFn. The target is called with a cancelable ctx.Context and Cancel receives its cancel function so
+	// cancellation can be triggered mid-call. Cancel runs concurrently with the target, so it may cancel before the
+	// target starts unless it waits for a signal from the target, e.g. a value sent on a channel.
+	Cancel func(ctx *Ctx, cancel context.CancelFunc)
+
+	// [Optional] Timeout cancels ctx.Context once the case has run for this long, so targets that observe the context
+	// are bounded. It takes priority over the DefaultTimeout of the suite, which is used when it is zero. A negative
+	// Timeout disables the DefaultTimeout for the case.
+	Timeout time.Duration
+
+	// [Optional] DrainTimeout bounds how long Drain waits for a channel output to be closed. The case fails if the
+	// channel is still open after the timeout. Drain waits forever when it is zero.
+	DrainTimeout time.Duration
+
+	// [Optional] Labels are logged at the start of the case as key=value pairs. They take priority over the labels
+	// of the suite on key conflicts.
+	Labels map[string]string
+
+	// [Optional] ExpectErrMsg asserts that the output, which must be an ErrorPair, has an error whose message is
+	// exactly this string.
+	ExpectErrMsg string
+
+	// [Optional] ExpectErrRegex asserts that the output, which must be an ErrorPair, has an error whose message
+	// matches this regular expression. The suite fails before running any case if it does not compile.
+	ExpectErrRegex string
+
+	// [Optional] ExpectedRegex asserts that the output, which must be a string, a byte slice or a fmt.Stringer,
+	// matches this regular expression. It is for outputs that can't be compared exactly, such as ones with
+	// timestamps or generated IDs. The suite fails before running any case if it does not compile.
+	ExpectedRegex string
+
+	// [Optional] ExpectNoErr asserts that the output, which must be an ErrorPair, has no error. The case stops
+	// otherwise.
+	ExpectNoErr bool
+
+	// [Optional] ExpectedValue asserts that the Value of the output, which must be an ErrorPair, is equal to it when it
+	// is not nil. Combined with ExpectNoErr, this asserts the success path of functions returning a value and an
+	// error without a Check.
+	ExpectedValue any
+
+	// [Optional] MaxAllocs fails the case if the target makes more heap allocations than this, which catches
+	// allocation regressions without a benchmark. The target is called 5 more times after the first call, with the
+	// same instance and input, and the smallest count is used to filter out noise, so it must be repeatable. The
+	// output of the first call is the one that is checked. Allocations are not checked when it is zero. It can't be
+	// combined with Concurrency since the goroutines that make the concurrent calls allocate too.
+	MaxAllocs uint64
+
+	// [Optional] ExpectNoAllocs fails the case if the target makes any heap allocation. It is measured like MaxAllocs
+	// and takes priority over it.
+	ExpectNoAllocs bool
+
+	// [Optional] ExpectPanic fails the case if the target does not panic. The panic is recovered, the target is
+	// called once regardless of Concurrency, and the zero output is compared and passed to Check. It can't be combined
+	// with MaxAllocs or ExpectNoAllocs since a panicking call can't be measured.
+	ExpectPanic bool
+
+	// [Optional] ExpectPanicFrom asserts that the target panics, like ExpectPanic, and that the panic originated from
+	// a function whose name contains this string, e.g. "config.validate" or "(*Parser).expect". Frames of the
+	// runtime, such as those of a nil dereference, are skipped, so the panic is attributed to the function that
+	// dereferenced nil. This tells an intended panic apart from an unrelated one. The stack is logged on failure.
+	ExpectPanicFrom string
+
+	// [Optional] Tolerance makes the Expected output of a float target, including named float types, be compared with
+	// InDelta using this delta instead of exact equality. The output is compared even when Expected is zero. It is
+	// ignored for other outputs, such as an ErrorPair of a float, which Validate reports.
+	Tolerance float64
+
+	// [Optional] TimeTolerance makes the Expected output of a time.Time target be compared with WithinDuration using
+	// this delta instead of exact equality, which ignores monotonic clock readings, locations and sub-delta
+	// differences in precision. The output is compared even when Expected is zero. It is ignored for other outputs,
+	// which Validate reports.
+	TimeTolerance time.Duration
+
+	// [Optional] IgnoreOrder makes the Expected output, which must be a slice or an array, be compared to the output
+	// with ElementsMatch, e.g. for outputs built by iterating over a map. Duplicates must appear the same number of
+	// times on both sides. The output is compared even when Expected is nil, and nil and empty slices are equal.
+	IgnoreOrder bool
+
+	// [Optional] ExpectedType asserts that the output has the same concrete type as this value, e.g. (*File)(nil) for
+	// targets that return an interface.
+	ExpectedType any
+
+	// [Optional] SameAs asserts that the output is equal to the output of the case with this name, e.g. for inputs of
+	// the same equivalence class. The output of that case is recorded when it runs, so it must run before this one and
+	// the case fails otherwise. It is compared after Normalize.
+	SameAs string
+
+	// [Optional] ContinueOnRequireFailure makes the failing assertions of ctx.Re, including those of Must1, record the
+	// failure and continue like ctx.As, as AssertionMode SoftOnly does for the whole suite. The rest of Check and any
+	// teardown that isn't registered with t.Cleanup still run after a failure, so they must handle the values the
+	// assertion would have guarded, e.g. a nil pointer after ctx.Re.NoError. A failure to create the instance still
+	// stops the case.
+	ContinueOnRequireFailure bool
+
+	// [Optional] Repeat runs the case this many times as subtests named repeat-i, each with a fresh Ctx and instance.
+	// The case fails if any repeat fails, which helps surface nondeterminism, especially with -race.
+	Repeat int
+
+	// [Optional] Priority of the case, e.g. for ordering cases with the SortCases function of the suite.
+	Priority int
+
+	// [Optional] NameFn computes the name of the subtest from the input, e.g. "Add(1,2)". It takes priority over
+	// Name and the NameFn of the MethodMesa. It receives the Input field, or DefaultInput when the case gets it, since
+	// InputFn and DefaultInputFn are resolved inside the subtest. Spaces and slashes in the result are replaced with
+	// underscores to keep -run filters usable.
+	NameFn func(ctx *Ctx, in InputType) string
+
+	// [Optional] AssertImmutable asserts that the target function does not mutate the instance, e.g. for getter
+	// methods. The instance is copied with CloneInstance of the MethodMesa if provided, otherwise its deep state is
+	// recorded before the call.
+	AssertImmutable bool
+
+	// [Optional] AssertInputUnchanged asserts that the target function does not mutate the input, e.g. by sorting a
+	// slice in place that is shared with other cases. The input is copied with CloneInput of the MethodMesa if
+	// provided, otherwise its deep state is recorded before the call. The diff shows which field of the input changed.
+	AssertInputUnchanged bool
+
+	// [Optional] Concurrency calls the target function from this many goroutines at once against the shared instance
+	// when greater than one, and waits for all of them to finish before the output is checked. Check receives the
+	// output of the first goroutine and is expected to assert the final state of the instance. This is meant to be run
+	// with -race to detect data races in the methods of the instance. The target should only use ctx.As since
+	// ctx.Re cannot stop the test from other goroutines. It can't be combined with MaxAllocs or ExpectNoAllocs.
+	Concurrency int
 }
 
 // MethodMesa represents a collection of test cases and the functions to create instances
 // and execute the target function under test.
 type MethodMesa[InstanceType, FieldsType, InputType, OutputType any] struct {
-	// [Optional] Function to initialize anything before running the test cases
+	// [Optional] Function to initialize anything before running the test cases. If it fails, e.g. with ctx.Re.NoError
+	// or ctx.As, no case runs. Teardown is still called, so it must handle a partial initialization.
 	Init func(ctx *Ctx)
 
-	// [Required] Function to create a new instance.
+	// [Required] Function to create a new instance. It is not needed when NewInstanceErr is provided.
 	NewInstance func(ctx *Ctx, fields FieldsType) InstanceType
 
-	// [Required] Target function under test.
+	// [Optional] Function to create a new instance when construction can fail, e.g. because it opens network
+	// connections or files. It takes priority over NewInstance and the case fails if it returns an error. Cleanup is
+	// still called with the returned, possibly partially constructed, instance.
+	NewInstanceErr func(ctx *Ctx, fields FieldsType) (InstanceType, error)
+
+	// [Optional] Target function under test. When it is nil, e.g. because BeforeCall does all the work, no target is
+	// called and Check receives the zero value of OutputType (nil for Empty).
 	Target func(ctx *Ctx, inst InstanceType, in InputType) OutputType
 
 	// [Required] List of test cases.
 	Cases []MethodCase[InstanceType, FieldsType, InputType, OutputType]
 
+	// [Optional] DefaultInput is the input of the cases that provide neither Input nor InputFn. The input of a case is
+	// resolved in order from its InputFn, its Input, DefaultInputFn and DefaultInput, then passed to its ModifyInput.
+	// An Input that is the zero value can't be told apart from an omitted one, so such a case gets the default too; use
+	// an InputFn that returns the zero value to run a case with it.
+	DefaultInput InputType
+
+	// [Optional] DefaultInputFn returns the input of the cases that provide neither Input nor InputFn. It takes
+	// priority over DefaultInput, and it is also used for the cases whose Input is the zero value.
+	DefaultInputFn func(ctx *Ctx, inst InstanceType) InputType
+
 	// [Optional] Function to execute before calling the target function. This is called when no BeforeCall function
 	// is provided by the the case itself.
 	BeforeCall func(ctx *Ctx, inst InstanceType, in InputType)
@@ -167,73 +455,640 @@ type MethodMesa[InstanceType, FieldsType, InputType, OutputType any] struct {
 
 	// [Optional] Teardown function is called after all cases finish
 	Teardown func(ctx *Ctx)
+
+	// [Optional] OnFailure is called with the name of the subtest when a case fails, e.g. to capture diagnostics. It
+	// runs before the Cleanup function so live resources can still be inspected.
+	OnFailure func(ctx *Ctx, name string)
+
+	// [Optional] InlineCleanup runs the Cleanup function of a case as soon as the case returns, right after Check,
+	// instead of registering it with t.Cleanup. It still runs when an assertion stops the case or the case panics, and
+	// it makes the order deterministic when cases hold resources that the next case needs. In exchange, it runs before
+	// OnFailure, so the instance may no longer be inspected there, and before the cleanups registered by the case
+	// itself, such as those of ctx.TempDir or ctx.WithCancel.
+	InlineCleanup bool
+
+	// [Optional] SaveGlobals isolates cases that touch package globals. Each function is called at the start of every
+	// case, before FieldsFn, to save some global state and returns a function that restores it. The restores are
+	// registered with t.Cleanup, so they run in reverse order even when the case fails, before the AfterCase global
+	// hooks. SaveGlobal covers plain variables. Cases that touch globals must not run in parallel.
+	SaveGlobals []func() (restore func())
+
+	// [Optional] PrintInputOnFailure logs the input of a failing case as a table of field names and values when the
+	// input is a struct. Nested structs are flattened with dotted paths.
+	PrintInputOnFailure bool
+
+	// [Optional] DiffMode controls how the Expected output of a case is compared to the actual output. Defaults to
+	// DiffTestify.
+	DiffMode DiffMode
+
+	// [Optional] CmpOptions are passed to go-cmp when DiffMode is DiffCmp, e.g. cmpopts.IgnoreUnexported for types
+	// with unexported fields.
+	CmpOptions []cmp.Option
+
+	// [Optional] AssertionMode controls whether failing assertions made through ctx.As and ctx.Re stop the case. It
+	// also applies to the ctx of Init and Teardown. Defaults to Mixed.
+	AssertionMode AssertionMode
+
+	// [Optional] MessagePrefix is prepended, along with the name of the case, to the failure messages of ctx.As and
+	// ctx.Re, e.g. to attribute failures to a suite in shared CI logs. Failures of Init and Teardown are prefixed with
+	// the name of the test instead.
+	MessagePrefix string
+
+	// [Optional] RecordRegressions writes the input of every failing case to RegressionDir/<test name>.json so it can
+	// be loaded back as a permanent case with CasesFromRegressions. Inputs must be JSON serializable.
+	RecordRegressions bool
+
+	// [Optional] RegressionDir is the directory of the files written by RecordRegressions. Defaults to
+	// testdata/regressions.
+	RegressionDir string
+
+	// [Optional] DocFormatters customize how inputs and outputs of specific types are rendered by DocExamples.
+	DocFormatters []DocFormatter
+
+	// [Optional] FixtureDir is the directory that ctx.Fixture reads files from. Defaults to testdata.
+	FixtureDir string
+
+	// [Optional] TraceLifecycle logs every hook that is called, along with the name of the case and whether the hook
+	// of the case or of the suite was chosen. This helps debug the order in which hooks run.
+	TraceLifecycle bool
+
+	// [Optional] RequireAssertions fails a case whose Check makes no assertions through ctx.As or ctx.Re, which
+	// catches Check functions that were accidentally left empty. Cases that make assertions without a Check, e.g.
+	// with an Expected output, ExpectNoErr, ExpectedRegex, MaxAllocs or ExpectPanic, are exempt.
+	RequireAssertions bool
+
+	// [Optional] Labels are logged at the start of every case as key=value pairs, e.g. for log aggregation. They
+	// don't affect whether a case passes.
+	Labels map[string]string
+
+	// [Optional] NameFn computes the name of the subtest of each case from its input. It is used when the case does
+	// not provide its own NameFn and takes priority over the Name of the case.
+	NameFn func(ctx *Ctx, in InputType) string
+
+	// [Optional] Filter decides whether each case runs. Cases for which it returns false are skipped with the reason
+	// "filtered".
+	Filter func(c CaseMeta) bool
+
+	// [Optional] SortCases reorders the cases before they run, e.g. by Priority or alphabetically. It reports whether
+	// case a must run before case b. Cases it considers equal keep their order in Cases.
+	SortCases func(a, b CaseMeta) bool
+
+	// [Optional] Shuffle runs the cases in a random order given by Seed, which catches cases that depend on each other.
+	// The seed is logged so the order can be reproduced. It can't be combined with SortCases.
+	Shuffle bool
+
+	// [Optional] Seed seeds Shuffle and ctx.Rand. A seed based on the current time is used when it is zero.
+	Seed int64
+
+	// [Optional] ReplayFile is the path of a JSON file that the seed and the order of the cases are written to before
+	// they run, so that a failing run can be reproduced with RunReplay.
+	ReplayFile string
+
+	// [Optional] Normalize rewrites the output of the target before it is compared to the Expected output of a case
+	// and passed to Check. It is used when the case does not provide its own Normalize function.
+	Normalize func(out OutputType) OutputType
+
+	// [Optional] DefaultTimeout is the Timeout of the cases that don't set their own. Cases are not bounded when it
+	// is zero.
+	DefaultTimeout time.Duration
+
+	// [Optional] FrozenTime freezes the clock of every case at this time, so that ctx.Now returns it until the case
+	// moves the clock with ctx.Advance. The clock follows time.Now() when it is zero.
+	FrozenTime time.Time
+
+	// [Optional] Progress is called after every case completes with the number of completed cases and the total
+	// number of cases selected to run, i.e. not excluded by Filter, skipped or missing a RequireEnv variable, e.g. to
+	// show a progress bar in a custom harness. It doesn't affect the results. Calls are serialized, even when cases
+	// run in parallel.
+	Progress func(done, total int)
+
+	// [Optional] SkipNearDeadline skips the remaining cases once less than this duration is left before the deadline
+	// of the test binary, set with -timeout. This leaves room for cleanup instead of the binary being killed.
+	SkipNearDeadline time.Duration
+
+	// [Optional] DetectGoroutineLeaks fails a case if goroutines it started are still running a second after it
+	// finished, including its Cleanup and the cancellation of its context. Goroutines are compared by id, so cases of
+	// the suite or of other tests running in parallel, and background goroutines started lazily by libraries, are
+	// reported as leaks too. It should only be used by suites whose cases don't run in parallel.
+	DetectGoroutineLeaks bool
+
+	// [Optional] FailOnNoCases fails the test before Init when every case would be excluded by Filter, Skip or
+	// RequireEnv, which usually means a filter is too aggressive. It is recommended for every suite. A suite without
+	// cases always fails validation, and an empty shard of RunShard is not reported.
+	FailOnNoCases bool
+
+	// [Optional] GroupByFields returns the key of the group of the fields of a case. The cases whose fields have the
+	// same key share one instance, built by the first of them instead of once per case, which pays off when building
+	// an instance is costly. Its failures are reported on that case, while the embedded context and the cleanups that
+	// NewInstance registers on ctx, e.g. with TempDir or StartServer, belong to the suite so that they outlive the case.
+	// The instance is passed to Reset before every other case of the group, and the Cleanup of the suite is called
+	// once per group after every case finished, before Teardown. The Cleanup of a case still runs after the case.
+	// Cases must not rely on the instance being fresh, and cases of a group that run in parallel must only share an
+	// instance that is safe for concurrent use.
+	GroupByFields func(fields FieldsType) string
+
+	// [Optional] Reset restores the instance shared by a group of GroupByFields to its initial state before it is
+	// reused by another case, e.g. by truncating tables. It is only used with GroupByFields.
+	Reset func(ctx *Ctx, inst InstanceType)
+
+	// [Optional] CloneInstance returns a deep copy of the instance. It is used by cases that set AssertImmutable and
+	// is only needed when the state of the instance can't be compared by walking it, e.g. it holds functions.
+	CloneInstance func(inst InstanceType) InstanceType
+
+	// [Optional] CloneInput returns a deep copy of the input. It is used by cases that set AssertInputUnchanged, where
+	// it is only needed when the state of the input can't be compared by walking it, e.g. it holds functions. ExpectedFn
+	// also receives a copy made with it, so that a reference implementation can't change the input of the target.
+	CloneInput func(in InputType) InputType
 }
 
-// Run executes all the test cases in the Mesa instance.
+// options returns the settings shared by the contexts of the suite.
+func (m MethodMesa[Inst, F, I, O]) options() options {
+	return options{
+		diffMode:   m.DiffMode,
+		cmpOptions: m.CmpOptions,
+		fixtureDir: m.FixtureDir,
+
+		traceLifecycle:    m.TraceLifecycle,
+		assertionMode:     m.AssertionMode,
+		messagePrefix:     m.MessagePrefix,
+		requireAssertions: m.RequireAssertions,
+	}
+}
+
+// Run executes all the test cases in the Mesa instance. The test fails before any case runs if Validate returns an
+// error.
 func (m MethodMesa[Inst, F, I, O]) Run(t *testing.T) {
+	if err := m.Validate(); err != nil {
+		t.Fatalf("invalid mesa: %v", err)
+	}
+
+	m.run(t)
+}
+
+// caseMeta describes the case to the selection functions of the suite.
+func (m MethodMesa[Inst, F, I, O]) caseMeta(tt MethodCase[Inst, F, I, O]) CaseMeta {
+	return CaseMeta{Name: tt.Name, Labels: mergeLabels(m.Labels, tt.Labels), Priority: tt.Priority}
+}
+
+// run executes all the test cases without validating the suite.
+func (m MethodMesa[Inst, F, I, O]) run(t *testing.T) {
 	ctx := newCtx(t)
+	ctx.opts = m.options()
+	ctx.failures = &atomic.Int64{}
+	ctx.setAssertions()
+	m.Cases = m.deriveNames()
+
+	if m.FailOnNoCases && len(m.Cases) > 0 {
+		if reason := m.noCasesReason(); reason != "" {
+			t.Fatal(reason)
+		}
+	}
+
+	// Deferred before Init so that it also runs when Init fails.
+	if m.Teardown != nil {
+		defer func() {
+			ctx.trace("Teardown")
+			m.Teardown(ctx)
+		}()
+	}
 
 	if m.Init != nil {
+		ctx.trace("Init")
+
+		// The failures of Init are counted rather than read from t, which may have failed before the suite ran. A
+		// failure that doesn't stop Init, such as one of ctx.As, still stops the suite before any case runs.
+		failed := t.Failed()
 		m.Init(ctx)
+
+		if ctx.failures.Load() > 0 || (!failed && t.Failed()) {
+			msg := "Init failed, no cases were run"
+			if m.MessagePrefix != "" {
+				msg = m.MessagePrefix + " " + t.Name() + ": " + msg
+			}
+
+			t.Fatal(msg)
+		}
 	}
 
-	if m.Teardown != nil {
-		defer m.Teardown(ctx)
+	// Deferred after Teardown so that the instances of the groups are cleaned up first.
+	defer ctx.cleanupGroups()
+
+	if m.SortCases != nil {
+		cases := append([]MethodCase[Inst, F, I, O](nil), m.Cases...)
+		sort.SliceStable(cases, func(i, j int) bool {
+			return m.SortCases(m.caseMeta(cases[i]), m.caseMeta(cases[j]))
+		})
+
+		m.Cases = cases
+	}
+
+	ctx.seed = newSeed(m.Seed)
+
+	if m.Shuffle {
+		m.Cases = shuffleCases(m.Cases, ctx.seed)
+		t.Logf("mesa: shuffled cases with seed %d", ctx.seed)
+	}
+
+	if m.ReplayFile != "" {
+		r := replay{Seed: ctx.seed, Order: make([]string, len(m.Cases))}
+		for i, c := range m.Cases {
+			r.Order[i] = c.Name
+		}
+
+		if err := writeReplay(m.ReplayFile, r); err != nil {
+			t.Fatalf("failed to write replay file %s: %v", m.ReplayFile, err)
+		}
+	}
+
+	patterns := make([]casePatterns, len(m.Cases))
+	for i, tt := range m.Cases {
+		var err error
+
+		if patterns[i].err, err = newErrExpectation(tt.ExpectErrMsg, tt.ExpectErrRegex); err != nil {
+			t.Fatalf("invalid ExpectErrRegex of case %q: %v", tt.Name, err)
+		}
+
+		if patterns[i].output, err = newOutputPattern(tt.ExpectedRegex); err != nil {
+			t.Fatalf("invalid ExpectedRegex of case %q: %v", tt.Name, err)
+		}
+	}
+
+	var prog *progress
+	if m.Progress != nil {
+		prog = &progress{fn: m.Progress}
+		for _, tt := range m.Cases {
+			if m.selected(tt) {
+				prog.total++
+			}
+		}
 	}
 
-	for _, tt := range m.Cases {
-		t.Run(tt.Name, func(t *testing.T) {
+	for i, tt := range m.Cases {
+		name := tt.Name
+
+		switch {
+		case tt.NameFn != nil:
+			name = sanitizeName(tt.NameFn(ctx, m.nameInput(tt)))
+		case m.NameFn != nil:
+			name = sanitizeName(m.NameFn(ctx, m.nameInput(tt)))
+		}
+
+		meta := m.caseMeta(tt)
+		counted := prog != nil && m.selected(tt)
+
+		t.Run(name, func(t *testing.T) {
+			if counted {
+				t.Cleanup(prog.caseDone)
+			}
+
+			if m.Filter != nil && !m.Filter(meta) {
+				t.Skip("filtered")
+			}
+
+			if m.SkipNearDeadline > 0 {
+				if d, ok := t.Deadline(); ok && time.Until(d) < m.SkipNearDeadline {
+					t.Skipf("less than %v left before the test deadline", m.SkipNearDeadline)
+				}
+			}
+
 			if tt.Skip != "" {
 				t.Skip(tt.Skip)
 			}
 
-			ctx := newCtx(t)
+			for _, env := range tt.RequireEnv {
+				if os.Getenv(env) == "" {
+					t.Skipf("requires env %s", env)
+				}
+			}
 
-			if tt.FieldsFn != nil {
-				tt.Fields = tt.FieldsFn(ctx)
+			if len(meta.Labels) > 0 {
+				t.Logf("mesa labels: %s", formatLabels(meta.Labels))
 			}
 
-			inst := m.NewInstance(ctx, tt.Fields)
+			if tt.Repeat <= 1 {
+				m.runCase(t, ctx, name, tt, patterns[i])
+				return
+			}
 
-			if tt.InputFn != nil {
-				tt.Input = tt.InputFn(ctx, inst)
+			for r := 0; r < tt.Repeat; r++ {
+				t.Run(fmt.Sprintf("repeat-%d", r), func(t *testing.T) {
+					m.runCase(t, ctx, name, tt, patterns[i])
+				})
 			}
+		})
+	}
+}
 
-			cleanup := func() {}
+// runCase runs a single case with a fresh context and instance.
+func (m MethodMesa[Inst, F, I, O]) runCase(
+	t *testing.T,
+	suite *Ctx,
+	name string,
+	tt MethodCase[Inst, F, I, O],
+	patterns casePatterns,
+) {
+	if tt.XFail != "" {
+		runXFail(t, tt.XFail, func(x *xfailT) {
+			m.runCaseWith(t, x, suite, name, tt, patterns)
+		})
 
-			switch {
-			case tt.Cleanup != nil:
-				cleanup = func() { tt.Cleanup(ctx, inst) }
-			case m.Cleanup != nil:
-				cleanup = func() { m.Cleanup(ctx, inst) }
-			}
+		return
+	}
 
-			t.Cleanup(cleanup)
+	m.runCaseWith(t, nil, suite, name, tt, patterns)
+}
 
-			switch {
-			case tt.BeforeCall != nil:
-				tt.BeforeCall(ctx, inst, tt.Input)
-			case m.BeforeCall != nil:
-				m.BeforeCall(ctx, inst, tt.Input)
+// runCaseWith runs a single case whose failures are recorded by xfail when it is not nil.
+func (m MethodMesa[Inst, F, I, O]) runCaseWith(
+	t *testing.T,
+	xfail *xfailT,
+	suite *Ctx,
+	name string,
+	tt MethodCase[Inst, F, I, O],
+	patterns casePatterns,
+) {
+	var ctx *Ctx
+
+	// Registered before the context so that the leak check runs once the context is canceled.
+	if m.DetectGoroutineLeaks {
+		checkLeaks := detectLeaks()
+		t.Cleanup(func() { checkLeaks(ctx) })
+	}
+
+	ctx = newCtx(t)
+	ctx.caseName = name
+	ctx.caseID = tt.ID
+	ctx.xfail = xfail
+	ctx.opts = m.options()
+	ctx.drainTimeout = tt.DrainTimeout
+
+	if tt.ContinueOnRequireFailure {
+		ctx.opts.assertionMode = SoftOnly
+	}
+
+	switch {
+	case tt.Timeout > 0:
+		ctx.WithTimeout(tt.Timeout)
+	case tt.Timeout == 0 && m.DefaultTimeout > 0:
+		ctx.WithTimeout(m.DefaultTimeout)
+	}
+
+	if !m.FrozenTime.IsZero() {
+		ctx.SetNow(m.FrozenTime)
+	}
+
+	ctx.setAssertions()
+	ctx.shared = suite.shared
+	ctx.suite = suite
+
+	runGlobalHooks(ctx, name)
+
+	for _, save := range m.SaveGlobals {
+		ctx.cleanup(save())
+	}
+
+	switch {
+	case tt.FieldsFn != nil && tt.CacheKey != "":
+		tt.Fields = suite.fields.get(tt.CacheKey, func() any {
+			ctx.trace("FieldsFn")
+			return tt.FieldsFn(ctx.sharedCtx(suite))
+		}).(F)
+	case tt.FieldsFn != nil:
+		ctx.trace("FieldsFn")
+		tt.Fields = tt.FieldsFn(ctx)
+	}
+
+	var (
+		inst Inst
+		err  error
+	)
+
+	if m.GroupByFields != nil {
+		inst, err = m.groupInstance(ctx, suite, tt.Fields)
+	} else {
+		inst, err = m.newInstance(ctx, tt.Fields)
+	}
+
+	cleanup := func() {}
+
+	switch {
+	case tt.Cleanup != nil:
+		cleanup = func() {
+			ctx.trace("Cleanup of the case")
+			tt.Cleanup(ctx, inst)
+		}
+	case m.Cleanup != nil && m.GroupByFields == nil:
+		cleanup = func() {
+			ctx.trace("Cleanup of the suite")
+			m.Cleanup(ctx, inst)
+		}
+	}
+
+	if m.InlineCleanup {
+		defer cleanup()
+	} else {
+		t.Cleanup(cleanup)
+	}
+
+	regressions := regressionPath(m.RegressionDir, suite.name())
+
+	// Registered after Cleanup so that it runs first.
+	t.Cleanup(func() {
+		if !t.Failed() {
+			return
+		}
+
+		if m.PrintInputOnFailure {
+			if table := formatInputTable(tt.Input); table != "" {
+				t.Logf("mesa input:\n%s", table)
 			}
+		}
 
-			out := m.Target(ctx, inst, tt.Input)
+		if m.RecordRegressions {
+			if err := recordRegression(regressions, tt.Name, tt.Input); err != nil {
+				t.Logf("mesa: failed to record regression: %v", err)
+			} else {
+				t.Logf("mesa: recorded input in %s", regressions)
+			}
+		}
 
-			switch {
-			case tt.Check != nil:
-				tt.Check(ctx, inst, tt.Input, out)
-			case m.Check != nil:
-				m.Check(ctx, inst, tt.Input, out)
+		if m.OnFailure != nil {
+			ctx.trace("OnFailure")
+			m.OnFailure(ctx, name)
+		}
+	})
+
+	// The case can't go on without an instance, even when ctx.Re doesn't stop it.
+	if err != nil {
+		ctx.Re.NoError(err, "failed to create instance")
+		ctx.reporter().FailNow()
+	}
+
+	switch {
+	case tt.InputFn != nil:
+		ctx.trace("InputFn")
+		tt.Input = tt.InputFn(ctx, inst)
+	case !isZero(tt.Input):
+	case m.DefaultInputFn != nil:
+		ctx.trace("DefaultInputFn")
+		tt.Input = m.DefaultInputFn(ctx, inst)
+	default:
+		tt.Input = m.DefaultInput
+	}
+
+	if tt.ModifyInput != nil {
+		ctx.trace("ModifyInput")
+		tt.Input = tt.ModifyInput(tt.Input)
+	}
+
+	switch {
+	case tt.BeforeCall != nil:
+		ctx.trace("BeforeCall of the case")
+		tt.BeforeCall(ctx, inst, tt.Input)
+	case m.BeforeCall != nil:
+		ctx.trace("BeforeCall of the suite")
+		m.BeforeCall(ctx, inst, tt.Input)
+	}
+
+	// Called before the snapshots so that they only cover the target, with a copy of the input when CloneInput is
+	// provided so that the target sees the input unchanged.
+	expected, hasExpected := tt.Expected, !isZero(tt.Expected)
+	if tt.ExpectedFn != nil {
+		ctx.trace("ExpectedFn")
+
+		in := tt.Input
+		if m.CloneInput != nil {
+			in = m.CloneInput(in)
+		}
+
+		expected, hasExpected = tt.ExpectedFn(ctx, in), true
+	}
+
+	assertUnchanged := func(*Ctx) {}
+	if tt.AssertImmutable {
+		assertUnchanged = snapshot(inst, m.CloneInstance, "instance was mutated by the target")
+	}
+
+	assertInputUnchanged := func(*Ctx) {}
+	if tt.AssertInputUnchanged {
+		assertInputUnchanged = snapshot(tt.Input, m.CloneInput, "input was mutated by the target")
+	}
+
+	// Started last so that a Cancel that fires right away can only interrupt the target.
+	if tt.Cancel != nil {
+		cancelCtx, cancel := context.WithCancel(ctx.Context)
+		t.Cleanup(cancel)
+
+		ctx.Context = cancelCtx
+		go tt.Cancel(ctx, cancel)
+	}
+
+	var out O
+	if m.Target != nil {
+		ctx.trace("Target")
+		// Built once so that measuring the allocations of the target doesn't count the closure.
+		target := func() O {
+			return m.Target(ctx, inst, tt.Input)
+		}
+		call := func() O {
+			return callConcurrently(tt.Concurrency, target)
+		}
+
+		switch {
+		case tt.ExpectPanic || tt.ExpectPanicFrom != "":
+			var r *recovered
+			out, r = callRecover(target)
+			checkPanic(ctx, r, tt.ExpectPanicFrom)
+		case tt.MaxAllocs > 0 || tt.ExpectNoAllocs:
+			var allocs uint64
+			out, allocs = measureAllocs(target)
+			maxAllocs := tt.MaxAllocs
+			if tt.ExpectNoAllocs {
+				maxAllocs = 0
 			}
-		})
+
+			checkAllocs(ctx, allocs, maxAllocs)
+		default:
+			out = call()
+		}
+	}
+
+	assertUnchanged(ctx)
+	assertInputUnchanged(ctx)
+
+	switch {
+	case tt.Normalize != nil:
+		ctx.trace("Normalize of the case")
+		out = tt.Normalize(out)
+	case m.Normalize != nil:
+		ctx.trace("Normalize of the suite")
+		out = m.Normalize(out)
+	}
+
+	suite.outputs.set(tt.Name, out)
+
+	switch {
+	case tt.Tolerance > 0 && isFloat[O]():
+		// InDelta only accepts the builtin float types.
+		ctx.As.InDelta(reflect.ValueOf(expected).Float(), reflect.ValueOf(out).Float(), tt.Tolerance,
+			"%s: output is not within %v of the expected output", ctx.name(), tt.Tolerance)
+	case tt.TimeTolerance > 0 && isTime[O]():
+		ctx.WithinDuration(any(expected).(time.Time), any(out).(time.Time), tt.TimeTolerance)
+	case tt.IgnoreOrder:
+		ctx.ElementsMatch(expected, out)
+	case hasExpected:
+		ctx.equal(expected, out)
+	}
+
+	if tt.ExpectedType != nil {
+		ctx.As.IsType(tt.ExpectedType, out, "%s: unexpected output type", ctx.name())
+	}
+
+	if tt.SameAs != "" {
+		checkSameAs(ctx, &suite.outputs, tt.SameAs, out)
+	}
+
+	patterns.err.check(ctx, out)
+	patterns.output.check(ctx, out)
+	checkSuccess(ctx, out, tt.ExpectNoErr, tt.ExpectedValue)
+
+	assertions := ctx.assertions.Load()
+
+	switch {
+	case tt.Check != nil:
+		ctx.trace("Check of the case")
+		tt.Check(ctx, inst, tt.Input, out)
+	case m.Check != nil:
+		ctx.trace("Check of the suite")
+		m.Check(ctx, inst, tt.Input, out)
+	default:
+		return
+	}
+
+	if m.RequireAssertions && !hasExpected && !tt.hasAutoChecks() && ctx.assertions.Load() == assertions {
+		ctx.As.Fail(ctx.name() + ": Check made no assertions")
 	}
 }
 
+// hasAutoChecks reports whether the case makes assertions on the output without a Check, other than comparing it to
+// the Expected output.
+func (tt MethodCase[Inst, F, I, O]) hasAutoChecks() bool {
+	return tt.IgnoreOrder || tt.ExpectNoErr || tt.ExpectedValue != nil || tt.ExpectedRegex != "" || tt.MaxAllocs > 0 ||
+		tt.ExpectNoAllocs || tt.ExpectPanic || tt.ExpectPanicFrom != "" || tt.SameAs != ""
+}
+
 // FunctionCase represents a test case with its associated properties.
 type FunctionCase[InputType, OutputType any] struct {
-	// [Required] Name of the test case.
+	// [Optional] Name of the test case. When it is empty and there is no NameFn, the name is derived from the Input
+	// field, or DefaultInput when the case gets it, with %v, truncated and with spaces and slashes replaced, and the
+	// index of the case is appended if another case already has that name. Explicit names are still recommended since
+	// they say what the case is about.
 	Name string
 
+	// [Optional] ID is returned by ctx.CaseID instead of the hash of the names of the suite and the case, so that
+	// external tools keep correlating the results of the case when it is renamed.
+	ID string
+
 	// [Optional] Input data for the test case. InputFn takes priority over Input. The Input field can be empty if the
 	// target function does not take any arguments.
 	Input InputType
@@ -242,9 +1097,40 @@ type FunctionCase[InputType, OutputType any] struct {
 	// be empty if the target function does not take any arguments.
 	InputFn func(ctx *Ctx) InputType
 
+	// [Optional] ModifyInput derives the input of the case from the input resolved from InputFn, Input or the
+	// defaults of the suite, e.g. to tweak a few fields of a large shared DefaultInput. It must return a modified copy
+	// rather than modify maps or pointers of the base in place, since they are shared with the other cases.
+	ModifyInput func(base InputType) InputType
+
+	// [Optional] Expected output of the target function. When it is not the zero value, the output is asserted to be
+	// equal to it before Check is called. A zero Expected can't be told apart from an omitted one and is not compared,
+	// so use ExpectedFn or Check to assert zero outputs, e.g. 0, "" or a nil slice.
+	Expected OutputType
+
+	// [Optional] ExpectedFn computes the expected output from the input, e.g. with a reference implementation, and
+	// takes priority over Expected. It is called before the target function and its result is always compared to the
+	// output, using the DiffMode of the suite. It receives a copy made with the CloneInput of the suite if provided,
+	// otherwise the input of the target itself, so it must not mutate slices, maps or pointers of the input, e.g. by
+	// sorting a slice in place.
+	ExpectedFn func(ctx *Ctx, in InputType) OutputType
+
+	// [Optional] Normalize rewrites the output of the target before it is compared to Expected and passed to Check,
+	// e.g. to redact timestamps or sort slices. Snapshots and golden files taken in Check see the normalized output.
+	// It takes priority over the Normalize function of the suite.
+	Normalize func(out OutputType) OutputType
+
 	// [Optional] Reason to skip the test case. The test is only skipped if this field is not empty
 	Skip string
 
+	// [Optional] XFail marks the case as known to fail, with the reason as its value. The case is skipped when it
+	// fails, and fails with a reminder to remove XFail when it passes. Only failures reported through the context,
+	// such as ctx.As, ctx.Re and ctx.Errorf, and panics count.
+	XFail string
+
+	// [Optional] RequireEnv lists environment variables that must be set for the case to run, e.g. for integration
+	// tests that need external services. The case is skipped if any of them is empty.
+	RequireEnv []string
+
 	// [Optional] Function to execute before calling the target function. It will be called instead of the BeforeCall
 	// function in the FunctionMesa if provided.
 	BeforeCall func(ctx *Ctx, in InputType)
@@ -256,19 +1142,143 @@ type FunctionCase[InputType, OutputType any] struct {
 	// [Optional] Cleanup function to execute after the test case finishes. It will be called instead of the Cleanup
 	// function in the FunctionMesa if provided.
 	Cleanup func(ctx *Ctx)
+
+	// [Optional] Cancel is called in a goroutine right before the target function is called, after BeforeCall and
+	// ExpectedFn. The target is called with a cancelable ctx.Context and Cancel receives its cancel function so
+	// cancellation can be triggered mid-call. Cancel runs concurrently with the target, so it may cancel before the
+	// target starts unless it waits for a signal from the target, e.g. a value sent on a channel.
+	Cancel func(ctx *Ctx, cancel context.CancelFunc)
+
+	// [Optional] Timeout cancels ctx.Context once the case has run for this long, so targets that observe the context
+	// are bounded. It takes priority over the DefaultTimeout of the suite, which is used when it is zero. A negative
+	// Timeout disables the DefaultTimeout for the case.
+	Timeout time.Duration
+
+	// [Optional] DrainTimeout bounds how long Drain waits for a channel output to be closed. The case fails if the
+	// channel is still open after the timeout. Drain waits forever when it is zero.
+	DrainTimeout time.Duration
+
+	// [Optional] Labels are logged at the start of the case as key=value pairs. They take priority over the labels
+	// of the suite on key conflicts.
+	Labels map[string]string
+
+	// [Optional] ExpectErrMsg asserts that the output, which must be an ErrorPair, has an error whose message is
+	// exactly this string.
+	ExpectErrMsg string
+
+	// [Optional] ExpectErrRegex asserts that the output, which must be an ErrorPair, has an error whose message
+	// matches this regular expression. The suite fails before running any case if it does not compile.
+	ExpectErrRegex string
+
+	// [Optional] ExpectedRegex asserts that the output, which must be a string, a byte slice or a fmt.Stringer,
+	// matches this regular expression. It is for outputs that can't be compared exactly, such as ones with
+	// timestamps or generated IDs. The suite fails before running any case if it does not compile.
+	ExpectedRegex string
+
+	// [Optional] ExpectNoErr asserts that the output, which must be an ErrorPair, has no error. The case stops
+	// otherwise.
+	ExpectNoErr bool
+
+	// [Optional] ExpectedValue asserts that the Value of the output, which must be an ErrorPair, is equal to it when it
+	// is not nil. Combined with ExpectNoErr, this asserts the success path of functions returning a value and an
+	// error without a Check.
+	ExpectedValue any
+
+	// [Optional] MaxAllocs fails the case if the target makes more heap allocations than this, which catches
+	// allocation regressions without a benchmark. The target is called 5 more times after the first call, with the
+	// same instance and input, and the smallest count is used to filter out noise, so it must be repeatable. The
+	// output of the first call is the one that is checked. Allocations are not checked when it is zero.
+	MaxAllocs uint64
+
+	// [Optional] ExpectNoAllocs fails the case if the target makes any heap allocation. It is measured like MaxAllocs
+	// and takes priority over it.
+	ExpectNoAllocs bool
+
+	// [Optional] ExpectPanic fails the case if the target does not panic. The panic is recovered, the target is
+	// called once regardless of Concurrency, and the zero output is compared and passed to Check. It can't be combined
+	// with MaxAllocs or ExpectNoAllocs since a panicking call can't be measured.
+	ExpectPanic bool
+
+	// [Optional] ExpectPanicFrom asserts that the target panics, like ExpectPanic, and that the panic originated from
+	// a function whose name contains this string, e.g. "config.validate" or "(*Parser).expect". Frames of the
+	// runtime, such as those of a nil dereference, are skipped, so the panic is attributed to the function that
+	// dereferenced nil. This tells an intended panic apart from an unrelated one. The stack is logged on failure.
+	ExpectPanicFrom string
+
+	// [Optional] Tolerance makes the Expected output of a float target, including named float types, be compared with
+	// InDelta using this delta instead of exact equality. The output is compared even when Expected is zero. It is
+	// ignored for other outputs, such as an ErrorPair of a float, which Validate reports.
+	Tolerance float64
+
+	// [Optional] TimeTolerance makes the Expected output of a time.Time target be compared with WithinDuration using
+	// this delta instead of exact equality, which ignores monotonic clock readings, locations and sub-delta
+	// differences in precision. The output is compared even when Expected is zero. It is ignored for other outputs,
+	// which Validate reports.
+	TimeTolerance time.Duration
+
+	// [Optional] IgnoreOrder makes the Expected output, which must be a slice or an array, be compared to the output
+	// with ElementsMatch, e.g. for outputs built by iterating over a map. Duplicates must appear the same number of
+	// times on both sides. The output is compared even when Expected is nil, and nil and empty slices are equal.
+	IgnoreOrder bool
+
+	// [Optional] ExpectedType asserts that the output has the same concrete type as this value, e.g. (*File)(nil) for
+	// targets that return an interface.
+	ExpectedType any
+
+	// [Optional] SameAs asserts that the output is equal to the output of the case with this name, e.g. for inputs of
+	// the same equivalence class. The output of that case is recorded when it runs, so it must run before this one and
+	// the case fails otherwise. It is compared after Normalize.
+	SameAs string
+
+	// [Optional] ContinueOnRequireFailure makes the failing assertions of ctx.Re, including those of Must1, record the
+	// failure and continue like ctx.As, as AssertionMode SoftOnly does for the whole suite. The rest of Check and any
+	// teardown that isn't registered with t.Cleanup still run after a failure, so they must handle the values the
+	// assertion would have guarded, e.g. a nil pointer after ctx.Re.NoError. A failure to create the instance still
+	// stops the case.
+	ContinueOnRequireFailure bool
+
+	// [Optional] Repeat runs the case this many times as subtests named repeat-i, each with a fresh Ctx and instance.
+	// The case fails if any repeat fails, which helps surface nondeterminism, especially with -race.
+	Repeat int
+
+	// [Optional] Priority of the case, e.g. for ordering cases with the SortCases function of the suite.
+	Priority int
+
+	// [Optional] NameFn computes the name of the subtest from the input, e.g. "Add(1,2)". It takes priority over
+	// Name and the NameFn of the FunctionMesa. It receives the Input field, or DefaultInput when the case gets it,
+	// since InputFn and DefaultInputFn are resolved inside the subtest. Spaces and slashes in the result are replaced
+	// with underscores to keep -run filters usable.
+	NameFn func(ctx *Ctx, in InputType) string
+
+	// [Optional] AssertInputUnchanged asserts that the target function does not mutate the input, e.g. by sorting a
+	// slice in place that is shared with other cases. The input is copied with CloneInput of the FunctionMesa if
+	// provided, otherwise its deep state is recorded before the call. The diff shows which field of the input changed.
+	AssertInputUnchanged bool
 }
 
 // FunctionMesa represents a collection of test cases that execute the target function under each test case.
 type FunctionMesa[InputType, OutputType any] struct {
-	// [Optional] Function to initialize anything before running the test cases
+	// [Optional] Function to initialize anything before running the test cases. If it fails, e.g. with ctx.Re.NoError
+	// or ctx.As, no case runs. Teardown is still called, so it must handle a partial initialization.
 	Init func(ctx *Ctx)
 
-	// [Required] Target function under test.
+	// [Optional] Target function under test. When it is nil, e.g. because BeforeCall does all the work, no target is
+	// called and Check receives the zero value of OutputType (nil for Empty).
 	Target func(ctx *Ctx, in InputType) OutputType
 
 	// [Required] List of test cases.
 	Cases []FunctionCase[InputType, OutputType]
 
+	// [Optional] DefaultInput is the input of the cases that provide neither Input nor InputFn. The input of a case is
+	// resolved in order from its InputFn, its Input, DefaultInputFn and DefaultInput, then passed to its ModifyInput.
+	// An Input that is the zero value can't be told apart from an omitted one, so such a case gets the default too; use
+	// an InputFn that returns the zero value to run a case with it.
+	DefaultInput InputType
+
+	// [Optional] DefaultInputFn returns the input of the cases that provide neither Input nor InputFn. It takes
+	// priority over DefaultInput, and it is also used for the cases whose Input is the zero value.
+	DefaultInputFn func(ctx *Ctx) InputType
+
 	// [Optional] Function to execute before calling the target function. This is called when no BeforeCall function
 	// is provided by the the case itself.
 	BeforeCall func(ctx *Ctx, in InputType)
@@ -283,16 +1293,182 @@ type FunctionMesa[InputType, OutputType any] struct {
 
 	// [Optional] Teardown function is called after all cases finish
 	Teardown func(ctx *Ctx)
+
+	// [Optional] OnFailure is called with the name of the subtest when a case fails, e.g. to capture diagnostics. It
+	// runs before the Cleanup function so live resources can still be inspected.
+	OnFailure func(ctx *Ctx, name string)
+
+	// [Optional] InlineCleanup runs the Cleanup function of a case as soon as the case returns, right after Check,
+	// instead of registering it with t.Cleanup. It still runs when an assertion stops the case or the case panics, and
+	// it makes the order deterministic when cases hold resources that the next case needs. In exchange, it runs before
+	// OnFailure, so the instance may no longer be inspected there, and before the cleanups registered by the case
+	// itself, such as those of ctx.TempDir or ctx.WithCancel.
+	InlineCleanup bool
+
+	// [Optional] SaveGlobals isolates cases that touch package globals. Each function is called at the start of every
+	// case, before FieldsFn, to save some global state and returns a function that restores it. The restores are
+	// registered with t.Cleanup, so they run in reverse order even when the case fails, before the AfterCase global
+	// hooks. SaveGlobal covers plain variables. Cases that touch globals must not run in parallel.
+	SaveGlobals []func() (restore func())
+
+	// [Optional] PrintInputOnFailure logs the input of a failing case as a table of field names and values when the
+	// input is a struct. Nested structs are flattened with dotted paths.
+	PrintInputOnFailure bool
+
+	// [Optional] DiffMode controls how the Expected output of a case is compared to the actual output. Defaults to
+	// DiffTestify.
+	DiffMode DiffMode
+
+	// [Optional] CmpOptions are passed to go-cmp when DiffMode is DiffCmp, e.g. cmpopts.IgnoreUnexported for types
+	// with unexported fields.
+	CmpOptions []cmp.Option
+
+	// [Optional] AssertionMode controls whether failing assertions made through ctx.As and ctx.Re stop the case. It
+	// also applies to the ctx of Init and Teardown. Defaults to Mixed.
+	AssertionMode AssertionMode
+
+	// [Optional] MessagePrefix is prepended, along with the name of the case, to the failure messages of ctx.As and
+	// ctx.Re, e.g. to attribute failures to a suite in shared CI logs. Failures of Init and Teardown are prefixed with
+	// the name of the test instead.
+	MessagePrefix string
+
+	// [Optional] RecordRegressions writes the input of every failing case to RegressionDir/<test name>.json so it can
+	// be loaded back as a permanent case with CasesFromRegressions. Inputs must be JSON serializable.
+	RecordRegressions bool
+
+	// [Optional] RegressionDir is the directory of the files written by RecordRegressions. Defaults to
+	// testdata/regressions.
+	RegressionDir string
+
+	// [Optional] DocFormatters customize how inputs and outputs of specific types are rendered by DocExamples.
+	DocFormatters []DocFormatter
+
+	// [Optional] FixtureDir is the directory that ctx.Fixture reads files from. Defaults to testdata.
+	FixtureDir string
+
+	// [Optional] TraceLifecycle logs every hook that is called, along with the name of the case and whether the hook
+	// of the case or of the suite was chosen. This helps debug the order in which hooks run.
+	TraceLifecycle bool
+
+	// [Optional] RequireAssertions fails a case whose Check makes no assertions through ctx.As or ctx.Re, which
+	// catches Check functions that were accidentally left empty. Cases that make assertions without a Check, e.g.
+	// with an Expected output, ExpectNoErr, ExpectedRegex, MaxAllocs or ExpectPanic, are exempt.
+	RequireAssertions bool
+
+	// [Optional] Labels are logged at the start of every case as key=value pairs, e.g. for log aggregation. They
+	// don't affect whether a case passes.
+	Labels map[string]string
+
+	// [Optional] NameFn computes the name of the subtest of each case from its input. It is used when the case does
+	// not provide its own NameFn and takes priority over the Name of the case.
+	NameFn func(ctx *Ctx, in InputType) string
+
+	// [Optional] Filter decides whether each case runs. Cases for which it returns false are skipped with the reason
+	// "filtered".
+	Filter func(c CaseMeta) bool
+
+	// [Optional] SortCases reorders the cases before they run, e.g. by Priority or alphabetically. It reports whether
+	// case a must run before case b. Cases it considers equal keep their order in Cases.
+	SortCases func(a, b CaseMeta) bool
+
+	// [Optional] Shuffle runs the cases in a random order given by Seed, which catches cases that depend on each other.
+	// The seed is logged so the order can be reproduced. It can't be combined with SortCases.
+	Shuffle bool
+
+	// [Optional] Seed seeds Shuffle and ctx.Rand. A seed based on the current time is used when it is zero.
+	Seed int64
+
+	// [Optional] ReplayFile is the path of a JSON file that the seed and the order of the cases are written to before
+	// they run, so that a failing run can be reproduced with RunReplay.
+	ReplayFile string
+
+	// [Optional] Normalize rewrites the output of the target before it is compared to the Expected output of a case
+	// and passed to Check. It is used when the case does not provide its own Normalize function.
+	Normalize func(out OutputType) OutputType
+
+	// [Optional] DefaultTimeout is the Timeout of the cases that don't set their own. Cases are not bounded when it
+	// is zero.
+	DefaultTimeout time.Duration
+
+	// [Optional] FrozenTime freezes the clock of every case at this time, so that ctx.Now returns it until the case
+	// moves the clock with ctx.Advance. The clock follows time.Now() when it is zero.
+	FrozenTime time.Time
+
+	// [Optional] Progress is called after every case completes with the number of completed cases and the total
+	// number of cases selected to run, i.e. not excluded by Filter, skipped or missing a RequireEnv variable, e.g. to
+	// show a progress bar in a custom harness. It doesn't affect the results. Calls are serialized, even when cases
+	// run in parallel.
+	Progress func(done, total int)
+
+	// [Optional] SkipNearDeadline skips the remaining cases once less than this duration is left before the deadline
+	// of the test binary, set with -timeout. This leaves room for cleanup instead of the binary being killed.
+	SkipNearDeadline time.Duration
+
+	// [Optional] DetectGoroutineLeaks fails a case if goroutines it started are still running a second after it
+	// finished, including its Cleanup and the cancellation of its context. Goroutines are compared by id, so cases of
+	// the suite or of other tests running in parallel, and background goroutines started lazily by libraries, are
+	// reported as leaks too. It should only be used by suites whose cases don't run in parallel.
+	DetectGoroutineLeaks bool
+
+	// [Optional] FailOnNoCases fails the test before Init when every case would be excluded by Filter, Skip or
+	// RequireEnv, which usually means a filter is too aggressive. It is recommended for every suite. A suite without
+	// cases always fails validation, and an empty shard of RunShard is not reported.
+	FailOnNoCases bool
+
+	// [Optional] CloneInput returns a deep copy of the input. It is used by cases that set AssertInputUnchanged, where
+	// it is only needed when the state of the input can't be compared by walking it, e.g. it holds functions. ExpectedFn
+	// also receives a copy made with it, so that a reference implementation can't change the input of the target.
+	CloneInput func(in InputType) InputType
 }
 
-// Run executes all the test cases in the FunctionMesa instance.
+// Run executes all the test cases in the FunctionMesa instance. The test fails before any case runs if Validate returns
+// an error.
 func (m FunctionMesa[I, O]) Run(t *testing.T) {
+	if err := m.Validate(); err != nil {
+		t.Fatalf("invalid mesa: %v", err)
+	}
+
+	m.method().run(t)
+}
+
+// method lowers the suite to a MethodMesa without an instance.
+func (m FunctionMesa[I, O]) method() MethodMesa[any, any, I, O] {
 	im := MethodMesa[any, any, I, O]{
 		NewInstance: func(_ *Ctx, _ any) any {
 			return nil
 		},
 
-		Cases: make([]MethodCase[any, any, I, O], len(m.Cases)),
+		Cases:      make([]MethodCase[any, any, I, O], len(m.Cases)),
+		DiffMode:   m.DiffMode,
+		CmpOptions: m.CmpOptions,
+		Labels:     m.Labels,
+		NameFn:     m.NameFn,
+		Filter:     m.Filter,
+		SortCases:  m.SortCases,
+		Shuffle:    m.Shuffle,
+		Seed:       m.Seed,
+		ReplayFile: m.ReplayFile,
+		OnFailure:  m.OnFailure,
+
+		SkipNearDeadline:     m.SkipNearDeadline,
+		DefaultTimeout:       m.DefaultTimeout,
+		FrozenTime:           m.FrozenTime,
+		Normalize:            m.Normalize,
+		FailOnNoCases:        m.FailOnNoCases,
+		InlineCleanup:        m.InlineCleanup,
+		SaveGlobals:          m.SaveGlobals,
+		Progress:             m.Progress,
+		DetectGoroutineLeaks: m.DetectGoroutineLeaks,
+		AssertionMode:        m.AssertionMode,
+		MessagePrefix:        m.MessagePrefix,
+		RecordRegressions:    m.RecordRegressions,
+		RegressionDir:        m.RegressionDir,
+		FixtureDir:           m.FixtureDir,
+		TraceLifecycle:       m.TraceLifecycle,
+		RequireAssertions:    m.RequireAssertions,
+		DefaultInput:         m.DefaultInput,
+		PrintInputOnFailure:  m.PrintInputOnFailure,
+		CloneInput:           m.CloneInput,
 	}
 
 	checkAndSet(&im.Init, m.Init != nil, func(ctx *Ctx) {
@@ -303,6 +1479,10 @@ func (m FunctionMesa[I, O]) Run(t *testing.T) {
 		return m.Target(ctx, in)
 	})
 
+	checkAndSet(&im.DefaultInputFn, m.DefaultInputFn != nil, func(ctx *Ctx, _ any) I {
+		return m.DefaultInputFn(ctx)
+	})
+
 	checkAndSet(&im.BeforeCall, m.BeforeCall != nil, func(ctx *Ctx, _ any, in I) {
 		m.BeforeCall(ctx, in)
 	})
@@ -322,14 +1502,47 @@ func (m FunctionMesa[I, O]) Run(t *testing.T) {
 	for i, c := range m.Cases {
 		c := c
 		im.Cases[i] = MethodCase[any, any, I, O]{
-			Name:  c.Name,
-			Input: c.Input,
-			Skip:  c.Skip,
-			InputFn: func(ctx *Ctx, inst any) I {
-				return c.InputFn(ctx)
-			},
+			Name:         c.Name,
+			ID:           c.ID,
+			Input:        c.Input,
+			Expected:     c.Expected,
+			ExpectedFn:   c.ExpectedFn,
+			Normalize:    c.Normalize,
+			Skip:         c.Skip,
+			XFail:        c.XFail,
+			RequireEnv:   c.RequireEnv,
+			Cancel:       c.Cancel,
+			DrainTimeout: c.DrainTimeout,
+			Timeout:      c.Timeout,
+			Labels:       c.Labels,
+			NameFn:       c.NameFn,
+			ModifyInput:  c.ModifyInput,
+
+			ExpectErrMsg:    c.ExpectErrMsg,
+			ExpectErrRegex:  c.ExpectErrRegex,
+			ExpectedRegex:   c.ExpectedRegex,
+			ExpectNoErr:     c.ExpectNoErr,
+			ExpectedValue:   c.ExpectedValue,
+			MaxAllocs:       c.MaxAllocs,
+			ExpectNoAllocs:  c.ExpectNoAllocs,
+			ExpectPanic:     c.ExpectPanic,
+			ExpectPanicFrom: c.ExpectPanicFrom,
+			Tolerance:       c.Tolerance,
+			TimeTolerance:   c.TimeTolerance,
+			IgnoreOrder:     c.IgnoreOrder,
+			ExpectedType:    c.ExpectedType,
+			SameAs:          c.SameAs,
+			Repeat:          c.Repeat,
+			Priority:        c.Priority,
+
+			AssertInputUnchanged:     c.AssertInputUnchanged,
+			ContinueOnRequireFailure: c.ContinueOnRequireFailure,
 		}
 
+		checkAndSet(&im.Cases[i].InputFn, c.InputFn != nil, func(ctx *Ctx, _ any) I {
+			return c.InputFn(ctx)
+		})
+
 		checkAndSet(&im.Cases[i].BeforeCall, c.BeforeCall != nil, func(ctx *Ctx, _ any, in I) {
 			c.BeforeCall(ctx, in)
 		})
@@ -343,7 +1556,7 @@ func (m FunctionMesa[I, O]) Run(t *testing.T) {
 		})
 	}
 
-	im.Run(t)
+	return im
 }
 
 // MethodBenchmarkMesa represents a collection of test cases and the functions to create instances
@@ -355,7 +1568,8 @@ type MethodBenchmarkMesa[InstanceType, FieldsType, InputType, OutputType any] st
 	// [Required] Function to create a new instance.
 	NewInstance func(ctx *Ctx, fields FieldsType) InstanceType
 
-	// [Required] Target function under test.
+	// [Required] Target function under test. It may be nil when every case sets BenchmarkSetup and none has a
+	// WarmupCheck.
 	Target func(ctx *Ctx, inst InstanceType, in InputType) OutputType
 
 	// [Required] List of test cases.
@@ -375,6 +1589,18 @@ type MethodBenchmarkMesa[InstanceType, FieldsType, InputType, OutputType any] st
 
 	// [Optional] Teardown function is called after all cases finish
 	Teardown func(ctx *Ctx)
+
+	// [Optional] ProfileDir is the directory that the profiles of each case are written to when CPUProfile or
+	// MemProfile is set. Defaults to testdata/profiles.
+	ProfileDir string
+
+	// [Optional] CPUProfile writes a CPU profile of the timed loop of each case to ProfileDir/<case>.cpu.prof. Warmup
+	// and checks are not profiled. It can't be combined with -cpuprofile.
+	CPUProfile bool
+
+	// [Optional] MemProfile writes a heap profile to ProfileDir/<case>.mem.prof once the timed loop of each case
+	// finishes.
+	MemProfile bool
 }
 
 // MethodBenchmarkCase represents a benchmark case with its associated properties.
@@ -402,9 +1628,39 @@ type MethodBenchmarkCase[InstanceType, FieldsType, InputType, OutputType any] st
 	// in the MethodMesa if provided.
 	Check func(ctx *Ctx, inst InstanceType, in InputType, out OutputType)
 
+	// [Optional] WarmupCheck is called with the output of a single call to the target function made before the timer
+	// is reset. It verifies the benchmarked code is correct without adding assertions to the timed loop.
+	WarmupCheck func(ctx *Ctx, inst InstanceType, in InputType, out OutputType)
+
 	// [Optional] Reason to skip the benchmark case. The benchmark is only skipped if this field is not empty
 	Skip string
 
+	// [Optional] Parallel runs the target function with b.RunParallel to measure contended throughput. The same
+	// instance is shared by every goroutine, so NewInstance must produce an instance that is safe for concurrent use.
+	Parallel bool
+
+	// [Optional] Bytes is the number of bytes processed by a single call to the target function. When it is set, the
+	// throughput of the target is reported in MB/s.
+	Bytes int64
+
+	// [Optional] Weight scales the number of times the target is called to b.N times the weight, rounded up, so that
+	// cases of very different cost can share a -benchtime, e.g. 0.001 for a case that is a thousand times slower than
+	// the others. The ns/op, MB/s and ReportMetric values are divided by the number of calls that were made, so they
+	// are comparable with unweighted cases, but the allocs/op and B/op of -benchmem are not. The weight only shortens
+	// the wall time of the case with a fixed count such as -benchtime=1000x, where a weight of 0.001 makes a thousandth
+	// of the calls. With a duration such as the default -benchtime=1s, b.N is autoscaled until the case runs for the
+	// duration, so the weight mostly cancels out: a lighter weight gives a larger b.N and the case takes about as long
+	// as without it. It is ignored by Parallel cases.
+	Weight float64
+
+	// [Optional] BenchmarkSetup times NewInstance instead of the target, so ns/op and the other metrics are per
+	// constructed instance, e.g. to benchmark constructors and heavy fixtures. The target is not called in the timed
+	// loop, so it may be a no-op, and Check receives the instance created before the loop and the zero output. Each
+	// instance created in the loop is passed to Cleanup, and the cleanups it registers on ctx, e.g. with WithCancel or
+	// TempDir, run right after it with the timer stopped rather than when the benchmark finishes. Parallel cases clean
+	// up their instances once the loop finishes instead. Metrics reported before the loop are discarded.
+	BenchmarkSetup bool
+
 	// [Optional] Function to execute before calling the target function. It will be called instead of the BeforeCall
 	// function in the MethodMesa if provided.
 	BeforeCall func(ctx *Ctx, inst InstanceType, in InputType)
@@ -416,6 +1672,29 @@ type MethodBenchmarkCase[InstanceType, FieldsType, InputType, OutputType any] st
 
 // Run executes all the benchmark cases in the Mesa instance.
 func (m MethodBenchmarkMesa[Inst, F, I, O]) Run(b *testing.B) {
+	m.run(b, nil)
+}
+
+// benchResult holds the measurements of a benchmark case.
+type benchResult struct {
+	input       any
+	n           int
+	nsPerOp     float64
+	allocsPerOp float64
+	bytesPerOp  float64
+	metrics     []reportedMetric
+}
+
+// reportedMetric is a custom metric of a benchmark case, as passed to b.ReportMetric.
+type reportedMetric struct {
+	name  string
+	value float64
+}
+
+// run executes all the benchmark cases and calls onResult, if it is not nil, with the measurements of each case
+// once its timed loop finishes. onResult is called for every round of b.N, so the last call holds the final
+// measurements.
+func (m MethodBenchmarkMesa[Inst, F, I, O]) run(b *testing.B, onResult func(name string, r benchResult)) {
 	ctx := newCtx(b)
 
 	if m.Init != nil {
@@ -435,6 +1714,9 @@ func (m MethodBenchmarkMesa[Inst, F, I, O]) Run(b *testing.B) {
 			}
 
 			ctx := newCtx(b)
+			ctx.parallel = bb.Parallel
+
+			runGlobalHooks(ctx, bb.Name)
 
 			if bb.FieldsFn != nil {
 				bb.Fields = bb.FieldsFn(ctx)
@@ -446,16 +1728,18 @@ func (m MethodBenchmarkMesa[Inst, F, I, O]) Run(b *testing.B) {
 				bb.Input = bb.InputFn(ctx, inst)
 			}
 
-			cleanup := func() {}
+			var cleanup func(inst Inst)
 
 			switch {
 			case bb.Cleanup != nil:
-				cleanup = func() { bb.Cleanup(ctx, inst) }
+				cleanup = func(inst Inst) { bb.Cleanup(ctx, inst) }
 			case m.Cleanup != nil:
-				cleanup = func() { m.Cleanup(ctx, inst) }
+				cleanup = func(inst Inst) { m.Cleanup(ctx, inst) }
 			}
 
-			b.Cleanup(cleanup)
+			if cleanup != nil {
+				b.Cleanup(func() { cleanup(inst) })
+			}
 
 			switch {
 			case bb.BeforeCall != nil:
@@ -464,21 +1748,138 @@ func (m MethodBenchmarkMesa[Inst, F, I, O]) Run(b *testing.B) {
 				m.BeforeCall(ctx, inst, bb.Input)
 			}
 
+			if bb.WarmupCheck != nil {
+				bb.WarmupCheck(ctx, inst, bb.Input, m.Target(ctx, inst, bb.Input))
+			}
+
+			if bb.Bytes > 0 {
+				b.SetBytes(bb.Bytes)
+			}
+
 			var out O
 
-			b.ResetTimer()
+			dir := m.ProfileDir
+			if dir == "" {
+				dir = filepath.Join("testdata", "profiles")
+			}
+
+			prof := newProfiler(ctx, dir, bb.Name, m.CPUProfile, m.MemProfile)
+
+			n := b.N
+			if bb.Weight > 0 && !bb.Parallel {
+				n = int(math.Ceil(float64(b.N) * bb.Weight))
+			}
+
+			// Metrics reported by the construction of the instance above would be divided by the constructions of
+			// the timed loop.
+			if bb.BenchmarkSetup {
+				ctx.metrics = metrics{byName: make(map[string]*metric)}
+			}
 
-			for i := 0; i < b.N; i++ {
-				innerOut := m.Target(ctx, inst, bb.Input)
-				out = innerOut
+			// ReadMemStats stops the world, so it is called outside of the timed loop.
+			var before, after runtime.MemStats
+			if onResult != nil {
+				runtime.ReadMemStats(&before)
+			}
+
+			// The cleanups of the instances created in the timed loop are collected instead of registered on b.
+			loop := &cleanups{}
+			if bb.BenchmarkSetup {
+				ctx.loop = loop
+			}
+
+			b.ResetTimer()
+			prof.start()
+
+			if bb.Parallel {
+				var mu sync.Mutex
+
+				b.RunParallel(func(pb *testing.PB) {
+					var innerOut O
+					called := false
+
+					for pb.Next() {
+						if bb.BenchmarkSetup {
+							loopInst := m.NewInstance(ctx, bb.Fields)
+							if cleanup != nil {
+								loop.add(func() { cleanup(loopInst) })
+							}
+
+							continue
+						}
+
+						innerOut = m.Target(ctx, inst, bb.Input)
+						called = true
+					}
+
+					if called {
+						mu.Lock()
+						out = innerOut
+						mu.Unlock()
+					}
+				})
+			} else if bb.BenchmarkSetup {
+				for i := 0; i < n; i++ {
+					loopInst := m.NewInstance(ctx, bb.Fields)
+
+					if cleanup != nil || loop.pending() {
+						b.StopTimer()
+
+						if cleanup != nil {
+							cleanup(loopInst)
+						}
+
+						loop.run()
+						b.StartTimer()
+					}
+				}
+			} else {
+				for i := 0; i < n; i++ {
+					innerOut := m.Target(ctx, inst, bb.Input)
+					out = innerOut
+				}
 			}
 
 			result = out
 
 			b.StopTimer()
 
-			for name, value := range ctx.metrics {
-				b.ReportMetric(value/float64(b.N), name)
+			if onResult != nil {
+				runtime.ReadMemStats(&after)
+			}
+
+			prof.stop()
+
+			// The instances of a Parallel loop are cleaned up once the loop finishes.
+			ctx.loop = nil
+			loop.run()
+
+			nsPerOp := float64(b.Elapsed().Nanoseconds()) / float64(n)
+
+			// The builtin ns/op and MB/s assume that b.N iterations ran.
+			if n != b.N {
+				b.ReportMetric(nsPerOp, "ns/op")
+
+				if bb.Bytes > 0 && b.Elapsed() > 0 {
+					b.ReportMetric(float64(bb.Bytes)*float64(n)/1e6/b.Elapsed().Seconds(), "MB/s")
+				}
+			}
+
+			reported := make([]reportedMetric, len(ctx.metrics.names))
+			for i, name := range ctx.metrics.names {
+				reported[i] = reportedMetric{name: name, value: ctx.metrics.byName[name].result(n)}
+				b.ReportMetric(reported[i].value, name)
+			}
+
+			if onResult != nil {
+				onResult(bb.Name, benchResult{
+					input:       bb.Input,
+					n:           n,
+					nsPerOp:     nsPerOp,
+					allocsPerOp: float64(after.Mallocs-before.Mallocs) / float64(n),
+					bytesPerOp:  float64(after.TotalAlloc-before.TotalAlloc) / float64(n),
+					metrics:     reported,
+				})
 			}
 
 			switch {
@@ -1,11 +1,94 @@
 package mesa_test
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/a20r/mesa"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
+// subprocessEnv holds the name of the test that runInSubprocess runs in a child process.
+const subprocessEnv = "MESA_SUBPROCESS"
+
+// runInSubprocess runs fn in a child test process and returns its verbose output. It is used to test failures and
+// logs reported through the *testing.T passed to a Mesa. The returned ok is false in the child process, where fn is
+// called directly and the caller must return.
+func runInSubprocess(t *testing.T, fn func(t *testing.T)) (out string, ok bool, err error) {
+	if os.Getenv(subprocessEnv) == t.Name() {
+		fn(t)
+		return "", false, nil
+	}
+
+	cmd := exec.Command(os.Args[0], "-test.run=^"+regexp.QuoteMeta(t.Name())+"$", "-test.v")
+	cmd.Env = append(os.Environ(), subprocessEnv+"="+t.Name())
+	b, err := cmd.CombinedOutput()
+
+	return string(b), true, err
+}
+
+// expectFailure runs fn in a child test process and asserts that it fails with output containing each of the given
+// substrings.
+func expectFailure(t *testing.T, fn func(t *testing.T), contains ...string) {
+	out, ok, err := runInSubprocess(t, fn)
+	if !ok {
+		return
+	}
+
+	require.Error(t, err, "expected test to fail:\n%s", out)
+
+	for _, c := range contains {
+		assert.Contains(t, out, c)
+	}
+}
+
+// recorder is a TestingT that records failures instead of failing the enclosing test.
+type recorder struct {
+	errors []string
+	failed bool
+}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+	r.failed = true
+}
+
+func (r *recorder) FailNow() {
+	r.failed = true
+	runtime.Goexit()
+}
+
+func (r *recorder) Name() string {
+	return "TestRecorder/case"
+}
+
+// record calls fn with a context backed by a recorder and returns the recorder once fn returns or fails.
+func record(fn func(ctx *mesa.Ctx)) *recorder {
+	r := &recorder{}
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		fn(mesa.NewTestCtx(r))
+	}()
+
+	<-done
+
+	return r
+}
+
 func BenchmarkTest(b *testing.B) {
 	m := mesa.MethodBenchmarkMesa[*MyStruct, int, int, mesa.Empty]{
 		NewInstance: func(ctx *mesa.Ctx, value int) *MyStruct {
@@ -31,3 +114,928 @@ func BenchmarkTest(b *testing.B) {
 
 	m.Run(b)
 }
+
+func BenchmarkWarmupCheck(b *testing.B) {
+	m := mesa.MethodBenchmarkMesa[*MyStruct, int, int, int]{
+		NewInstance: func(ctx *mesa.Ctx, value int) *MyStruct {
+			return &MyStruct{Value: value}
+		},
+		Target: func(ctx *mesa.Ctx, inst *MyStruct, n int) int {
+			inst.Add(n)
+			return inst.Value
+		},
+		Cases: []mesa.MethodBenchmarkCase[*MyStruct, int, int, int]{
+			{
+				Name:   "Add 1 to 0",
+				Fields: 0,
+				Input:  1,
+				WarmupCheck: func(ctx *mesa.Ctx, inst *MyStruct, in int, out int) {
+					ctx.Re.Equal(1, out)
+				},
+			},
+		},
+	}
+
+	m.Run(b)
+}
+
+func TestCancel(t *testing.T) {
+	m := mesa.FunctionMesa[time.Duration, error]{
+		Target: func(ctx *mesa.Ctx, wait time.Duration) error {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(wait):
+				return nil
+			}
+		},
+		Cases: []mesa.FunctionCase[time.Duration, error]{
+			{
+				Name:  "Canceled mid-call",
+				Input: time.Minute,
+				Cancel: func(ctx *mesa.Ctx, cancel context.CancelFunc) {
+					cancel()
+				},
+				// Cancel is only started once the hooks before the target have run.
+				BeforeCall: func(ctx *mesa.Ctx, _ time.Duration) {
+					time.Sleep(10 * time.Millisecond)
+					ctx.As.NoError(ctx.Err())
+				},
+				ExpectedFn: func(ctx *mesa.Ctx, _ time.Duration) error {
+					time.Sleep(10 * time.Millisecond)
+					ctx.As.NoError(ctx.Err())
+					return context.Canceled
+				},
+				Check: func(ctx *mesa.Ctx, _ time.Duration, err error) {
+					ctx.As.ErrorIs(err, context.Canceled)
+				},
+			},
+			{
+				Name:  "Completes without Cancel",
+				Input: time.Millisecond,
+				Check: func(ctx *mesa.Ctx, _ time.Duration, err error) {
+					ctx.As.NoError(err)
+				},
+			},
+		},
+	}
+
+	m.Run(t)
+}
+
+func BenchmarkParallel(b *testing.B) {
+	m := mesa.MethodBenchmarkMesa[*sync.Map, mesa.Empty, int, int]{
+		NewInstance: func(ctx *mesa.Ctx, _ mesa.Empty) *sync.Map {
+			return &sync.Map{}
+		},
+		Target: func(ctx *mesa.Ctx, inst *sync.Map, n int) int {
+			inst.Store(n, n)
+			ctx.ReportMetric(1, "stores/op")
+			return n
+		},
+		Cases: []mesa.MethodBenchmarkCase[*sync.Map, mesa.Empty, int, int]{
+			{
+				Name:     "Store",
+				Input:    1,
+				Parallel: true,
+				Check: func(ctx *mesa.Ctx, inst *sync.Map, in int, out int) {
+					ctx.As.Equal(1, out)
+				},
+			},
+		},
+	}
+
+	m.Run(b)
+}
+
+func TestLabels(t *testing.T) {
+	m := mesa.FunctionMesa[int, int]{
+		Labels: map[string]string{"component": "math", "owner": "core"},
+		Target: func(ctx *mesa.Ctx, in int) int {
+			return -in
+		},
+		Cases: []mesa.FunctionCase[int, int]{
+			{
+				Name:     "Suite labels",
+				Input:    1,
+				Expected: -1,
+			},
+			{
+				Name:     "Case labels override",
+				Input:    2,
+				Expected: -2,
+				Labels:   map[string]string{"owner": "platform"},
+			},
+		},
+	}
+
+	m.Run(t)
+}
+
+func TestLabels_Output(t *testing.T) {
+	out, ok, err := runInSubprocess(t, TestLabels)
+	if !ok {
+		return
+	}
+
+	require.NoError(t, err, out)
+	assert.Contains(t, out, "mesa labels: component=math owner=core")
+	assert.Contains(t, out, "mesa labels: component=math owner=platform")
+}
+
+type counter struct {
+	hits  map[string]int
+	total int
+}
+
+func (c *counter) Get(key string) int {
+	return c.hits[key]
+}
+
+func (c *counter) GetAndCount(key string) int {
+	c.hits[key]++
+	c.total++
+	return c.hits[key]
+}
+
+func newCounter(_ *mesa.Ctx, hits map[string]int) *counter {
+	return &counter{hits: hits}
+}
+
+func TestAssertImmutable(t *testing.T) {
+	m := mesa.MethodMesa[*counter, map[string]int, string, int]{
+		NewInstance: newCounter,
+		Target: func(ctx *mesa.Ctx, inst *counter, key string) int {
+			return inst.Get(key)
+		},
+		Cases: []mesa.MethodCase[*counter, map[string]int, string, int]{
+			{
+				Name:            "Get does not mutate",
+				Fields:          map[string]int{"a": 1},
+				Input:           "a",
+				Expected:        1,
+				AssertImmutable: true,
+			},
+		},
+	}
+
+	m.Run(t)
+
+	m.CloneInstance = func(inst *counter) *counter {
+		hits := make(map[string]int, len(inst.hits))
+		for k, v := range inst.hits {
+			hits[k] = v
+		}
+		return &counter{hits: hits, total: inst.total}
+	}
+
+	m.Run(t)
+}
+
+func TestAssertImmutable_Mutated(t *testing.T) {
+	expectFailure(t, func(t *testing.T) {
+		m := mesa.MethodMesa[*counter, map[string]int, string, int]{
+			NewInstance: newCounter,
+			Target: func(ctx *mesa.Ctx, inst *counter, key string) int {
+				return inst.GetAndCount(key)
+			},
+			Cases: []mesa.MethodCase[*counter, map[string]int, string, int]{
+				{
+					Name:            "GetAndCount mutates",
+					Fields:          map[string]int{"a": 1},
+					Input:           "a",
+					AssertImmutable: true,
+				},
+			},
+		}
+
+		m.Run(t)
+	}, "instance was mutated by the target", "total: (int) 1")
+}
+
+type scores struct {
+	Player string
+	Points []int
+}
+
+func TestAssertInputUnchanged(t *testing.T) {
+	m := mesa.FunctionMesa[scores, int]{
+		Target: func(ctx *mesa.Ctx, in scores) int {
+			total := 0
+			for _, p := range in.Points {
+				total += p
+			}
+			return total
+		},
+		Cases: []mesa.FunctionCase[scores, int]{
+			{
+				Name:                 "Sum does not mutate",
+				Input:                scores{Player: "ada", Points: []int{3, 1, 2}},
+				Expected:             6,
+				AssertInputUnchanged: true,
+			},
+		},
+	}
+
+	m.Run(t)
+
+	m.CloneInput = func(in scores) scores {
+		return scores{Player: in.Player, Points: append([]int(nil), in.Points...)}
+	}
+
+	m.Run(t)
+}
+
+func TestAssertInputUnchanged_ExpectedFn(t *testing.T) {
+	var seen [][]int
+
+	m := mesa.FunctionMesa[scores, int]{
+		Target: func(ctx *mesa.Ctx, in scores) int {
+			seen = append(seen, append([]int(nil), in.Points...))
+
+			best := in.Points[0]
+			for _, p := range in.Points {
+				if p > best {
+					best = p
+				}
+			}
+			return best
+		},
+		Cases: []mesa.FunctionCase[scores, int]{
+			{
+				Name:  "Oracle sorts in place",
+				Input: scores{Player: "ada", Points: []int{3, 1, 2}},
+				ExpectedFn: func(ctx *mesa.Ctx, in scores) int {
+					sort.Ints(in.Points)
+					return in.Points[len(in.Points)-1]
+				},
+				AssertInputUnchanged: true,
+			},
+		},
+	}
+
+	// Without CloneInput, the oracle and the target share the slice, but the mutation isn't blamed on the target.
+	m.Run(t)
+
+	m.Cases[0].Input.Points = []int{3, 1, 2}
+	m.CloneInput = func(in scores) scores {
+		return scores{Player: in.Player, Points: append([]int(nil), in.Points...)}
+	}
+
+	m.Run(t)
+
+	assert.Equal(t, [][]int{{1, 2, 3}, {3, 1, 2}}, seen)
+}
+
+func TestAssertInputUnchanged_Mutated(t *testing.T) {
+	expectFailure(t, func(t *testing.T) {
+		m := mesa.FunctionMesa[scores, int]{
+			Target: func(ctx *mesa.Ctx, in scores) int {
+				sort.Ints(in.Points)
+				return in.Points[len(in.Points)-1]
+			},
+			Cases: []mesa.FunctionCase[scores, int]{
+				{
+					Name:                 "Sort in place mutates",
+					Input:                scores{Player: "ada", Points: []int{3, 1, 2}},
+					Expected:             3,
+					AssertInputUnchanged: true,
+				},
+			},
+		}
+
+		m.Run(t)
+	}, "input was mutated by the target", "Points: ([]int) (len=3) {")
+}
+
+func TestOptionalTarget(t *testing.T) {
+	m := mesa.MethodMesa[*MyStruct, int, int, mesa.Empty]{
+		NewInstance: func(ctx *mesa.Ctx, value int) *MyStruct {
+			return &MyStruct{Value: value}
+		},
+		BeforeCall: func(ctx *mesa.Ctx, inst *MyStruct, n int) {
+			inst.Add(n)
+		},
+		Check: func(ctx *mesa.Ctx, inst *MyStruct, n int, out mesa.Empty) {
+			ctx.As.Nil(out)
+			ctx.As.Equal(n+1, inst.Value)
+		},
+		Cases: []mesa.MethodCase[*MyStruct, int, int, mesa.Empty]{
+			{Name: "Add 1 to 1", Fields: 1, Input: 1},
+			{Name: "Add 2 to 1", Fields: 1, Input: 2},
+		},
+	}
+
+	m.Run(t)
+}
+
+func TestRequireEnv(t *testing.T) {
+	t.Setenv("MESA_TEST_SET", "1")
+
+	ran := map[string]bool{}
+
+	m := mesa.FunctionMesa[string, mesa.Empty]{
+		BeforeCall: func(ctx *mesa.Ctx, name string) {
+			ran[name] = true
+		},
+		Cases: []mesa.FunctionCase[string, mesa.Empty]{
+			{
+				Name:       "Set",
+				Input:      "set",
+				RequireEnv: []string{"MESA_TEST_SET"},
+			},
+			{
+				Name:       "Unset",
+				Input:      "unset",
+				RequireEnv: []string{"MESA_TEST_SET", "MESA_TEST_UNSET"},
+			},
+		},
+	}
+
+	m.Run(t)
+
+	assert.Equal(t, map[string]bool{"set": true}, ran)
+}
+
+type conn struct {
+	addr   string
+	closed bool
+}
+
+func dial(addr string) (*conn, error) {
+	c := &conn{addr: addr}
+	if addr == "" {
+		return c, errors.New("missing address")
+	}
+
+	return c, nil
+}
+
+func TestNewInstanceErr(t *testing.T) {
+	m := mesa.MethodMesa[*conn, string, mesa.Empty, string]{
+		NewInstanceErr: func(ctx *mesa.Ctx, addr string) (*conn, error) {
+			return dial(addr)
+		},
+		Target: func(ctx *mesa.Ctx, inst *conn, _ mesa.Empty) string {
+			return inst.addr
+		},
+		Cleanup: func(ctx *mesa.Ctx, inst *conn) {
+			inst.closed = true
+		},
+		Cases: []mesa.MethodCase[*conn, string, mesa.Empty, string]{
+			{
+				Name:     "Dial",
+				Fields:   "localhost:80",
+				Expected: "localhost:80",
+			},
+		},
+	}
+
+	m.Run(t)
+}
+
+func TestNewInstanceErr_Fails(t *testing.T) {
+	expectFailure(t, func(t *testing.T) {
+		m := mesa.MethodMesa[*conn, string, mesa.Empty, string]{
+			NewInstanceErr: func(ctx *mesa.Ctx, addr string) (*conn, error) {
+				return dial(addr)
+			},
+			Target: func(ctx *mesa.Ctx, inst *conn, _ mesa.Empty) string {
+				panic("target should not be called")
+			},
+			Cleanup: func(ctx *mesa.Ctx, inst *conn) {
+				ctx.T().Logf("cleanup called for partial instance: %v", inst != nil)
+			},
+			Cases: []mesa.MethodCase[*conn, string, mesa.Empty, string]{
+				{Name: "Missing address"},
+			},
+		}
+
+		m.Run(t)
+	}, "failed to create instance", "missing address", "cleanup called for partial instance: true")
+}
+
+func TestNameFn(t *testing.T) {
+	type input struct{ a, b int }
+
+	var names []string
+
+	m := mesa.FunctionMesa[input, int]{
+		NameFn: func(ctx *mesa.Ctx, in input) string {
+			return fmt.Sprintf("Add(%d, %d)", in.a, in.b)
+		},
+		Target: func(ctx *mesa.Ctx, in input) int {
+			names = append(names, ctx.T().Name())
+			return Add(in.a, in.b)
+		},
+		Cases: []mesa.FunctionCase[input, int]{
+			{Input: input{1, 2}, Expected: 3},
+			{
+				Name:     "Ignored",
+				Input:    input{2, 2},
+				Expected: 4,
+				NameFn: func(ctx *mesa.Ctx, in input) string {
+					return fmt.Sprintf("%d/%d", in.a, in.b)
+				},
+			},
+		},
+	}
+
+	m.Run(t)
+
+	assert.Equal(t, []string{"TestNameFn/Add(1,_2)", "TestNameFn/2_2"}, names)
+}
+
+func TestDerivedNames(t *testing.T) {
+	var names []string
+
+	m := mesa.FunctionMesa[string, int]{
+		Target: func(ctx *mesa.Ctx, in string) int {
+			names = append(names, ctx.T().Name())
+			return len(in)
+		},
+		Cases: []mesa.FunctionCase[string, int]{
+			{Input: "go", Expected: 2},
+			{Input: "a b/c", Expected: 5},
+			{Input: "go", Expected: 2},
+			{Name: "Explicit", Input: "mesa", Expected: 4},
+			{Input: "Explicit", Expected: 8},
+			{Input: ""},
+			{Input: strings.Repeat("x", 100), Expected: 100},
+		},
+	}
+
+	m.Run(t)
+
+	t.Run("Shard", func(t *testing.T) {
+		m.RunShard(t, 1, 2)
+	})
+
+	assert.Equal(t, []string{
+		"TestDerivedNames/go",
+		"TestDerivedNames/a_b_c",
+		"TestDerivedNames/go_2",
+		"TestDerivedNames/Explicit",
+		"TestDerivedNames/Explicit_4",
+		`TestDerivedNames/""`,
+		"TestDerivedNames/" + strings.Repeat("x", 63) + "…",
+		// Names are derived before sharding, so the shard runs the same names.
+		"TestDerivedNames/Shard/a_b_c",
+		"TestDerivedNames/Shard/Explicit",
+		`TestDerivedNames/Shard/""`,
+	}, names)
+}
+
+func TestFilter(t *testing.T) {
+	var ran []string
+
+	m := mesa.FunctionMesa[string, mesa.Empty]{
+		Labels: map[string]string{"kind": "unit"},
+		Filter: func(c mesa.CaseMeta) bool {
+			return c.Labels["kind"] == "unit" && c.Name != "Excluded"
+		},
+		BeforeCall: func(ctx *mesa.Ctx, in string) {
+			ran = append(ran, in)
+		},
+		Cases: []mesa.FunctionCase[string, mesa.Empty]{
+			{Name: "Included", Input: "included"},
+			{Name: "Excluded", Input: "excluded"},
+			{Name: "Integration", Input: "integration", Labels: map[string]string{"kind": "integration"}},
+		},
+	}
+
+	m.Run(t)
+
+	assert.Equal(t, []string{"included"}, ran)
+}
+
+func TestCtx_Shared(t *testing.T) {
+	m := mesa.FunctionMesa[int, int]{
+		Target: func(ctx *mesa.Ctx, in int) int {
+			return in * in
+		},
+		Check: func(ctx *mesa.Ctx, in int, out int) {
+			ctx.SetValue("square", out)
+			ctx.Shared().Store(in, out)
+		},
+		Teardown: func(ctx *mesa.Ctx) {
+			sum := 0
+			ctx.Shared().Range(func(_, v any) bool {
+				sum += v.(int)
+				return true
+			})
+
+			ctx.As.Equal(1+4+9, sum)
+			ctx.As.Nil(ctx.GetValue("square"))
+		},
+		Cases: []mesa.FunctionCase[int, int]{
+			{Name: "One", Input: 1},
+			{Name: "Two", Input: 2},
+			{Name: "Three", Input: 3},
+		},
+	}
+
+	m.Run(t)
+}
+
+func TestRepeat(t *testing.T) {
+	var (
+		instances int
+		names     []string
+	)
+
+	m := mesa.MethodMesa[*strings.Builder, mesa.Empty, string, string]{
+		NewInstance: func(ctx *mesa.Ctx, _ mesa.Empty) *strings.Builder {
+			instances++
+			return &strings.Builder{}
+		},
+		Target: func(ctx *mesa.Ctx, inst *strings.Builder, in string) string {
+			inst.WriteString(in)
+			return inst.String()
+		},
+		Cases: []mesa.MethodCase[*strings.Builder, mesa.Empty, string, string]{
+			{
+				Name:     "Fresh instance",
+				Input:    "a",
+				Expected: "a",
+				Repeat:   3,
+				Check: func(ctx *mesa.Ctx, _ *strings.Builder, _ string, _ string) {
+					names = append(names, ctx.T().Name())
+				},
+			},
+		},
+	}
+
+	m.Run(t)
+
+	assert.Equal(t, 3, instances)
+	assert.Equal(t, []string{
+		"TestRepeat/Fresh_instance/repeat-0",
+		"TestRepeat/Fresh_instance/repeat-1",
+		"TestRepeat/Fresh_instance/repeat-2",
+	}, names)
+}
+
+func TestExpectedFn(t *testing.T) {
+	m := mesa.FunctionMesa[int, int]{
+		Target: func(ctx *mesa.Ctx, in int) int {
+			return in * in
+		},
+		Cases: []mesa.FunctionCase[int, int]{
+			{
+				Name:  "Oracle",
+				Input: 3,
+				ExpectedFn: func(ctx *mesa.Ctx, in int) int {
+					return in + in + in
+				},
+			},
+			{
+				Name:     "Takes priority over Expected",
+				Input:    2,
+				Expected: 5,
+				ExpectedFn: func(ctx *mesa.Ctx, in int) int {
+					return in << 1
+				},
+			},
+		},
+	}
+
+	m.Run(t)
+}
+
+func TestExpectedFn_CloneInput(t *testing.T) {
+	var sortedInput []bool
+
+	m := mesa.FunctionMesa[[]int, []int]{
+		Target: func(ctx *mesa.Ctx, in []int) []int {
+			sortedInput = append(sortedInput, sort.IntsAreSorted(in))
+
+			out := append([]int(nil), in...)
+			sort.Ints(out)
+			return out
+		},
+		CloneInput: func(in []int) []int {
+			return append([]int(nil), in...)
+		},
+		Cases: []mesa.FunctionCase[[]int, []int]{
+			{
+				Name:  "Oracle sorts in place",
+				Input: []int{3, 1, 2},
+				ExpectedFn: func(ctx *mesa.Ctx, in []int) []int {
+					sort.Ints(in)
+					return in
+				},
+			},
+		},
+	}
+
+	m.Run(t)
+
+	assert.Equal(t, []bool{false}, sortedInput, "the target must see the input before the oracle sorted it")
+}
+
+func TestExpectedFn_Mismatch(t *testing.T) {
+	expectFailure(t, func(t *testing.T) {
+		m := mesa.FunctionMesa[int, int]{
+			Target: func(ctx *mesa.Ctx, in int) int {
+				return in * in
+			},
+			Cases: []mesa.FunctionCase[int, int]{
+				{
+					Name:  "Zero expected",
+					Input: 3,
+					ExpectedFn: func(ctx *mesa.Ctx, in int) int {
+						return 0
+					},
+				},
+			},
+		}
+
+		m.Run(t)
+	}, "--- FAIL: TestExpectedFn_Mismatch/Zero_expected")
+}
+
+func TestSortCases(t *testing.T) {
+	var order []string
+
+	m := mesa.FunctionMesa[string, mesa.Empty]{
+		SortCases: func(a, b mesa.CaseMeta) bool {
+			return a.Priority > b.Priority
+		},
+		BeforeCall: func(ctx *mesa.Ctx, in string) {
+			order = append(order, in)
+		},
+		Cases: []mesa.FunctionCase[string, mesa.Empty]{
+			{Name: "Low", Input: "low", Priority: 1},
+			{Name: "High", Input: "high", Priority: 10},
+			{Name: "Medium", Input: "medium", Priority: 5},
+			{Name: "Also medium", Input: "also medium", Priority: 5},
+		},
+	}
+
+	m.Run(t)
+
+	assert.Equal(t, []string{"high", "medium", "also medium", "low"}, order)
+	assert.Equal(t, "Low", m.Cases[0].Name, "the cases of the suite must not be reordered")
+}
+
+func TestCacheKey(t *testing.T) {
+	calls := map[string]int{}
+
+	fixture := func(name string) func(ctx *mesa.Ctx) []string {
+		return func(ctx *mesa.Ctx) []string {
+			calls[name]++
+			return []string{name}
+		}
+	}
+
+	m := mesa.MethodMesa[[]string, []string, int, string]{
+		NewInstance: func(ctx *mesa.Ctx, fields []string) []string {
+			return fields
+		},
+		Target: func(ctx *mesa.Ctx, inst []string, i int) string {
+			return inst[i]
+		},
+		Cases: []mesa.MethodCase[[]string, []string, int, string]{
+			{Name: "Users 1", CacheKey: "users", FieldsFn: fixture("users"), Expected: "users"},
+			{Name: "Users 2", CacheKey: "users", FieldsFn: fixture("users"), Expected: "users"},
+			{Name: "Orders 1", CacheKey: "orders", FieldsFn: fixture("orders"), Expected: "orders"},
+			{Name: "Orders 2", CacheKey: "orders", FieldsFn: fixture("orders"), Expected: "orders"},
+			{Name: "Uncached 1", FieldsFn: fixture("uncached"), Expected: "uncached"},
+			{Name: "Uncached 2", FieldsFn: fixture("uncached"), Expected: "uncached"},
+		},
+	}
+
+	m.Run(t)
+
+	assert.Equal(t, map[string]int{"users": 1, "orders": 1, "uncached": 2}, calls)
+}
+
+func TestCacheKey_SharedResources(t *testing.T) {
+	server := func(ctx *mesa.Ctx) string {
+		return ctx.StartServer(http.NotFoundHandler()).URL
+	}
+
+	m := mesa.MethodMesa[string, string, mesa.Empty, string]{
+		NewInstance: func(ctx *mesa.Ctx, url string) string {
+			return url
+		},
+		Target: func(ctx *mesa.Ctx, url string, _ mesa.Empty) string {
+			resp, err := http.Get(url)
+			ctx.NoErr(err)
+			defer resp.Body.Close()
+
+			return resp.Status
+		},
+		Cases: []mesa.MethodCase[string, string, mesa.Empty, string]{
+			{Name: "Server 1", CacheKey: "server", FieldsFn: server, Expected: "404 Not Found"},
+			{Name: "Server 2", CacheKey: "server", FieldsFn: server, Expected: "404 Not Found"},
+		},
+	}
+
+	m.Run(t)
+}
+
+func TestFunctionCase_IgnoreOrder(t *testing.T) {
+	keys := func(ctx *mesa.Ctx, in map[string]int) []string {
+		var out []string
+		for k, n := range in {
+			for i := 0; i < n; i++ {
+				out = append(out, k)
+			}
+		}
+
+		return out
+	}
+
+	m := mesa.FunctionMesa[map[string]int, []string]{
+		RequireAssertions: true,
+		Target:            keys,
+		Check:             func(ctx *mesa.Ctx, in map[string]int, out []string) {},
+		Cases: []mesa.FunctionCase[map[string]int, []string]{
+			{
+				Name:        "Any order",
+				Input:       map[string]int{"a": 1, "b": 1, "c": 1},
+				Expected:    []string{"c", "a", "b"},
+				IgnoreOrder: true,
+			},
+			{
+				Name:        "Duplicates",
+				Input:       map[string]int{"a": 2, "b": 1},
+				Expected:    []string{"b", "a", "a"},
+				IgnoreOrder: true,
+			},
+			{
+				Name:        "Nil matches empty",
+				Input:       map[string]int{},
+				Expected:    []string{},
+				IgnoreOrder: true,
+			},
+			{
+				Name:        "Nil expected",
+				Input:       map[string]int{},
+				IgnoreOrder: true,
+			},
+		},
+	}
+
+	m.Run(t)
+}
+
+func TestFunctionCase_IgnoreOrder_Multiset(t *testing.T) {
+	expectFailure(t, func(t *testing.T) {
+		m := mesa.FunctionMesa[[]int, []int]{
+			Target: func(ctx *mesa.Ctx, in []int) []int {
+				return in
+			},
+			Cases: []mesa.FunctionCase[[]int, []int]{
+				{Name: "Different counts", Input: []int{1, 1, 2}, Expected: []int{1, 2, 2}, IgnoreOrder: true},
+				{Name: "Nil expected", Input: []int{1}, IgnoreOrder: true},
+			},
+		}
+
+		m.Run(t)
+	}, "--- FAIL: TestFunctionCase_IgnoreOrder_Multiset/Different_counts",
+		"--- FAIL: TestFunctionCase_IgnoreOrder_Multiset/Nil_expected", "elements don't match")
+}
+
+type request struct {
+	Method  string
+	Path    string
+	Retries int
+}
+
+func TestFunctionMesa_DefaultInput(t *testing.T) {
+	describe := func(ctx *mesa.Ctx, in request) string {
+		return fmt.Sprintf("%s %s %d", in.Method, in.Path, in.Retries)
+	}
+
+	m := mesa.FunctionMesa[request, string]{
+		DefaultInput: request{Method: "GET", Path: "/", Retries: 3},
+		Target:       describe,
+		Cases: []mesa.FunctionCase[request, string]{
+			{Name: "Default", Expected: "GET / 3"},
+			{Name: "Input", Input: request{Method: "POST"}, Expected: "POST  0"},
+			{
+				Name: "InputFn",
+				InputFn: func(ctx *mesa.Ctx) request {
+					return request{Method: "PUT"}
+				},
+				Expected: "PUT  0",
+			},
+			{
+				Name: "Modified default",
+				ModifyInput: func(base request) request {
+					base.Path = "/users"
+					return base
+				},
+				Expected: "GET /users 3",
+			},
+			{
+				Name:  "Modified input",
+				Input: request{Method: "DELETE"},
+				ModifyInput: func(base request) request {
+					base.Retries = 1
+					return base
+				},
+				Expected: "DELETE  1",
+			},
+			{Name: "Zero input", Input: request{}, Expected: "GET / 3"},
+			{
+				Name: "Zero InputFn",
+				InputFn: func(ctx *mesa.Ctx) request {
+					return request{}
+				},
+				Expected: "  0",
+			},
+		},
+	}
+
+	m.Run(t)
+}
+
+func TestFunctionMesa_DefaultInput_Names(t *testing.T) {
+	var names []string
+
+	m := mesa.FunctionMesa[request, string]{
+		DefaultInput: request{Method: "GET", Path: "/"},
+		Target: func(ctx *mesa.Ctx, in request) string {
+			names = append(names, ctx.T().Name())
+			return in.Method
+		},
+		Cases: []mesa.FunctionCase[request, string]{
+			{Expected: "GET"},
+			{
+				NameFn: func(ctx *mesa.Ctx, in request) string {
+					return in.Method + in.Path
+				},
+				Expected: "GET",
+			},
+			{
+				NameFn: func(ctx *mesa.Ctx, in request) string {
+					return in.Method + in.Path
+				},
+				Input:    request{Method: "PUT", Path: "/x"},
+				Expected: "PUT",
+			},
+		},
+	}
+
+	m.Run(t)
+
+	assert.Equal(t, []string{
+		"TestFunctionMesa_DefaultInput_Names/{GET___0}",
+		"TestFunctionMesa_DefaultInput_Names/GET_",
+		"TestFunctionMesa_DefaultInput_Names/PUT_x",
+	}, names)
+}
+
+func TestFunctionMesa_DefaultInputFn(t *testing.T) {
+	m := mesa.FunctionMesa[request, string]{
+		DefaultInput: request{Method: "GET"},
+		DefaultInputFn: func(ctx *mesa.Ctx) request {
+			return request{Method: "HEAD", Path: "/" + ctx.T().Name()}
+		},
+		Target: func(ctx *mesa.Ctx, in request) string {
+			return in.Method + " " + in.Path
+		},
+		Cases: []mesa.FunctionCase[request, string]{
+			{Name: "A", Expected: "HEAD /TestFunctionMesa_DefaultInputFn/A"},
+			{Name: "B", Input: request{Method: "GET"}, Expected: "GET "},
+		},
+	}
+
+	m.Run(t)
+}
+
+func TestFunctionMesa_Normalize(t *testing.T) {
+	var checked [][]string
+
+	m := mesa.FunctionMesa[string, []string]{
+		Normalize: func(out []string) []string {
+			sort.Strings(out)
+			return out
+		},
+		Target: func(ctx *mesa.Ctx, in string) []string {
+			return strings.Fields(in)
+		},
+		Check: func(ctx *mesa.Ctx, in string, out []string) {
+			checked = append(checked, out)
+		},
+		Cases: []mesa.FunctionCase[string, []string]{
+			{Name: "Suite", Input: "b c a", Expected: []string{"a", "b", "c"}},
+			{
+				Name:  "Case",
+				Input: "b c a",
+				Normalize: func(out []string) []string {
+					return out[:1]
+				},
+				Expected: []string{"b"},
+			},
+		},
+	}
+
+	m.Run(t)
+
+	assert.Equal(t, [][]string{{"a", "b", "c"}, {"b"}}, checked)
+}
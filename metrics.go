@@ -0,0 +1,125 @@
+package mesa
+
+import "testing"
+
+// AggMode controls how the values of a benchmark metric reported during a case are aggregated before they are passed
+// to b.ReportMetric.
+type AggMode int
+
+const (
+	// AggPerOp sums the values and divides the total by the number of iterations, which is b.N unless the case has a
+	// Weight. This is how ReportMetric aggregates metrics.
+	AggPerOp AggMode = iota
+
+	// AggSum reports the total of the values.
+	AggSum
+
+	// AggMean reports the mean of the values.
+	AggMean
+
+	// AggMax reports the largest value, e.g. for peak memory.
+	AggMax
+)
+
+// metric is a benchmark metric aggregated according to its mode.
+type metric struct {
+	mode  AggMode
+	value float64
+	count int
+}
+
+// add aggregates v into the metric.
+func (m *metric) add(v float64) {
+	m.count++
+
+	switch {
+	case m.mode != AggMax:
+		m.value += v
+	case m.count == 1 || v > m.value:
+		m.value = v
+	}
+}
+
+// result returns the value reported for the metric after n iterations.
+func (m *metric) result(n int) float64 {
+	switch m.mode {
+	case AggPerOp:
+		return m.value / float64(n)
+	case AggMean:
+		return m.value / float64(m.count)
+	default:
+		return m.value
+	}
+}
+
+// metrics holds the metrics of a benchmark case in the order they were first reported.
+type metrics struct {
+	names  []string
+	byName map[string]*metric
+}
+
+// ReportMetricWith adds value to the benchmarking metric with the given name, unit and aggregation mode. The unit is
+// appended to the name following Go conventions, e.g. name "items" with unit "op" is reported as "items/op". The
+// mode of a metric is set by the first value reported for it. It will panic if the context is being used for tests.
+func (c *Ctx) ReportMetricWith(value float64, name, unit string, mode AggMode) {
+	b := c.B()
+	if b == nil {
+		return
+	}
+
+	if !c.parallel {
+		b.StopTimer()
+		defer b.StartTimer()
+	}
+
+	if unit != "" {
+		name += "/" + unit
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	m, ok := c.metrics.byName[name]
+	if !ok {
+		m = &metric{mode: mode}
+		c.metrics.byName[name] = m
+		c.metrics.names = append(c.metrics.names, name)
+	}
+
+	m.add(value)
+}
+
+// Metric returns the value accumulated so far for the benchmark metric with the given name, including its unit
+// suffix, and whether it was reported at all. It lets a Check assert on instrumented counters. The value is read before
+// the metric is aggregated, so for AggPerOp and AggMean it is the sum of the reported values, not the sum divided by
+// b.N or by the number of values. For AggMax it is the largest value.
+func (c *Ctx) Metric(name string) (float64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	m, ok := c.metrics.byName[name]
+	if !ok {
+		return 0, false
+	}
+
+	return m.value, true
+}
+
+// Benchmark measures fn as a diagnostic and never fails the case. In a test it runs fn with testing.Benchmark, logs
+// the result under name and returns it. In a benchmark it runs fn as a sub-benchmark called name instead, which
+// reports the result itself, and returns the zero result. The result depends on the machine, so it must not be used
+// in assertions.
+func (c *Ctx) Benchmark(name string, fn func(b *testing.B)) testing.BenchmarkResult {
+	if b, ok := c.t.(*testing.B); ok {
+		b.Run(name, fn)
+		return testing.BenchmarkResult{}
+	}
+
+	r := testing.Benchmark(fn)
+
+	if l, ok := c.t.(interface{ Logf(string, ...any) }); ok {
+		l.Logf("mesa benchmark %s: %s %s", name, r.String(), r.MemString())
+	}
+
+	return r
+}
@@ -0,0 +1,296 @@
+package mesa_test
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/a20r/mesa"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func BenchmarkReportMetricWith(b *testing.B) {
+	m := mesa.MethodBenchmarkMesa[*[]int, mesa.Empty, int, int]{
+		NewInstance: func(ctx *mesa.Ctx, _ mesa.Empty) *[]int {
+			return &[]int{}
+		},
+		Target: func(ctx *mesa.Ctx, inst *[]int, n int) int {
+			*inst = append(*inst, n)
+			ctx.ReportMetricWith(float64(n), "items", "op", mesa.AggPerOp)
+			ctx.ReportMetricWith(float64(len(*inst)), "peak-len", "", mesa.AggMax)
+			ctx.ReportMetricWith(float64(n), "mean-items", "", mesa.AggMean)
+			ctx.ReportMetricWith(1, "calls", "", mesa.AggSum)
+			return len(*inst)
+		},
+		Cases: []mesa.MethodBenchmarkCase[*[]int, mesa.Empty, int, int]{
+			{
+				Name:  "Append",
+				Input: 2,
+			},
+		},
+	}
+
+	m.Run(b)
+}
+
+func TestCtx_Metric(t *testing.T) {
+	setBenchtime(t, "100x")
+
+	var items, peak float64
+	var okItems, okPeak, okMissing bool
+
+	m := mesa.MethodBenchmarkMesa[*[]int, mesa.Empty, int, int]{
+		NewInstance: func(ctx *mesa.Ctx, _ mesa.Empty) *[]int {
+			return &[]int{}
+		},
+		Target: func(ctx *mesa.Ctx, inst *[]int, n int) int {
+			*inst = append(*inst, n)
+			ctx.ReportMetric(float64(n), "items")
+			ctx.ReportMetricWith(float64(len(*inst)), "peak-len", "", mesa.AggMax)
+			return len(*inst)
+		},
+		Cases: []mesa.MethodBenchmarkCase[*[]int, mesa.Empty, int, int]{
+			{
+				Name:  "Append",
+				Input: 2,
+				Check: func(ctx *mesa.Ctx, inst *[]int, n int, out int) {
+					items, okItems = ctx.Metric("items")
+					peak, okPeak = ctx.Metric("peak-len")
+					_, okMissing = ctx.Metric("missing")
+				},
+			},
+		},
+	}
+
+	testing.Benchmark(m.Run)
+
+	assert.True(t, okItems)
+	assert.Equal(t, 200.0, items, "metrics are read before they are divided by b.N")
+	assert.True(t, okPeak)
+	assert.Equal(t, 100.0, peak)
+	assert.False(t, okMissing)
+}
+
+func TestCtx_Benchmark(t *testing.T) {
+	setBenchtime(t, "100x")
+
+	out, ok, err := runInSubprocess(t, func(t *testing.T) {
+		m := mesa.FunctionMesa[int, mesa.Empty]{
+			BeforeCall: func(ctx *mesa.Ctx, n int) {
+				r := ctx.Benchmark("sum", func(b *testing.B) {
+					for i := 0; i < b.N; i++ {
+						sum := 0
+						for j := 0; j < n; j++ {
+							sum += j
+						}
+					}
+				})
+
+				ctx.As.Equal(100, r.N)
+			},
+			Cases: []mesa.FunctionCase[int, mesa.Empty]{
+				{Name: "Small loop", Input: 10},
+			},
+		}
+
+		m.Run(t)
+	})
+	if !ok {
+		return
+	}
+
+	assert.NoError(t, err, out)
+	assert.Regexp(t, `mesa benchmark sum: +100\s+[0-9.]+ ns/op`, out)
+}
+
+func TestCtx_Benchmark_InBenchmark(t *testing.T) {
+	setBenchtime(t, "10x")
+
+	var calls int
+
+	m := mesa.MethodBenchmarkMesa[mesa.Empty, mesa.Empty, int, int]{
+		NewInstance: func(ctx *mesa.Ctx, _ mesa.Empty) mesa.Empty {
+			return nil
+		},
+		BeforeCall: func(ctx *mesa.Ctx, _ mesa.Empty, n int) {
+			r := ctx.Benchmark("nested", func(b *testing.B) {
+				calls++
+			})
+
+			assert.Zero(t, r.N)
+		},
+		Target: func(ctx *mesa.Ctx, _ mesa.Empty, n int) int {
+			return n
+		},
+		Cases: []mesa.MethodBenchmarkCase[mesa.Empty, mesa.Empty, int, int]{
+			{Name: "Case", Input: 1},
+		},
+	}
+
+	testing.Benchmark(m.Run)
+
+	assert.Positive(t, calls)
+}
+
+func TestMethodBenchmarkCase_Weight(t *testing.T) {
+	setBenchtime(t, "100x")
+
+	calls := map[string]int{}
+
+	m := mesa.MethodBenchmarkMesa[mesa.Empty, mesa.Empty, string, int]{
+		NewInstance: func(ctx *mesa.Ctx, _ mesa.Empty) mesa.Empty {
+			return nil
+		},
+		// Called before every round of b.N, so the calls of the last round are counted.
+		BeforeCall: func(ctx *mesa.Ctx, _ mesa.Empty, name string) {
+			calls[name] = 0
+		},
+		Target: func(ctx *mesa.Ctx, _ mesa.Empty, name string) int {
+			calls[name]++
+			return calls[name]
+		},
+		Cases: []mesa.MethodBenchmarkCase[mesa.Empty, mesa.Empty, string, int]{
+			{Name: "Unweighted", Input: "unweighted"},
+			{Name: "Light", Input: "light", Weight: 0.1},
+			{Name: "Heavy", Input: "heavy", Weight: 2.5},
+			{Name: "Tiny", Input: "tiny", Weight: 0.0001},
+		},
+	}
+
+	testing.Benchmark(m.Run)
+
+	assert.Equal(t, map[string]int{"unweighted": 100, "light": 10, "heavy": 250, "tiny": 1}, calls)
+}
+
+func TestMethodBenchmarkCase_Weight_NsPerOp(t *testing.T) {
+	setBenchtime(t, "100x")
+
+	path := filepath.Join(t.TempDir(), "baseline.json")
+
+	m := mesa.MethodBenchmarkMesa[mesa.Empty, mesa.Empty, mesa.Empty, mesa.Empty]{
+		NewInstance: func(ctx *mesa.Ctx, _ mesa.Empty) mesa.Empty {
+			return nil
+		},
+		Target: func(ctx *mesa.Ctx, _ mesa.Empty, _ mesa.Empty) mesa.Empty {
+			time.Sleep(time.Millisecond)
+			return nil
+		},
+		Cases: []mesa.MethodBenchmarkCase[mesa.Empty, mesa.Empty, mesa.Empty, mesa.Empty]{
+			{Name: "Slow", Weight: 0.05},
+		},
+	}
+
+	testing.Benchmark(func(b *testing.B) {
+		m.RunWithBaseline(b, path, 0)
+	})
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	var baseline map[string]float64
+	require.NoError(t, json.Unmarshal(data, &baseline))
+
+	// The 5 calls of the case take at least a millisecond each, which would be 50µs/op if divided by b.N.
+	assert.GreaterOrEqual(t, baseline["Slow"], float64(time.Millisecond))
+}
+
+func TestMethodBenchmarkCase_BenchmarkSetup(t *testing.T) {
+	setBenchtime(t, "100x")
+
+	var constructed int
+	var fields float64
+
+	m := mesa.MethodBenchmarkMesa[*[]int, int, mesa.Empty, mesa.Empty]{
+		NewInstance: func(ctx *mesa.Ctx, size int) *[]int {
+			constructed++
+			ctx.ReportMetric(float64(size), "fields")
+			s := make([]int, size)
+			return &s
+		},
+		// Called before every round of b.N, after the instance of the case is created.
+		BeforeCall: func(ctx *mesa.Ctx, _ *[]int, _ mesa.Empty) {
+			constructed = 0
+		},
+		Cases: []mesa.MethodBenchmarkCase[*[]int, int, mesa.Empty, mesa.Empty]{
+			{
+				Name:           "Construct",
+				Fields:         8,
+				BenchmarkSetup: true,
+				Check: func(ctx *mesa.Ctx, inst *[]int, _ mesa.Empty, _ mesa.Empty) {
+					assert.Len(t, *inst, 8)
+					fields, _ = ctx.Metric("fields")
+				},
+			},
+		},
+	}
+
+	testing.Benchmark(m.Run)
+
+	assert.Equal(t, 100, constructed)
+	assert.Equal(t, 800.0, fields, "the construction before the timed loop is not counted")
+}
+
+func TestMethodBenchmarkCase_BenchmarkSetup_Cleanup(t *testing.T) {
+	setBenchtime(t, "100x")
+
+	var (
+		mu      sync.Mutex
+		servers []*httptest.Server
+		cleaned int
+	)
+
+	check := func(ctx *mesa.Ctx, inst *httptest.Server, _ mesa.Empty, _ mesa.Empty) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		// Check is called for every round of b.N.
+		assert.Equal(t, ctx.B().N, cleaned, "every instance of the loop is passed to Cleanup")
+		assert.Len(t, servers, ctx.B().N)
+
+		for _, srv := range servers {
+			_, err := net.Dial("tcp", srv.Listener.Addr().String())
+			assert.Error(t, err, "the servers of the loop are closed before the benchmark finishes")
+		}
+
+		conn, err := net.Dial("tcp", inst.Listener.Addr().String())
+		if assert.NoError(t, err, "the server of the case is closed when the benchmark finishes") {
+			conn.Close()
+		}
+	}
+
+	m := mesa.MethodBenchmarkMesa[*httptest.Server, mesa.Empty, mesa.Empty, mesa.Empty]{
+		NewInstance: func(ctx *mesa.Ctx, _ mesa.Empty) *httptest.Server {
+			srv := ctx.StartServer(http.NotFoundHandler())
+
+			mu.Lock()
+			servers = append(servers, srv)
+			mu.Unlock()
+
+			return srv
+		},
+		// Called before every round of b.N, after the instance of the case is created.
+		BeforeCall: func(ctx *mesa.Ctx, _ *httptest.Server, _ mesa.Empty) {
+			mu.Lock()
+			servers, cleaned = nil, 0
+			mu.Unlock()
+		},
+		Cleanup: func(ctx *mesa.Ctx, _ *httptest.Server) {
+			mu.Lock()
+			cleaned++
+			mu.Unlock()
+		},
+		Cases: []mesa.MethodBenchmarkCase[*httptest.Server, mesa.Empty, mesa.Empty, mesa.Empty]{
+			{Name: "Sequential", BenchmarkSetup: true, Check: check},
+			{Name: "Parallel", BenchmarkSetup: true, Parallel: true, Check: check},
+		},
+	}
+
+	testing.Benchmark(m.Run)
+}
@@ -0,0 +1,70 @@
+package mesa
+
+import (
+	"fmt"
+	"strings"
+)
+
+// maxDerivedName is the number of characters that names derived from the input of a case are truncated to.
+const maxDerivedName = 64
+
+// sanitizeName replaces spaces and slashes in a subtest name with underscores so that the name can be matched by a
+// single element of a -run filter.
+func sanitizeName(name string) string {
+	return strings.NewReplacer(" ", "_", "/", "_").Replace(name)
+}
+
+// deriveName returns the name of a case formatted from its input with %v, or with %#v when that is empty, e.g. for an
+// empty string.
+func deriveName(in any) string {
+	name := fmt.Sprint(in)
+	if name == "" {
+		name = fmt.Sprintf("%#v", in)
+	}
+
+	return sanitizeName(truncate(name, maxDerivedName))
+}
+
+// nameInput returns the input that the name of a case is computed from. It is the Input of the case, or DefaultInput
+// when the case would get it, since InputFn and DefaultInputFn need the instance and are only resolved in the subtest.
+func (m MethodMesa[Inst, F, I, O]) nameInput(c MethodCase[Inst, F, I, O]) I {
+	if c.InputFn == nil && m.DefaultInputFn == nil && isZero(c.Input) {
+		return m.DefaultInput
+	}
+
+	return c.Input
+}
+
+// deriveNames returns a copy of the cases where the cases without a Name or a NameFn are named after their input. The
+// index of the case is appended to a derived name that is already taken, so the names don't depend on the order the
+// cases run in.
+func (m MethodMesa[Inst, F, I, O]) deriveNames() []MethodCase[Inst, F, I, O] {
+	derive := func(c MethodCase[Inst, F, I, O]) bool {
+		return c.Name == "" && c.NameFn == nil && m.NameFn == nil
+	}
+
+	taken := make(map[string]bool, len(m.Cases))
+	for _, c := range m.Cases {
+		if !derive(c) {
+			taken[c.Name] = true
+		}
+	}
+
+	cases := append([]MethodCase[Inst, F, I, O](nil), m.Cases...)
+
+	for i, c := range cases {
+		if !derive(c) {
+			continue
+		}
+
+		name := deriveName(m.nameInput(c))
+		if taken[name] {
+			name = fmt.Sprintf("%s_%d", name, i)
+		}
+
+		taken[name] = true
+		cases[i].Name = name
+	}
+
+	return cases
+}
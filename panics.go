@@ -0,0 +1,65 @@
+package mesa
+
+import (
+	"runtime"
+	"runtime/debug"
+	"strings"
+)
+
+// recovered is a panic recovered from the target, along with where it originated.
+type recovered struct {
+	value  any
+	origin string
+	stack  []byte
+}
+
+// callRecover calls fn and recovers a panic, if any. The origin and the stack of the panic are captured when it is
+// recovered, while the panicking frames are still on the stack.
+func callRecover[O any](fn func() O) (out O, r *recovered) {
+	defer func() {
+		if v := recover(); v != nil {
+			r = &recovered{value: v, origin: panicOrigin(), stack: debug.Stack()}
+		}
+	}()
+
+	return fn(), nil
+}
+
+// panicOrigin returns the name of the function that panicked. It must be called by the deferred function that
+// recovers the panic. Frames of the runtime between the panic and the function, such as those of a nil dereference,
+// are skipped.
+func panicOrigin() string {
+	pcs := make([]uintptr, 64)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(1, pcs)])
+
+	panicking := false
+
+	for {
+		frame, more := frames.Next()
+
+		switch {
+		case frame.Function == "runtime.gopanic":
+			panicking = true
+		case panicking && !strings.HasPrefix(frame.Function, "runtime."):
+			return frame.Function
+		}
+
+		if !more {
+			return ""
+		}
+	}
+}
+
+// checkPanic asserts that the target panicked, and that the panic originated from a function whose name contains
+// from when it is not empty.
+func checkPanic(ctx *Ctx, r *recovered, from string) {
+	if r == nil {
+		ctx.As.Fail(ctx.name() + ": expected the target to panic")
+		return
+	}
+
+	if from != "" && !strings.Contains(r.origin, from) {
+		ctx.As.Failf(ctx.name()+": panic did not originate from "+from,
+			"the panic %v originated from %s\n%s", r.value, r.origin, r.stack)
+	}
+}
@@ -0,0 +1,77 @@
+package mesa_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/a20r/mesa"
+)
+
+type port struct {
+	number int
+}
+
+// mustValidPort panics on purpose when the port is out of range.
+func mustValidPort(n int) {
+	if n <= 0 || n > 65535 {
+		panic(fmt.Sprintf("invalid port %d", n))
+	}
+}
+
+// parsePort validates the port, then dereferences p, which panics when it is nil.
+func parsePort(n int, p *port) int {
+	mustValidPort(n)
+	p.number = n
+
+	return p.number
+}
+
+func TestExpectPanic(t *testing.T) {
+	m := mesa.FunctionMesa[int, int]{
+		RequireAssertions: true,
+		Target: func(ctx *mesa.Ctx, n int) int {
+			return parsePort(n, &port{})
+		},
+		Check: func(ctx *mesa.Ctx, n int, out int) {},
+		Cases: []mesa.FunctionCase[int, int]{
+			{Name: "Any panic", Input: -1, ExpectPanic: true},
+			{Name: "From the validation", Input: 70000, ExpectPanicFrom: "mesa_test.mustValidPort"},
+		},
+	}
+
+	m.Run(t)
+}
+
+func TestExpectPanic_NoPanic(t *testing.T) {
+	expectFailure(t, func(t *testing.T) {
+		m := mesa.FunctionMesa[int, int]{
+			Target: func(ctx *mesa.Ctx, n int) int {
+				return parsePort(n, &port{})
+			},
+			Cases: []mesa.FunctionCase[int, int]{
+				{Name: "Valid port", Input: 8080, ExpectPanic: true},
+			},
+		}
+
+		m.Run(t)
+	}, "Valid_port: expected the target to panic")
+}
+
+func TestExpectPanicFrom_OtherOrigin(t *testing.T) {
+	expectFailure(t, func(t *testing.T) {
+		m := mesa.FunctionMesa[int, int]{
+			Target: func(ctx *mesa.Ctx, n int) int {
+				return parsePort(n, nil)
+			},
+			Cases: []mesa.FunctionCase[int, int]{
+				{Name: "Nil dereference", Input: 8080, ExpectPanicFrom: "mesa_test.mustValidPort"},
+			},
+		}
+
+		m.Run(t)
+	},
+		"panic did not originate from mesa_test.mustValidPort",
+		"originated from github.com/a20r/mesa_test.parsePort",
+		"invalid memory address or nil pointer dereference",
+	)
+}
@@ -0,0 +1,56 @@
+package mesa
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// casePatterns holds the compiled regular expressions of a case, so that every pattern of a case is compiled before
+// the suite runs.
+type casePatterns struct {
+	err    errExpectation
+	output outputPattern
+}
+
+// outputPattern holds the compiled ExpectedRegex of a case.
+type outputPattern struct {
+	re *regexp.Regexp
+}
+
+// newOutputPattern compiles the ExpectedRegex of a case, if any.
+func newOutputPattern(pattern string) (outputPattern, error) {
+	if pattern == "" {
+		return outputPattern{}, nil
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return outputPattern{}, err
+	}
+
+	return outputPattern{re: re}, nil
+}
+
+// check asserts that out, which must be a string, a byte slice or a fmt.Stringer, matches the pattern. It does nothing
+// if the case has no ExpectedRegex.
+func (p outputPattern) check(ctx *Ctx, out any) {
+	if p.re == nil {
+		return
+	}
+
+	var s string
+
+	switch v := out.(type) {
+	case string:
+		s = v
+	case []byte:
+		s = string(v)
+	case fmt.Stringer:
+		s = v.String()
+	default:
+		ctx.As.Failf("invalid output type", "ExpectedRegex requires a string output, got %T", out)
+		return
+	}
+
+	ctx.As.Regexp(p.re, s, "%s: output doesn't match ExpectedRegex", ctx.name())
+}
@@ -0,0 +1,86 @@
+package mesa
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/pprof"
+	"strings"
+)
+
+// profiler writes the CPU and heap profiles of a single benchmark case to dir.
+type profiler struct {
+	ctx     *Ctx
+	dir     string
+	name    string
+	cpu     bool
+	mem     bool
+	cpuFile *os.File
+}
+
+// newProfiler returns a profiler for the case called name. Slashes and spaces in the name are replaced with
+// underscores so that every case gets a single file in dir.
+func newProfiler(ctx *Ctx, dir, name string, cpu, mem bool) *profiler {
+	name = strings.NewReplacer("/", "_", " ", "_").Replace(name)
+	return &profiler{ctx: ctx, dir: dir, name: name, cpu: cpu, mem: mem}
+}
+
+// path returns the path of the profile file with the given kind, e.g. cpu.
+func (p *profiler) path(kind string) string {
+	return filepath.Join(p.dir, p.name+"."+kind+".prof")
+}
+
+// start starts the CPU profile of the case. It fails the case if the profile can't be started, e.g. because the
+// benchmark is run with -cpuprofile which profiles the whole binary.
+func (p *profiler) start() {
+	if !p.cpu {
+		return
+	}
+
+	p.ctx.Re.NoError(os.MkdirAll(p.dir, 0o755), "failed to create profile directory %s", p.dir)
+
+	f, err := os.Create(p.path("cpu"))
+	p.ctx.Re.NoError(err, "failed to create CPU profile")
+
+	if err != nil {
+		return
+	}
+
+	if err := pprof.StartCPUProfile(f); err != nil {
+		f.Close()
+		p.ctx.Re.NoError(err, "failed to start CPU profile, it can't be combined with -cpuprofile")
+
+		return
+	}
+
+	p.cpuFile = f
+}
+
+// stop stops the CPU profile of the case and writes its heap profile.
+func (p *profiler) stop() {
+	if p.cpuFile != nil {
+		pprof.StopCPUProfile()
+		p.ctx.Re.NoError(p.cpuFile.Close(), "failed to write CPU profile")
+		p.cpuFile = nil
+	}
+
+	if !p.mem {
+		return
+	}
+
+	p.ctx.Re.NoError(os.MkdirAll(p.dir, 0o755), "failed to create profile directory %s", p.dir)
+
+	f, err := os.Create(p.path("mem"))
+	p.ctx.Re.NoError(err, "failed to create heap profile")
+
+	if err != nil {
+		return
+	}
+
+	defer f.Close()
+
+	// Collect garbage so that the profile reflects the allocations of the case that are still live.
+	runtime.GC()
+
+	p.ctx.Re.NoError(pprof.WriteHeapProfile(f), "failed to write heap profile")
+}
@@ -0,0 +1,67 @@
+package mesa_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/a20r/mesa"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMethodBenchmarkMesa_Profile(t *testing.T) {
+	setBenchtime(t, "100x")
+
+	dir := t.TempDir()
+
+	m := mesa.MethodBenchmarkMesa[mesa.Empty, mesa.Empty, int, []int]{
+		ProfileDir: dir,
+		CPUProfile: true,
+		MemProfile: true,
+		NewInstance: func(ctx *mesa.Ctx, _ mesa.Empty) mesa.Empty {
+			return nil
+		},
+		Target: func(ctx *mesa.Ctx, _ mesa.Empty, n int) []int {
+			return make([]int, n)
+		},
+		Cases: []mesa.MethodBenchmarkCase[mesa.Empty, mesa.Empty, int, []int]{
+			{Name: "Small slice", Input: 16},
+			{Name: "Large/slice", Input: 4096},
+		},
+	}
+
+	testing.Benchmark(m.Run)
+
+	for _, name := range []string{
+		"Small_slice.cpu.prof", "Small_slice.mem.prof",
+		"Large_slice.cpu.prof", "Large_slice.mem.prof",
+	} {
+		info, err := os.Stat(filepath.Join(dir, name))
+		if assert.NoError(t, err) {
+			assert.NotZero(t, info.Size(), name)
+		}
+	}
+}
+
+func TestMethodBenchmarkMesa_NoProfile(t *testing.T) {
+	setBenchtime(t, "100x")
+
+	dir := filepath.Join(t.TempDir(), "profiles")
+
+	m := mesa.MethodBenchmarkMesa[mesa.Empty, mesa.Empty, int, int]{
+		ProfileDir: dir,
+		NewInstance: func(ctx *mesa.Ctx, _ mesa.Empty) mesa.Empty {
+			return nil
+		},
+		Target: func(ctx *mesa.Ctx, _ mesa.Empty, n int) int {
+			return n
+		},
+		Cases: []mesa.MethodBenchmarkCase[mesa.Empty, mesa.Empty, int, int]{
+			{Name: "Identity", Input: 1},
+		},
+	}
+
+	testing.Benchmark(m.Run)
+
+	assert.NoDirExists(t, dir)
+}
@@ -0,0 +1,27 @@
+package mesa
+
+import "sync"
+
+// progress counts the cases of a suite that completed and reports them to the Progress function of the suite.
+type progress struct {
+	mu    sync.Mutex
+	fn    func(done, total int)
+	done  int
+	total int
+}
+
+// caseDone counts a completed case and reports it. Reports are serialized, so fn is never called concurrently and
+// done increases by one on every call, even when cases run in parallel.
+func (p *progress) caseDone() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.done++
+	p.fn(p.done, p.total)
+}
+
+// selected reports whether the case is selected to run, i.e. it is not excluded by Filter, skipped or missing a
+// RequireEnv variable.
+func (m MethodMesa[Inst, F, I, O]) selected(c MethodCase[Inst, F, I, O]) bool {
+	return (m.Filter == nil || m.Filter(m.caseMeta(c))) && c.Skip == "" && !missingEnvVar(c.RequireEnv)
+}
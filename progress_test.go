@@ -0,0 +1,61 @@
+package mesa_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/a20r/mesa"
+)
+
+func TestProgress(t *testing.T) {
+	var reports []string
+
+	m := mesa.FunctionMesa[int, int]{
+		Target: func(ctx *mesa.Ctx, in int) int {
+			return in
+		},
+		Filter: func(c mesa.CaseMeta) bool {
+			return c.Name != "Filtered"
+		},
+		Progress: func(done, total int) {
+			reports = append(reports, fmt.Sprintf("%d/%d", done, total))
+		},
+		Cases: []mesa.FunctionCase[int, int]{
+			{Name: "One", Input: 1, Expected: 1},
+			{Name: "Filtered", Input: 2},
+			{Name: "Skipped", Input: 3, Skip: "not today"},
+			{Name: "Missing env", Input: 4, RequireEnv: []string{"MESA_TEST_PROGRESS_UNSET"}},
+			{Name: "Two", Input: 5, Expected: 5},
+			{Name: "Three", Input: 6, Expected: 6},
+		},
+	}
+
+	m.Run(t)
+
+	assert.Equal(t, []string{"1/3", "2/3", "3/3"}, reports)
+}
+
+func TestProgress_Parallel(t *testing.T) {
+	var dones []int
+
+	m := mesa.FunctionMesa[int, int]{
+		Target: func(ctx *mesa.Ctx, in int) int {
+			ctx.T().Parallel()
+			return in
+		},
+		Progress: func(done, total int) {
+			dones = append(dones, done)
+			assert.Equal(t, 8, total)
+		},
+	}
+
+	for i := 0; i < 8; i++ {
+		m.Cases = append(m.Cases, mesa.FunctionCase[int, int]{Name: fmt.Sprint("Case ", i), Input: i})
+	}
+
+	t.Run("suite", m.Run)
+
+	assert.Equal(t, []int{1, 2, 3, 4, 5, 6, 7, 8}, dones)
+}
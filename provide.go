@@ -0,0 +1,45 @@
+package mesa
+
+import "fmt"
+
+// Provide registers value under key, e.g. a test double that NewInstance resolves with Resolve. Values provided on
+// the context of a case are only visible to that case, while values provided on the context of the suite, e.g. in
+// Init, are shared by every case.
+func (c *Ctx) Provide(key, value any) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.deps == nil {
+		c.deps = make(map[any]any)
+	}
+
+	c.deps[key] = value
+}
+
+// lookup returns the value provided under key on the context, falling back to the context of the suite.
+func (c *Ctx) lookup(key any) (any, bool) {
+	c.mu.Lock()
+	v, ok := c.deps[key]
+	c.mu.Unlock()
+
+	if !ok && c.suite != nil {
+		return c.suite.lookup(key)
+	}
+
+	return v, ok
+}
+
+// Resolve returns the value provided under key with Ctx.Provide as a T. The case stops if no value was provided
+// under key or if it is not a T.
+func Resolve[T any](ctx *Ctx, key any) T {
+	v, ok := ctx.lookup(key)
+	if !ok {
+		ctx.Re.FailNow(fmt.Sprintf("%s: no value provided for key %v", ctx.name(), key))
+		return *new(T)
+	}
+
+	val, ok := v.(T)
+	ctx.Re.Truef(ok, "%s: value provided for key %v has type %T, not %T", ctx.name(), key, v, *new(T))
+
+	return val
+}
@@ -0,0 +1,73 @@
+package mesa_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/a20r/mesa"
+	"github.com/stretchr/testify/assert"
+)
+
+type salutation struct {
+	greeting string
+}
+
+func (s salutation) Greet(name string) string {
+	return s.greeting + " " + name
+}
+
+func TestResolve(t *testing.T) {
+	m := mesa.MethodMesa[salutation, mesa.Empty, string, string]{
+		Init: func(ctx *mesa.Ctx) {
+			ctx.Provide("greeting", "hello")
+		},
+		NewInstance: func(ctx *mesa.Ctx, _ mesa.Empty) salutation {
+			return salutation{greeting: mesa.Resolve[string](ctx, "greeting")}
+		},
+		Target: func(ctx *mesa.Ctx, inst salutation, in string) string {
+			return inst.Greet(in)
+		},
+		Cases: []mesa.MethodCase[salutation, mesa.Empty, string, string]{
+			{
+				Name:     "Provided by the suite",
+				Input:    "ada",
+				Expected: "hello ada",
+			},
+			{
+				Name: "Provided by the case",
+				FieldsFn: func(ctx *mesa.Ctx) mesa.Empty {
+					ctx.Provide("greeting", "hi")
+					return nil
+				},
+				Input:    "grace",
+				Expected: "hi grace",
+			},
+			{
+				Name:     "Not affected by other cases",
+				Input:    "alan",
+				Expected: "hello alan",
+			},
+		},
+	}
+
+	m.Run(t)
+}
+
+func TestResolve_Failures(t *testing.T) {
+	r := record(func(ctx *mesa.Ctx) {
+		mesa.Resolve[string](ctx, "missing")
+		t.Error("Resolve should stop the case")
+	})
+
+	assert.True(t, r.failed)
+	assert.Contains(t, strings.Join(r.errors, "\n"), "no value provided for key missing")
+
+	r = record(func(ctx *mesa.Ctx) {
+		ctx.Provide("port", 8080)
+		mesa.Resolve[string](ctx, "port")
+		t.Error("Resolve should stop the case")
+	})
+
+	assert.True(t, r.failed)
+	assert.Contains(t, strings.Join(r.errors, "\n"), "value provided for key port has type int, not string")
+}
@@ -0,0 +1,120 @@
+package mesa
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// regression is an entry of a regressions file.
+type regression struct {
+	Name  string          `json:"name"`
+	Input json.RawMessage `json:"input"`
+}
+
+// regressionsMu serializes writes to regressions files across cases.
+var regressionsMu sync.Mutex
+
+// regressionPath returns the regressions file of the suite run by the test with the given name.
+func regressionPath(dir, suite string) string {
+	if dir == "" {
+		dir = filepath.Join("testdata", "regressions")
+	}
+
+	return filepath.Join(dir, filepath.FromSlash(suite)+".json")
+}
+
+// recordRegression appends the input of the failed case to the regressions file at path, unless the file already
+// contains the same input.
+func recordRegression(path, name string, in any) error {
+	data, err := json.Marshal(in)
+	if err != nil {
+		return fmt.Errorf("failed to marshal input to JSON: %w", err)
+	}
+
+	regressionsMu.Lock()
+	defer regressionsMu.Unlock()
+
+	entries, err := readRegressions(path)
+	if err != nil {
+		return err
+	}
+
+	for _, e := range entries {
+		if bytes.Equal(e.Input, data) {
+			return nil
+		}
+	}
+
+	entries = append(entries, regression{Name: name, Input: data})
+
+	out, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, append(out, '\n'), 0o644)
+}
+
+// readRegressions reads the entries of the regressions file at path. A missing file has no entries.
+func readRegressions(path string) ([]regression, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var entries []regression
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse regressions file %s: %w", path, err)
+	}
+
+	return entries, nil
+}
+
+// CasesFromRegressions loads the inputs recorded by a suite with RecordRegressions as explicit cases. Every case
+// shares check, and no cases are returned if the file does not exist. Together they lock failing inputs, e.g. from
+// randomly generated cases, in as permanent regression cases:
+//
+//  1. Set RecordRegressions on the suite. The input of every failing case is written to
+//     testdata/regressions/<test name>.json.
+//  2. Append CasesFromRegressions(path, check) to the cases of the suite and commit the file.
+//  3. Fix the bug. The recorded cases keep guarding against it on every run.
+//
+// It panics if the file cannot be read or parsed since that is a mistake in the test definition rather than a test
+// failure.
+func CasesFromRegressions[InputType, OutputType any](
+	path string,
+	check func(ctx *Ctx, in InputType, out OutputType),
+) []FunctionCase[InputType, OutputType] {
+	entries, err := readRegressions(path)
+	if err != nil {
+		panic("mesa: " + err.Error())
+	}
+
+	cases := make([]FunctionCase[InputType, OutputType], len(entries))
+	for i, e := range entries {
+		var in InputType
+		if err := json.Unmarshal(e.Input, &in); err != nil {
+			panic(fmt.Sprintf("mesa: failed to parse input of regression %q: %v", e.Name, err))
+		}
+
+		cases[i] = FunctionCase[InputType, OutputType]{
+			Name:  "regression " + e.Name,
+			Input: in,
+			Check: check,
+		}
+	}
+
+	return cases
+}
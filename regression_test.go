@@ -0,0 +1,77 @@
+package mesa_test
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/a20r/mesa"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const regressionDirEnv = "MESA_REGRESSION_DIR"
+
+func TestRecordRegressions(t *testing.T) {
+	// The directory is shared with the child process through the environment.
+	dir := os.Getenv(regressionDirEnv)
+	if dir == "" {
+		dir = t.TempDir()
+		t.Setenv(regressionDirEnv, dir)
+	}
+
+	isEven := func(ctx *mesa.Ctx, in int) bool {
+		return in%2 == 0
+	}
+
+	out, ok, err := runInSubprocess(t, func(t *testing.T) {
+		m := mesa.FunctionMesa[int, bool]{
+			RecordRegressions: true,
+			RegressionDir:     dir,
+			Target:            isEven,
+			Check: func(ctx *mesa.Ctx, in int, out bool) {
+				ctx.As.True(out, "%d is not even", in)
+			},
+			Cases: []mesa.FunctionCase[int, bool]{
+				{Name: "Two", Input: 2},
+				{Name: "Three", Input: 3},
+				{Name: "Five", Input: 5},
+				{Name: "Five again", Input: 5},
+			},
+		}
+
+		m.Run(t)
+	})
+	if !ok {
+		return
+	}
+
+	require.Error(t, err, "expected test to fail:\n%s", out)
+
+	path := filepath.Join(dir, "TestRecordRegressions.json")
+	cases := mesa.CasesFromRegressions(path, func(ctx *mesa.Ctx, in int, out bool) {
+		ctx.As.False(out)
+	})
+
+	assert.Contains(t, out, "recorded input in "+path)
+
+	var names []string
+	for _, c := range cases {
+		names = append(names, c.Name+"="+strconv.Itoa(c.Input))
+	}
+
+	assert.Equal(t, []string{"regression Three=3", "regression Five=5"}, names)
+
+	m := mesa.FunctionMesa[int, bool]{
+		Target: isEven,
+		Cases:  cases,
+	}
+
+	m.Run(t)
+}
+
+func TestCasesFromRegressions_Missing(t *testing.T) {
+	cases := mesa.CasesFromRegressions[int, bool](filepath.Join(t.TempDir(), "missing.json"), nil)
+	assert.Empty(t, cases)
+}
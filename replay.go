@@ -0,0 +1,150 @@
+package mesa
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// replay is the content of a replay file: the seed of a run and the names of its cases in the order they ran.
+type replay struct {
+	Seed  int64    `json:"seed"`
+	Order []string `json:"order"`
+}
+
+// RunReplay runs the cases with the seed and in the order recorded in the replay file at path, which is written by a
+// run of the suite with ReplayFile set. Committing the replay file of a failing run makes it reproducible, including
+// the order of shuffled cases and the values of ctx.Rand:
+//
+//  1. Set ReplayFile on the suite and run it until it fails.
+//  2. Call RunReplay with the written file instead of Run to rerun exactly that configuration while debugging.
+//
+// Every case of the suite must be in the replay file and the other way around, so cases are matched by Name.
+func (m MethodMesa[Inst, F, I, O]) RunReplay(t *testing.T, path string) {
+	if err := m.Validate(); err != nil {
+		t.Fatalf("invalid mesa: %v", err)
+	}
+
+	r, err := readReplay(path)
+	if err != nil {
+		t.Fatalf("failed to read replay file %s: %v", path, err)
+	}
+
+	cases, err := orderCases(m.deriveNames(), r.Order, func(c MethodCase[Inst, F, I, O]) string { return c.Name })
+	if err != nil {
+		t.Fatalf("replay file %s does not match the suite: %v", path, err)
+	}
+
+	m.Cases = cases
+	m.Seed = r.Seed
+	m.Shuffle = false
+	m.SortCases = nil
+	m.ReplayFile = ""
+	m.run(t)
+}
+
+// RunReplay runs the cases with the seed and in the order recorded in the replay file at path. See
+// MethodMesa.RunReplay.
+func (m FunctionMesa[I, O]) RunReplay(t *testing.T, path string) {
+	m.method().RunReplay(t, path)
+}
+
+// orderCases returns the cases in the order of names. Cases sharing a name are matched in their original order.
+func orderCases[C any](cases []C, names []string, name func(C) string) ([]C, error) {
+	byName := make(map[string][]C)
+	for _, c := range cases {
+		byName[name(c)] = append(byName[name(c)], c)
+	}
+
+	ordered := make([]C, 0, len(cases))
+
+	for _, n := range names {
+		matches := byName[n]
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("case %q is not in the suite", n)
+		}
+
+		ordered = append(ordered, matches[0])
+		byName[n] = matches[1:]
+	}
+
+	for _, c := range cases {
+		if len(byName[name(c)]) > 0 {
+			return nil, fmt.Errorf("case %q is not in the replay file", name(c))
+		}
+	}
+
+	return ordered, nil
+}
+
+// shuffleCases shuffles the cases with the given seed.
+func shuffleCases[C any](cases []C, seed int64) []C {
+	shuffled := append([]C(nil), cases...)
+	rand.New(rand.NewSource(seed)).Shuffle(len(shuffled), func(i, j int) {
+		shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+	})
+
+	return shuffled
+}
+
+// newSeed returns seed, or a seed based on the current time when it is zero.
+func newSeed(seed int64) int64 {
+	if seed != 0 {
+		return seed
+	}
+
+	return time.Now().UnixNano()
+}
+
+// writeReplay writes the seed and the order of the cases to the replay file at path.
+func writeReplay(path string, r replay) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// readReplay reads the replay file at path.
+func readReplay(path string) (replay, error) {
+	var r replay
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return r, err
+	}
+
+	return r, json.Unmarshal(data, &r)
+}
+
+// Rand returns a source of random numbers for the case, e.g. to generate inputs in InputFn. It is seeded from the seed
+// of the suite and the name of the case, so it returns the same values when the suite runs with the same Seed or is
+// replayed with RunReplay, whatever the order of the cases. It is not safe for concurrent use.
+func (c *Ctx) Rand() *rand.Rand {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.rand == nil {
+		var seed int64
+		if c.suite != nil {
+			seed = c.suite.seed
+		}
+
+		h := fnv.New64a()
+		h.Write([]byte(c.caseName))
+
+		c.rand = rand.New(rand.NewSource(seed ^ int64(h.Sum64())))
+	}
+
+	return c.rand
+}
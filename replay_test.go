@@ -0,0 +1,109 @@
+package mesa_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/a20r/mesa"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// randomSuite returns a suite whose target records the name of every case along with a value from ctx.Rand.
+func randomSuite(order *[]string, values map[string]int) mesa.FunctionMesa[string, mesa.Empty] {
+	cases := make([]mesa.FunctionCase[string, mesa.Empty], 0, 8)
+	for _, name := range []string{"a", "b", "c", "d", "e", "f", "g", "h"} {
+		cases = append(cases, mesa.FunctionCase[string, mesa.Empty]{Name: name, Input: name})
+	}
+
+	return mesa.FunctionMesa[string, mesa.Empty]{
+		Target: func(ctx *mesa.Ctx, in string) mesa.Empty {
+			*order = append(*order, in)
+			values[in] = ctx.Rand().Int()
+
+			return nil
+		},
+		Cases: cases,
+	}
+}
+
+func TestFunctionMesa_Shuffle(t *testing.T) {
+	var first, second []string
+
+	m := randomSuite(&first, map[string]int{})
+	m.Shuffle = true
+	m.Seed = 42
+	m.Run(t)
+
+	m = randomSuite(&second, map[string]int{})
+	m.Shuffle = true
+	m.Seed = 42
+	m.Run(t)
+
+	assert.ElementsMatch(t, []string{"a", "b", "c", "d", "e", "f", "g", "h"}, first)
+	assert.NotEqual(t, []string{"a", "b", "c", "d", "e", "f", "g", "h"}, first)
+	assert.Equal(t, first, second, "the same seed gives the same order")
+}
+
+func TestFunctionMesa_RunReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "replays", "suite.json")
+
+	var (
+		order  []string
+		values = map[string]int{}
+	)
+
+	m := randomSuite(&order, values)
+	m.Shuffle = true
+	m.ReplayFile = path
+	m.Run(t)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	var written struct {
+		Seed  int64    `json:"seed"`
+		Order []string `json:"order"`
+	}
+
+	require.NoError(t, json.Unmarshal(data, &written))
+	assert.NotZero(t, written.Seed)
+	assert.Equal(t, order, written.Order)
+
+	var (
+		replayedOrder  []string
+		replayedValues = map[string]int{}
+	)
+
+	m = randomSuite(&replayedOrder, replayedValues)
+	m.Shuffle = true
+	m.RunReplay(t, path)
+
+	assert.Equal(t, order, replayedOrder)
+	assert.Equal(t, values, replayedValues)
+}
+
+func TestFunctionMesa_RunReplay_Mismatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "replay.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"seed": 1, "order": ["a", "b", "z"]}`), 0o644))
+
+	expectFailure(t, func(t *testing.T) {
+		var order []string
+
+		randomSuite(&order, map[string]int{}).RunReplay(t, path)
+	}, `does not match the suite: case "z" is not in the suite`)
+}
+
+func TestFunctionMesa_Validate_Shuffle(t *testing.T) {
+	m := mesa.FunctionMesa[int, int]{
+		Shuffle: true,
+		SortCases: func(a, b mesa.CaseMeta) bool {
+			return a.Name < b.Name
+		},
+		Cases: []mesa.FunctionCase[int, int]{{Name: "A"}},
+	}
+
+	assert.EqualError(t, m.Validate(), "Shuffle and SortCases can't be combined")
+}
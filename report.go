@@ -0,0 +1,159 @@
+package mesa
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"sort"
+	"strconv"
+	"testing"
+	"unicode/utf8"
+)
+
+// MetricsFormat is the format of the metrics written by RunWithMetrics.
+type MetricsFormat int
+
+const (
+	// MetricsCSV writes a header row followed by one row per case. The custom metrics of every case are columns
+	// after the builtin ones, sorted by name, and are empty for cases that didn't report them.
+	MetricsCSV MetricsFormat = iota
+
+	// MetricsJSON writes an indented JSON array with one object per case.
+	MetricsJSON
+)
+
+// maxInputLen is the length above which the input of a case is truncated in the metrics written by RunWithMetrics.
+const maxInputLen = 80
+
+// caseMetrics is the metrics of a benchmark case written by RunWithMetrics.
+type caseMetrics struct {
+	Name        string             `json:"name"`
+	Input       string             `json:"input"`
+	N           int                `json:"n"`
+	NsPerOp     float64            `json:"ns_per_op"`
+	AllocsPerOp float64            `json:"allocs_per_op"`
+	BytesPerOp  float64            `json:"bytes_per_op"`
+	Metrics     map[string]float64 `json:"metrics,omitempty"`
+}
+
+// RunWithMetrics runs the benchmark cases, then writes the metrics of each case to w in the given format, e.g. to
+// a file uploaded as a CI artifact for dashboards. The metrics of a case are its name, its input rendered like
+// DocExamples and truncated to 80 characters, the number of iterations, its ns/op, allocs/op and B/op, and its custom
+// metrics as reported to b.ReportMetric, i.e. after they are aggregated. Only the last round of b.N of each case is
+// written. Allocations are always measured, even without -benchmem.
+func (m MethodBenchmarkMesa[Inst, F, I, O]) RunWithMetrics(b *testing.B, w io.Writer, format MetricsFormat) {
+	var (
+		names   []string
+		results = make(map[string]caseMetrics)
+	)
+
+	m.run(b, func(name string, r benchResult) {
+		if _, ok := results[name]; !ok {
+			names = append(names, name)
+		}
+
+		c := caseMetrics{
+			Name:        name,
+			Input:       truncate(formatDoc(nil, r.input), maxInputLen),
+			N:           r.n,
+			NsPerOp:     r.nsPerOp,
+			AllocsPerOp: r.allocsPerOp,
+			BytesPerOp:  r.bytesPerOp,
+		}
+
+		if len(r.metrics) > 0 {
+			c.Metrics = make(map[string]float64, len(r.metrics))
+			for _, rm := range r.metrics {
+				c.Metrics[rm.name] = rm.value
+			}
+		}
+
+		results[name] = c
+	})
+
+	cases := make([]caseMetrics, len(names))
+	for i, name := range names {
+		cases[i] = results[name]
+	}
+
+	var err error
+
+	switch format {
+	case MetricsJSON:
+		err = writeMetricsJSON(w, cases)
+	default:
+		err = writeMetricsCSV(w, cases)
+	}
+
+	if err != nil {
+		b.Fatalf("failed to write benchmark metrics: %v", err)
+	}
+}
+
+// writeMetricsJSON writes the metrics of the cases as an indented JSON array.
+func writeMetricsJSON(w io.Writer, cases []caseMetrics) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(cases)
+}
+
+// writeMetricsCSV writes the metrics of the cases as CSV with a header row.
+func writeMetricsCSV(w io.Writer, cases []caseMetrics) error {
+	seen := make(map[string]bool)
+
+	var custom []string
+
+	for _, c := range cases {
+		for name := range c.Metrics {
+			if !seen[name] {
+				seen[name] = true
+				custom = append(custom, name)
+			}
+		}
+	}
+
+	sort.Strings(custom)
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write(append([]string{"name", "input", "n", "ns/op", "allocs/op", "B/op"}, custom...)); err != nil {
+		return err
+	}
+
+	for _, c := range cases {
+		row := []string{c.Name, c.Input, strconv.Itoa(c.N), formatFloat(c.NsPerOp), formatFloat(c.AllocsPerOp),
+			formatFloat(c.BytesPerOp)}
+
+		for _, name := range custom {
+			v, ok := c.Metrics[name]
+			if !ok {
+				row = append(row, "")
+				continue
+			}
+
+			row = append(row, formatFloat(v))
+		}
+
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+
+	return cw.Error()
+}
+
+// formatFloat formats v with the fewest digits that represent it exactly.
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// truncate shortens s to at most n characters, replacing its end with an ellipsis.
+func truncate(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+
+	return string([]rune(s)[:n-1]) + "…"
+}
@@ -0,0 +1,96 @@
+package mesa_test
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/a20r/mesa"
+)
+
+func metricsBenchmark() mesa.MethodBenchmarkMesa[mesa.Empty, mesa.Empty, string, []string] {
+	return mesa.MethodBenchmarkMesa[mesa.Empty, mesa.Empty, string, []string]{
+		NewInstance: func(ctx *mesa.Ctx, _ mesa.Empty) mesa.Empty {
+			return nil
+		},
+		Target: func(ctx *mesa.Ctx, _ mesa.Empty, in string) []string {
+			fields := strings.Fields(in)
+			ctx.ReportMetric(float64(len(fields)), "fields/op")
+
+			if len(fields) > 2 {
+				ctx.ReportMetricWith(1, "long", "", mesa.AggSum)
+			}
+
+			return fields
+		},
+		Cases: []mesa.MethodBenchmarkCase[mesa.Empty, mesa.Empty, string, []string]{
+			{Name: "Short", Input: "a b"},
+			{Name: "Long", Input: strings.Repeat("word ", 40)},
+		},
+	}
+}
+
+func TestMethodBenchmarkMesa_RunWithMetrics_CSV(t *testing.T) {
+	setBenchtime(t, "100x")
+
+	var buf bytes.Buffer
+
+	testing.Benchmark(func(b *testing.B) {
+		metricsBenchmark().RunWithMetrics(b, &buf, mesa.MetricsCSV)
+	})
+
+	rows, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, rows, 3)
+
+	assert.Equal(t, []string{"name", "input", "n", "ns/op", "allocs/op", "B/op", "fields/op", "long"}, rows[0])
+
+	short, long := rows[1], rows[2]
+	assert.Equal(t, []string{"Short", `"a b"`, "100"}, short[:3])
+	assert.Equal(t, "2", short[6], "custom metrics are written after they are divided by b.N")
+	assert.Equal(t, "", short[7], "metrics that were not reported are empty")
+
+	assert.Equal(t, "Long", long[0])
+	assert.Len(t, []rune(long[1]), 80)
+	assert.True(t, strings.HasSuffix(long[1], "…"))
+	assert.NotEqual(t, "0", long[4], "strings.Fields allocates the slice of fields")
+	assert.Equal(t, "40", long[6])
+	assert.Equal(t, "100", long[7])
+}
+
+func TestMethodBenchmarkMesa_RunWithMetrics_JSON(t *testing.T) {
+	setBenchtime(t, "100x")
+
+	var buf bytes.Buffer
+
+	testing.Benchmark(func(b *testing.B) {
+		metricsBenchmark().RunWithMetrics(b, &buf, mesa.MetricsJSON)
+	})
+
+	var cases []struct {
+		Name        string             `json:"name"`
+		Input       string             `json:"input"`
+		N           int                `json:"n"`
+		NsPerOp     float64            `json:"ns_per_op"`
+		AllocsPerOp float64            `json:"allocs_per_op"`
+		Metrics     map[string]float64 `json:"metrics"`
+	}
+
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &cases))
+	require.Len(t, cases, 2)
+
+	assert.Equal(t, "Short", cases[0].Name)
+	assert.Equal(t, `"a b"`, cases[0].Input)
+	assert.Equal(t, 100, cases[0].N)
+	assert.Positive(t, cases[0].NsPerOp)
+	assert.Equal(t, map[string]float64{"fields/op": 2}, cases[0].Metrics)
+
+	assert.Equal(t, "Long", cases[1].Name)
+	assert.GreaterOrEqual(t, cases[1].AllocsPerOp, 1.0)
+	assert.Equal(t, map[string]float64{"fields/op": 40, "long": 100}, cases[1].Metrics)
+}
@@ -0,0 +1,46 @@
+package mesa
+
+import (
+	"fmt"
+	"sync"
+)
+
+// outputs records the outputs of the cases of a suite by case name so that cases with SameAs can compare to them.
+type outputs struct {
+	mu     sync.Mutex
+	values map[string]any
+}
+
+// set records the output of the case with the given name, replacing the output of a previous run, e.g. a repeat.
+func (o *outputs) set(name string, out any) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.values == nil {
+		o.values = make(map[string]any)
+	}
+
+	o.values[name] = out
+}
+
+// get returns the output recorded for the case with the given name and whether there is one.
+func (o *outputs) get(name string) (any, bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	out, ok := o.values[name]
+
+	return out, ok
+}
+
+// checkSameAs asserts that out is equal to the output recorded for the case called name. The case fails if that case
+// has not run yet, e.g. because it was filtered, skipped or ordered after this one.
+func checkSameAs(ctx *Ctx, outs *outputs, name string, out any) {
+	want, ok := outs.get(name)
+	if !ok {
+		ctx.As.Fail(fmt.Sprintf("%s: SameAs case %q has not run before this case", ctx.name(), name))
+		return
+	}
+
+	ctx.equal(want, out, "%s: output differs from the output of case %q", ctx.name(), name)
+}
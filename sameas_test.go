@@ -0,0 +1,72 @@
+package mesa_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/a20r/mesa"
+)
+
+func TestFunctionCase_SameAs(t *testing.T) {
+	m := mesa.FunctionMesa[string, string]{
+		Target: func(ctx *mesa.Ctx, in string) string {
+			return strings.ToLower(in)
+		},
+		Cases: []mesa.FunctionCase[string, string]{
+			{Name: "Lower", Input: "hello", Expected: "hello"},
+			{Name: "Upper", Input: "HELLO", SameAs: "Lower"},
+			{Name: "Mixed", Input: "HeLlO", SameAs: "Upper"},
+		},
+	}
+
+	m.Run(t)
+}
+
+func TestFunctionCase_SameAs_Differs(t *testing.T) {
+	expectFailure(t, func(t *testing.T) {
+		m := mesa.FunctionMesa[string, string]{
+			Target: func(ctx *mesa.Ctx, in string) string {
+				return strings.ToUpper(in)
+			},
+			Cases: []mesa.FunctionCase[string, string]{
+				{Name: "Hello", Input: "hello"},
+				{Name: "World", Input: "world", SameAs: "Hello"},
+			},
+		}
+
+		m.Run(t)
+	}, `output differs from the output of case "Hello"`, `expected: "HELLO"`, `actual  : "WORLD"`)
+}
+
+func TestFunctionCase_SameAs_NotRun(t *testing.T) {
+	expectFailure(t, func(t *testing.T) {
+		m := mesa.FunctionMesa[string, string]{
+			Target: func(ctx *mesa.Ctx, in string) string {
+				return strings.ToLower(in)
+			},
+			Cases: []mesa.FunctionCase[string, string]{
+				{Name: "Upper", Input: "HELLO", SameAs: "Lower"},
+				{Name: "Lower", Input: "hello"},
+			},
+		}
+
+		m.Run(t)
+	}, `SameAs case "Lower" has not run before this case`)
+}
+
+func TestFunctionCase_SameAs_Normalize(t *testing.T) {
+	m := mesa.FunctionMesa[string, string]{
+		Target: func(ctx *mesa.Ctx, in string) string {
+			return strings.TrimSpace(in)
+		},
+		Normalize: func(out string) string {
+			return strings.ToLower(out)
+		},
+		Cases: []mesa.FunctionCase[string, string]{
+			{Name: "Padded", Input: "  go  "},
+			{Name: "Upper", Input: "GO", SameAs: "Padded"},
+		},
+	}
+
+	m.Run(t)
+}
@@ -0,0 +1,16 @@
+package mesa
+
+import (
+	"net/http"
+	"net/http/httptest"
+)
+
+// StartServer starts an httptest.Server serving handler and closes it when the test or benchmark of the context
+// finishes. Its URL can be used as the fields or input of a case, e.g. from FieldsFn, to test a client against a
+// stubbed server.
+func (c *Ctx) StartServer(handler http.Handler) *httptest.Server {
+	srv := httptest.NewServer(handler)
+	c.cleanup(srv.Close)
+
+	return srv
+}
@@ -0,0 +1,63 @@
+package mesa_test
+
+import (
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/a20r/mesa"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type apiClient struct {
+	baseURL string
+}
+
+func (c apiClient) Get(path string) (string, error) {
+	resp, err := http.Get(c.baseURL + path)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+
+	return string(body), err
+}
+
+func TestCtx_StartServer(t *testing.T) {
+	var urls []string
+
+	m := mesa.MethodMesa[apiClient, string, string, mesa.ErrorPair[string]]{
+		NewInstance: func(ctx *mesa.Ctx, baseURL string) apiClient {
+			return apiClient{baseURL: baseURL}
+		},
+		Target: func(ctx *mesa.Ctx, inst apiClient, path string) mesa.ErrorPair[string] {
+			return mesa.NewErrorPair(inst.Get(path))
+		},
+		Cases: []mesa.MethodCase[apiClient, string, string, mesa.ErrorPair[string]]{
+			{
+				Name: "Stubbed server",
+				FieldsFn: func(ctx *mesa.Ctx) string {
+					srv := ctx.StartServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+						io.WriteString(w, "stub "+r.URL.Path)
+					}))
+					urls = append(urls, srv.URL)
+
+					return srv.URL
+				},
+				Input:    "/users",
+				Expected: mesa.NewErrorPair("stub /users", nil),
+			},
+		},
+	}
+
+	m.Run(t)
+
+	// The server is closed once the case finishes.
+	require.Len(t, urls, 1)
+
+	_, err := http.Get(urls[0])
+	assert.Error(t, err)
+}
@@ -0,0 +1,54 @@
+package mesa
+
+import "testing"
+
+// RunShard runs the cases whose index in Cases modulo shardCount equals shardIndex. Sharding is based on the order
+// of Cases, so running every shard index from 0 to shardCount-1 runs each case exactly once. This lets CI split a
+// large table across parallel jobs deterministically. The whole suite is validated before it is sharded, so a shard
+// may be empty.
+func (m MethodMesa[Inst, F, I, O]) RunShard(t *testing.T, shardIndex, shardCount int) {
+	validateShard(t, shardIndex, shardCount)
+
+	if err := m.Validate(); err != nil {
+		t.Fatalf("invalid mesa: %v", err)
+	}
+
+	// Names are derived before sharding so that they don't depend on the shard.
+	m.Cases = shard(m.deriveNames(), shardIndex, shardCount)
+	m.run(t)
+}
+
+// RunShard runs the cases whose index in Cases modulo shardCount equals shardIndex. Sharding is based on the order
+// of Cases, so running every shard index from 0 to shardCount-1 runs each case exactly once. This lets CI split a
+// large table across parallel jobs deterministically. The whole suite is validated before it is sharded, so a shard
+// may be empty.
+func (m FunctionMesa[I, O]) RunShard(t *testing.T, shardIndex, shardCount int) {
+	validateShard(t, shardIndex, shardCount)
+
+	if err := m.Validate(); err != nil {
+		t.Fatalf("invalid mesa: %v", err)
+	}
+
+	im := m.method()
+	im.Cases = shard(im.deriveNames(), shardIndex, shardCount)
+	im.run(t)
+}
+
+// validateShard fails the test if shardIndex is not in [0, shardCount).
+func validateShard(t *testing.T, shardIndex, shardCount int) {
+	if shardCount <= 0 || shardIndex < 0 || shardIndex >= shardCount {
+		t.Fatalf("invalid shard %d of %d: shardIndex must be at least 0 and less than shardCount", shardIndex,
+			shardCount)
+	}
+}
+
+// shard returns the cases whose index modulo count equals index.
+func shard[T any](cases []T, index, count int) []T {
+	var out []T
+
+	for i := index; i < len(cases); i += count {
+		out = append(out, cases[i])
+	}
+
+	return out
+}
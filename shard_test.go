@@ -0,0 +1,49 @@
+package mesa_test
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+
+	"github.com/a20r/mesa"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunShard(t *testing.T) {
+	var ran []int
+
+	m := mesa.FunctionMesa[int, mesa.Empty]{
+		BeforeCall: func(ctx *mesa.Ctx, in int) {
+			ran = append(ran, in)
+		},
+	}
+
+	for i := 0; i < 10; i++ {
+		m.Cases = append(m.Cases, mesa.FunctionCase[int, mesa.Empty]{
+			Name:  fmt.Sprintf("Case %d", i),
+			Input: i,
+		})
+	}
+
+	m.RunShard(t, 1, 3)
+	assert.Equal(t, []int{1, 4, 7}, ran)
+
+	ran = nil
+
+	for i := 0; i < 3; i++ {
+		m.RunShard(t, i, 3)
+	}
+
+	sort.Ints(ran)
+	assert.Equal(t, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, ran)
+}
+
+func TestRunShard_Invalid(t *testing.T) {
+	expectFailure(t, func(t *testing.T) {
+		m := mesa.FunctionMesa[int, mesa.Empty]{
+			Cases: []mesa.FunctionCase[int, mesa.Empty]{{Name: "Case"}},
+		}
+
+		m.RunShard(t, 3, 3)
+	}, "invalid shard 3 of 3")
+}
@@ -0,0 +1,78 @@
+package mesa
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/davecgh/go-spew/spew"
+)
+
+// updateEnv is the environment variable that makes Snapshot and GoldenJSON rewrite their files with the current output
+// instead of comparing to them, e.g. MESA_UPDATE=1 go test ./... It is not a flag since test packages commonly declare
+// their own -update flag, which would collide with one registered by mesa.
+const updateEnv = "MESA_UPDATE"
+
+// envSet reports whether the environment variable env is set to a true value, e.g. 1 or true.
+func envSet(env string) bool {
+	v, _ := strconv.ParseBool(os.Getenv(env))
+	return v
+}
+
+// dumpConfig renders values deterministically so that two dumps of equal values are identical. Pointer addresses
+// are omitted so that only the pointed to values are compared.
+var dumpConfig = spew.ConfigState{
+	Indent:                  "  ",
+	DisablePointerAddresses: true,
+	DisableCapacities:       true,
+	DisableMethods:          true,
+	SortKeys:                true,
+}
+
+// snapshot records the state of v and returns a function that asserts v has not changed since. When clone is not
+// nil it is used to copy v and the copy is compared to v, otherwise a deep dump of v, including unexported fields, is
+// compared. The assertion fails with a diff of the before and after states.
+func snapshot[T any](v T, clone func(T) T, msg string) func(ctx *Ctx) {
+	if clone != nil {
+		before := clone(v)
+		return func(ctx *Ctx) {
+			ctx.As.Equal(before, v, msg)
+		}
+	}
+
+	before := dumpConfig.Sdump(v)
+
+	return func(ctx *Ctx) {
+		ctx.As.Equal(before, dumpConfig.Sdump(v), msg)
+	}
+}
+
+// Snapshot asserts that data is equal to the snapshot file of the case, testdata/snapshots/<test name>.snap. The
+// snapshot is written instead when the tests are run with MESA_UPDATE=1. The case fails if the snapshot does not exist
+// yet.
+func (c *Ctx) Snapshot(data []byte) bool {
+	path := filepath.Join("testdata", "snapshots", filepath.FromSlash(c.name())+".snap")
+
+	if envSet(updateEnv) {
+		err := os.MkdirAll(filepath.Dir(path), 0o755)
+		if err == nil {
+			err = os.WriteFile(path, data, 0o644)
+		}
+
+		return c.As.NoError(err, "failed to update snapshot %s", path)
+	}
+
+	want, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return c.As.Fail("snapshot " + path + " does not exist, run the tests with MESA_UPDATE=1 to create it")
+	}
+
+	if !c.As.NoError(err, "failed to read snapshot %s", path) {
+		return false
+	}
+
+	return c.As.Equal(string(want), string(data), "output does not match snapshot %s, run the tests with MESA_UPDATE=1 "+
+		"to update it", path)
+}
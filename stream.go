@@ -0,0 +1,107 @@
+package mesa
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"time"
+)
+
+// Collect drains ch into a slice and returns it once ch is closed. It blocks forever if ch is never closed, use
+// Drain to bound the wait with the DrainTimeout of the case.
+func Collect[T any](ch <-chan T) []T {
+	var out []T
+
+	for v := range ch {
+		out = append(out, v)
+	}
+
+	return out
+}
+
+// Drain drains ch into a slice like Collect. If the case sets a DrainTimeout, the case fails when ch is not closed
+// within the timeout instead of hanging, and the values received so far are returned.
+func Drain[T any](ctx *Ctx, ch <-chan T) []T {
+	if ctx.drainTimeout <= 0 {
+		return Collect(ch)
+	}
+
+	var out []T
+
+	timer := time.NewTimer(ctx.drainTimeout)
+	defer timer.Stop()
+
+	for {
+		select {
+		case v, ok := <-ch:
+			if !ok {
+				return out
+			}
+
+			out = append(out, v)
+		case <-timer.C:
+			ctx.Re.Failf("channel was not closed", "%s: received %d values but the channel was not closed within %v",
+				ctx.name(), len(out), ctx.drainTimeout)
+
+			return out
+		}
+	}
+}
+
+// ReceiveWithin waits up to d for a value on ch and returns it, e.g. for targets that signal completion on a channel.
+// The case stops if no value is received within d or if ch is closed first. Like Drain, it is a function rather than
+// a method of Ctx since methods can't have type parameters.
+func ReceiveWithin[T any](ctx *Ctx, ch <-chan T, d time.Duration) T {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case v, ok := <-ch:
+		if !ok {
+			ctx.Re.Failf("channel was closed", "%s: channel was closed before a value was received", ctx.name())
+		}
+
+		return v
+	case <-timer.C:
+		ctx.Re.Failf("no value received", "%s: no value was received within %v", ctx.name(), d)
+	}
+
+	var zero T
+
+	return zero
+}
+
+// AssertClosed asserts that ch is closed and reports whether it is. It doesn't wait, so a value that is ready is
+// received, and consumed, and fails the assertion, and so does a channel that has no value ready yet. Use Drain to wait
+// for a channel to be closed.
+func AssertClosed[T any](ctx *Ctx, ch <-chan T) bool {
+	select {
+	case v, ok := <-ch:
+		if ok {
+			return ctx.As.Fail(fmt.Sprintf("%s: channel is not closed, received %#v", ctx.name(), v))
+		}
+
+		return true
+	default:
+		return ctx.As.Fail(ctx.name() + ": channel is not closed")
+	}
+}
+
+// ReadAll reads r until EOF and returns the data read. The case stops if r is nil or reading fails, e.g. for targets
+// that return a response body or an encoder.
+func (c *Ctx) ReadAll(r io.Reader) []byte {
+	if v := reflect.ValueOf(r); r == nil || (v.Kind() == reflect.Pointer && v.IsNil()) {
+		c.Re.FailNow(c.name() + ": cannot read from a nil reader")
+		return nil
+	}
+
+	data, err := io.ReadAll(r)
+	c.Re.NoError(err, "%s: failed to read", c.name())
+
+	return data
+}
+
+// ReadAllString reads r until EOF and returns the data read as a string. See ReadAll.
+func (c *Ctx) ReadAllString(r io.Reader) string {
+	return string(c.ReadAll(r))
+}
@@ -0,0 +1,196 @@
+package mesa_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/a20r/mesa"
+	"github.com/stretchr/testify/assert"
+)
+
+// countTo sends 1 to n on the returned channel and closes it when done is true.
+func countTo(n int, done bool) <-chan int {
+	ch := make(chan int)
+
+	go func() {
+		for i := 1; i <= n; i++ {
+			ch <- i
+		}
+
+		if done {
+			close(ch)
+		}
+	}()
+
+	return ch
+}
+
+func TestCollect(t *testing.T) {
+	m := mesa.FunctionMesa[int, <-chan int]{
+		Target: func(ctx *mesa.Ctx, n int) <-chan int {
+			return countTo(n, true)
+		},
+		Cases: []mesa.FunctionCase[int, <-chan int]{
+			{
+				Name:  "Empty",
+				Input: 0,
+				Check: func(ctx *mesa.Ctx, _ int, out <-chan int) {
+					ctx.As.Empty(mesa.Collect(out))
+				},
+			},
+			{
+				Name:         "Three values",
+				Input:        3,
+				DrainTimeout: time.Second,
+				Check: func(ctx *mesa.Ctx, _ int, out <-chan int) {
+					ctx.As.Equal([]int{1, 2, 3}, mesa.Drain(ctx, out))
+				},
+			},
+		},
+	}
+
+	m.Run(t)
+}
+
+func TestDrain_Timeout(t *testing.T) {
+	expectFailure(t, func(t *testing.T) {
+		m := mesa.FunctionMesa[int, <-chan int]{
+			Target: func(ctx *mesa.Ctx, n int) <-chan int {
+				return countTo(n, false)
+			},
+			Cases: []mesa.FunctionCase[int, <-chan int]{
+				{
+					Name:         "Never closed",
+					Input:        2,
+					DrainTimeout: 20 * time.Millisecond,
+					Check: func(ctx *mesa.Ctx, _ int, out <-chan int) {
+						mesa.Drain(ctx, out)
+					},
+				},
+			},
+		}
+
+		m.Run(t)
+	}, "received 2 values but the channel was not closed within 20ms")
+}
+
+func TestReceiveWithin(t *testing.T) {
+	m := mesa.FunctionMesa[int, <-chan int]{
+		Target: func(ctx *mesa.Ctx, n int) <-chan int {
+			return countTo(n, true)
+		},
+		Cases: []mesa.FunctionCase[int, <-chan int]{
+			{
+				Name:  "Two values then closed",
+				Input: 2,
+				Check: func(ctx *mesa.Ctx, _ int, out <-chan int) {
+					ctx.As.Equal(1, mesa.ReceiveWithin(ctx, out, time.Second))
+					ctx.As.Equal(2, mesa.ReceiveWithin(ctx, out, time.Second))
+
+					// The sender closes the channel right after the last value.
+					mesa.Drain(ctx, out)
+					mesa.AssertClosed(ctx, out)
+				},
+			},
+		},
+	}
+
+	m.Run(t)
+}
+
+func TestReceiveWithin_Failures(t *testing.T) {
+	r := record(func(ctx *mesa.Ctx) {
+		mesa.ReceiveWithin(ctx, countTo(0, false), 20*time.Millisecond)
+		t.Error("ReceiveWithin should stop the case on timeout")
+	})
+
+	assert.True(t, r.failed)
+	assert.Contains(t, strings.Join(r.errors, "\n"), "TestRecorder/case: no value was received within 20ms")
+
+	r = record(func(ctx *mesa.Ctx) {
+		mesa.ReceiveWithin(ctx, countTo(0, true), time.Second)
+		t.Error("ReceiveWithin should stop the case when the channel is closed")
+	})
+
+	assert.True(t, r.failed)
+	assert.Contains(t, strings.Join(r.errors, "\n"), "channel was closed before a value was received")
+}
+
+func TestAssertClosed(t *testing.T) {
+	closed := make(chan int)
+	close(closed)
+
+	r := record(func(ctx *mesa.Ctx) {
+		assert.True(t, mesa.AssertClosed(ctx, closed))
+	})
+
+	assert.False(t, r.failed)
+
+	r = record(func(ctx *mesa.Ctx) {
+		assert.False(t, mesa.AssertClosed(ctx, make(chan int)))
+	})
+
+	assert.True(t, r.failed)
+	assert.Contains(t, strings.Join(r.errors, "\n"), "TestRecorder/case: channel is not closed")
+
+	ready := make(chan int, 1)
+	ready <- 7
+
+	r = record(func(ctx *mesa.Ctx) {
+		assert.False(t, mesa.AssertClosed(ctx, ready))
+	})
+
+	assert.True(t, r.failed)
+	assert.Contains(t, strings.Join(r.errors, "\n"), "channel is not closed, received 7")
+}
+
+type failingReader struct{}
+
+func (failingReader) Read([]byte) (int, error) {
+	return 0, errors.New("connection reset")
+}
+
+func TestCtx_ReadAll(t *testing.T) {
+	m := mesa.FunctionMesa[string, io.Reader]{
+		Target: func(ctx *mesa.Ctx, in string) io.Reader {
+			return strings.NewReader(strings.Repeat(in, 3))
+		},
+		Check: func(ctx *mesa.Ctx, in string, out io.Reader) {
+			ctx.As.Equal(strings.Repeat(in, 3), ctx.ReadAllString(out))
+		},
+		Cases: []mesa.FunctionCase[string, io.Reader]{
+			{Name: "Repeated", Input: "ab"},
+			{Name: "Empty", Input: ""},
+		},
+	}
+
+	m.Run(t)
+}
+
+func TestCtx_ReadAll_Failures(t *testing.T) {
+	tests := []struct {
+		name string
+		r    io.Reader
+		want string
+	}{
+		{name: "Nil reader", r: nil, want: "cannot read from a nil reader"},
+		{name: "Typed nil reader", r: (*bytes.Buffer)(nil), want: "cannot read from a nil reader"},
+		{name: "Read error", r: failingReader{}, want: "connection reset"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := record(func(ctx *mesa.Ctx) {
+				ctx.ReadAll(tt.r)
+				t.Error("ReadAll should stop the case")
+			})
+
+			assert.True(t, r.failed)
+			assert.Contains(t, strings.Join(r.errors, "\n"), tt.want)
+		})
+	}
+}
@@ -0,0 +1,178 @@
+// Package streammesa provides a Mesa for table tests of streaming handlers, such as gRPC server-streaming and
+// bidirectional streaming handlers, without hand-written stream mocks.
+package streammesa
+
+import (
+	"context"
+	"io"
+	"sync"
+	"testing"
+
+	"github.com/a20r/mesa"
+)
+
+// Type assertion to ensure the stream mesa adheres to the Mesa interface
+var _ mesa.Mesa = StreamMesa[any, any]{}
+
+// Stream is a fake stream that supplies queued messages to Recv and records the messages passed to Send. It has the
+// Send, Recv and Context methods of the streams generated by gRPC, so handlers written against a small interface with
+// these methods can be tested with it. It is safe for concurrent use.
+type Stream[RecvType, SendType any] struct {
+	ctx  context.Context
+	mu   sync.Mutex
+	recv []RecvType
+	sent []SendType
+}
+
+// NewStream returns a stream whose Recv returns the given messages in order and then io.EOF, as if the client closed
+// its side of the stream.
+func NewStream[RecvType, SendType any](ctx context.Context, recv ...RecvType) *Stream[RecvType, SendType] {
+	return &Stream[RecvType, SendType]{ctx: ctx, recv: append([]RecvType(nil), recv...)}
+}
+
+// Context returns the context of the stream.
+func (s *Stream[R, S]) Context() context.Context {
+	return s.ctx
+}
+
+// Send records msg. It returns the error of the context once the context is done, like a stream whose client went
+// away.
+func (s *Stream[R, S]) Send(msg S) error {
+	if err := s.ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sent = append(s.sent, msg)
+
+	return nil
+}
+
+// Recv returns the next queued message. It returns io.EOF once every message was received, or the error of the
+// context once the context is done.
+func (s *Stream[R, S]) Recv() (R, error) {
+	var zero R
+
+	if err := s.ctx.Err(); err != nil {
+		return zero, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.recv) == 0 {
+		return zero, io.EOF
+	}
+
+	msg := s.recv[0]
+	s.recv = s.recv[1:]
+
+	return msg, nil
+}
+
+// Sent returns a copy of the messages sent so far, in order. It is empty, not nil, when nothing was sent.
+func (s *Stream[R, S]) Sent() []S {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]S{}, s.sent...)
+}
+
+// Result is the output of the handler of a case: the stream it was called with and the error it returned.
+type Result[RecvType, SendType any] struct {
+	Stream *Stream[RecvType, SendType]
+	Err    error
+}
+
+// StreamCase represents a test case with its associated properties.
+type StreamCase[RecvType, SendType any] struct {
+	// [Optional] Name of the test case. When it is empty, the name is derived from Recv with %v, like the names of mesa
+	// cases are derived from their input. Explicit names are still recommended since they say what the case is about.
+	Name string
+
+	// [Optional] Recv are the incoming messages returned by Recv, in order, before io.EOF.
+	Recv []RecvType
+
+	// [Optional] Skip the test case with the provided reason.
+	Skip string
+
+	// [Optional] ExpectedSent asserts the messages sent by the handler, in order, if set. Use an empty slice to assert
+	// that nothing was sent.
+	ExpectedSent []SendType
+
+	// [Optional] ExpectErrMsg asserts that the handler returns an error with this message.
+	ExpectErrMsg string
+
+	// [Optional] ExpectErr asserts that the handler returns an error, whatever its message, e.g. to match it in Check
+	// with errors.Is or ErrorContains.
+	ExpectErr bool
+
+	// [Optional] Function to check the result of the handler. It will be called instead of the Check function in the
+	// StreamMesa if provided. The handler must return nil unless the case has ExpectErrMsg, ExpectErr or its own Check,
+	// which then asserts the error itself.
+	Check func(ctx *mesa.Ctx, in []RecvType, res Result[RecvType, SendType])
+}
+
+// StreamMesa represents a collection of test cases that call a streaming handler with a fake stream and check the
+// messages it sent.
+type StreamMesa[RecvType, SendType any] struct {
+	// [Required] Handler under test. It is called with a stream carrying the context of the case.
+	Handler func(ctx *mesa.Ctx, stream *Stream[RecvType, SendType]) error
+
+	// [Optional] Function to check the result of the handler. This is called when no Check function is provided by
+	// the case itself.
+	Check func(ctx *mesa.Ctx, in []RecvType, res Result[RecvType, SendType])
+
+	// [Required] List of test cases
+	Cases []StreamCase[RecvType, SendType]
+}
+
+// Run executes all the test cases in the StreamMesa instance.
+func (m StreamMesa[R, S]) Run(t *testing.T) {
+	fm := mesa.FunctionMesa[[]R, Result[R, S]]{
+		Target: func(ctx *mesa.Ctx, in []R) Result[R, S] {
+			stream := NewStream[R, S](ctx, in...)
+			return Result[R, S]{Stream: stream, Err: m.Handler(ctx, stream)}
+		},
+		Cases: make([]mesa.FunctionCase[[]R, Result[R, S]], len(m.Cases)),
+	}
+
+	for i, c := range m.Cases {
+		c := c
+		fm.Cases[i] = mesa.FunctionCase[[]R, Result[R, S]]{
+			Name:  c.Name,
+			Input: c.Recv,
+			Skip:  c.Skip,
+			Check: func(ctx *mesa.Ctx, in []R, res Result[R, S]) {
+				c.assert(ctx, res)
+
+				switch {
+				case c.Check != nil:
+					c.Check(ctx, in, res)
+				case m.Check != nil:
+					m.Check(ctx, in, res)
+				}
+			},
+		}
+	}
+
+	fm.Run(t)
+}
+
+// assert checks the expectations of the case against the result of the handler.
+func (c StreamCase[R, S]) assert(ctx *mesa.Ctx, res Result[R, S]) {
+	switch {
+	case c.ExpectErrMsg != "":
+		ctx.As.EqualError(res.Err, c.ExpectErrMsg, "unexpected error")
+	case c.ExpectErr:
+		ctx.As.Error(res.Err, "expected an error")
+	case c.Check == nil:
+		ctx.As.NoError(res.Err, "unexpected error")
+	}
+
+	if c.ExpectedSent != nil {
+		ctx.As.Equal(c.ExpectedSent, res.Stream.Sent(), "unexpected sent messages")
+	}
+}
@@ -0,0 +1,154 @@
+package streammesa_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/a20r/mesa"
+	"github.com/a20r/mesa/streammesa"
+	"github.com/stretchr/testify/assert"
+)
+
+// chatStream is the interface a bidirectional handler is written against, as generated by gRPC minus
+// grpc.ServerStream.
+type chatStream interface {
+	Context() context.Context
+	Send(string) error
+	Recv() (string, error)
+}
+
+// shout replies to every message with its upper case until the client closes the stream.
+func shout(stream chatStream) error {
+	for {
+		msg, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if msg == "" {
+			return errors.New("empty message")
+		}
+
+		if err := stream.Send(strings.ToUpper(msg)); err != nil {
+			return err
+		}
+	}
+}
+
+func TestStreamMesa(t *testing.T) {
+	m := streammesa.StreamMesa[string, string]{
+		Handler: func(ctx *mesa.Ctx, stream *streammesa.Stream[string, string]) error {
+			return shout(stream)
+		},
+		Cases: []streammesa.StreamCase[string, string]{
+			{
+				Name:         "Replies in order",
+				Recv:         []string{"hello", "world"},
+				ExpectedSent: []string{"HELLO", "WORLD"},
+			},
+			{
+				Name:         "Closed immediately",
+				ExpectedSent: []string{},
+			},
+			{
+				Name:         "Empty message",
+				Recv:         []string{"hi", "", "ignored"},
+				ExpectedSent: []string{"HI"},
+				ExpectErrMsg: "empty message",
+			},
+		},
+	}
+
+	m.Run(t)
+}
+
+func TestStreamMesa_Check(t *testing.T) {
+	var checked []int
+
+	m := streammesa.StreamMesa[int, int]{
+		Handler: func(ctx *mesa.Ctx, stream *streammesa.Stream[int, int]) error {
+			n, err := stream.Recv()
+			if err != nil {
+				return err
+			}
+
+			for i := 1; i <= n; i++ {
+				if err := stream.Send(i); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		},
+		Check: func(ctx *mesa.Ctx, in []int, res streammesa.Result[int, int]) {
+			checked = append(checked, len(res.Stream.Sent()))
+		},
+		Cases: []streammesa.StreamCase[int, int]{
+			{Name: "Three", Recv: []int{3}, ExpectedSent: []int{1, 2, 3}},
+			{Name: "None", Recv: []int{0}},
+			{
+				Name:         "No request",
+				ExpectErrMsg: "EOF",
+				Check: func(ctx *mesa.Ctx, in []int, res streammesa.Result[int, int]) {
+					ctx.As.ErrorIs(res.Err, io.EOF)
+				},
+			},
+			{
+				Name: "Error asserted by Check",
+				Check: func(ctx *mesa.Ctx, in []int, res streammesa.Result[int, int]) {
+					ctx.As.ErrorIs(res.Err, io.EOF)
+				},
+			},
+			{Name: "Any error", ExpectErr: true},
+		},
+	}
+
+	m.Run(t)
+
+	assert.Equal(t, []int{3, 0, 0}, checked)
+}
+
+func TestStream_Canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	stream := streammesa.NewStream[string, string](ctx, "queued")
+
+	cancel()
+
+	_, err := stream.Recv()
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.ErrorIs(t, stream.Send("late"), context.Canceled)
+	assert.Empty(t, stream.Sent())
+}
+
+func ExampleStreamMesa() {
+	m := streammesa.StreamMesa[string, string]{
+		// The handler under test only depends on the Send, Recv and Context methods of its stream, so the fake
+		// stream can be passed in place of the one generated by gRPC.
+		Handler: func(ctx *mesa.Ctx, stream *streammesa.Stream[string, string]) error {
+			return shout(stream)
+		},
+		Cases: []streammesa.StreamCase[string, string]{
+			{
+				Name:         "Echo in upper case",
+				Recv:         []string{"ping", "pong"},
+				ExpectedSent: []string{"PING", "PONG"},
+			},
+			{
+				Name:         "Reject empty messages",
+				Recv:         []string{""},
+				ExpectedSent: []string{},
+				ExpectErrMsg: "empty message",
+			},
+		},
+	}
+
+	var t *testing.T
+	m.Run(t)
+}
@@ -0,0 +1,141 @@
+package mesa
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CasesFromStructTags returns one case per combination of the variations declared by the struct tags of the input
+// type, which lets an input struct describe its own test matrix. Every case starts from a copy of template and shares
+// check.
+//
+// A field declares its variations with a tag of the form `mesa:"a,b,c"`: the tag value is split on commas and each
+// part, with surrounding spaces trimmed, is parsed as a value of the field. Fields of kind string, bool, int, uint and
+// float, as well as time.Duration, are supported, and parts are parsed with the strconv function of the kind, or
+// time.ParseDuration. Fields without a mesa tag keep the value of template, and a part can't contain a comma.
+//
+// The cases are the product of the variations of the tagged fields. They are ordered like nested loops over the
+// fields in declaration order, with the last field varying fastest, and are named after the values of the tagged
+// fields, e.g. "Mode=valid,Size=10". It panics if the input type is not a struct, if it has no tagged field, or if a
+// tagged field is unexported, of an unsupported kind or has a part that can't be parsed.
+func CasesFromStructTags[InputType, OutputType any](
+	template InputType,
+	check func(ctx *Ctx, in InputType, out OutputType),
+) []FunctionCase[InputType, OutputType] {
+	typ := reflect.TypeOf(template)
+	if typ == nil || typ.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("mesa: CasesFromStructTags requires a struct input, got %v", typ))
+	}
+
+	var variations []tagVariation
+
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+
+		tag, ok := f.Tag.Lookup("mesa")
+		if !ok {
+			continue
+		}
+
+		if !f.IsExported() {
+			panic(fmt.Sprintf("mesa: field %s of %s has a mesa tag but is unexported", f.Name, typ))
+		}
+
+		v := tagVariation{index: i, name: f.Name}
+		for _, part := range strings.Split(tag, ",") {
+			part = strings.TrimSpace(part)
+			v.parts = append(v.parts, part)
+			v.values = append(v.values, parseTagValue(f, part))
+		}
+
+		variations = append(variations, v)
+	}
+
+	if len(variations) == 0 {
+		panic(fmt.Sprintf("mesa: %s has no field with a mesa tag", typ))
+	}
+
+	cases := []FunctionCase[InputType, OutputType]{{Input: template, Check: check}}
+
+	for _, v := range variations {
+		next := make([]FunctionCase[InputType, OutputType], 0, len(cases)*len(v.values))
+
+		for _, c := range cases {
+			for j, val := range v.values {
+				in := reflect.New(typ).Elem()
+				in.Set(reflect.ValueOf(c.Input))
+				in.Field(v.index).Set(val)
+
+				name := v.name + "=" + v.parts[j]
+				if c.Name != "" {
+					name = c.Name + "," + name
+				}
+
+				next = append(next, FunctionCase[InputType, OutputType]{
+					Name:  name,
+					Input: in.Interface().(InputType),
+					Check: check,
+				})
+			}
+		}
+
+		cases = next
+	}
+
+	return cases
+}
+
+// tagVariation holds the values declared by the mesa tag of a field, along with the parts of the tag they were parsed
+// from.
+type tagVariation struct {
+	index  int
+	name   string
+	parts  []string
+	values []reflect.Value
+}
+
+// durationType is the type of time.Duration, whose tag values are parsed with time.ParseDuration.
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// parseTagValue parses a part of the mesa tag of f as a value of the type of f.
+func parseTagValue(f reflect.StructField, part string) reflect.Value {
+	v := reflect.New(f.Type).Elem()
+
+	var err error
+
+	switch k := f.Type.Kind(); {
+	case f.Type == durationType:
+		var d time.Duration
+		d, err = time.ParseDuration(part)
+		v.SetInt(int64(d))
+	case k == reflect.String:
+		v.SetString(part)
+	case k == reflect.Bool:
+		var b bool
+		b, err = strconv.ParseBool(part)
+		v.SetBool(b)
+	case k >= reflect.Int && k <= reflect.Int64:
+		var n int64
+		n, err = strconv.ParseInt(part, 0, f.Type.Bits())
+		v.SetInt(n)
+	case k >= reflect.Uint && k <= reflect.Uint64:
+		var n uint64
+		n, err = strconv.ParseUint(part, 0, f.Type.Bits())
+		v.SetUint(n)
+	case k == reflect.Float32 || k == reflect.Float64:
+		var n float64
+		n, err = strconv.ParseFloat(part, f.Type.Bits())
+		v.SetFloat(n)
+	default:
+		panic(fmt.Sprintf("mesa: field %s has a mesa tag but its type %s is not supported", f.Name, f.Type))
+	}
+
+	if err != nil {
+		panic(fmt.Sprintf("mesa: invalid value %q in the mesa tag of field %s: %v", part, f.Name, err))
+	}
+
+	return v
+}
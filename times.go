@@ -0,0 +1,22 @@
+package mesa
+
+import (
+	"reflect"
+	"time"
+)
+
+// WithinDuration asserts that expected and actual are within delta of each other. Their monotonic clock readings
+// are stripped first, so times read from time.Now are compared by their wall clock like times that were parsed or
+// built with time.Date. The failure message includes the name of the case.
+func (c *Ctx) WithinDuration(expected, actual time.Time, delta time.Duration) bool {
+	return c.As.WithinDuration(expected.Round(0), actual.Round(0), delta, "%s: times are not within %v of each other",
+		c.name(), delta)
+}
+
+// timeType is the type of time.Time outputs compared with TimeTolerance.
+var timeType = reflect.TypeOf(time.Time{})
+
+// isTime reports whether T is time.Time.
+func isTime[T any]() bool {
+	return reflect.TypeOf((*T)(nil)).Elem() == timeType
+}
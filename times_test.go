@@ -0,0 +1,72 @@
+package mesa_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/a20r/mesa"
+)
+
+func TestCtx_WithinDuration(t *testing.T) {
+	base := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	now := time.Now()
+
+	tests := []struct {
+		name             string
+		expected, actual time.Time
+		delta            time.Duration
+		wantFailed       bool
+	}{
+		{name: "Milliseconds apart", expected: base, actual: base.Add(3 * time.Millisecond), delta: 10 * time.Millisecond},
+		{name: "Before", expected: base, actual: base.Add(-3 * time.Millisecond), delta: 10 * time.Millisecond},
+		{name: "Too far", expected: base, actual: base.Add(time.Second), delta: 10 * time.Millisecond, wantFailed: true},
+		{name: "Other location", expected: base, actual: base.In(time.FixedZone("NZST", 12*3600)), delta: time.Nanosecond},
+		{name: "Monotonic clock", expected: now.Round(0), actual: now, delta: time.Nanosecond},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := record(func(ctx *mesa.Ctx) {
+				ctx.WithinDuration(tt.expected, tt.actual, tt.delta)
+			})
+
+			assert.Equal(t, tt.wantFailed, r.failed, r.errors)
+		})
+	}
+}
+
+func TestTimeTolerance(t *testing.T) {
+	created := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	m := mesa.FunctionMesa[time.Duration, time.Time]{
+		Target: func(ctx *mesa.Ctx, skew time.Duration) time.Time {
+			return created.Add(skew)
+		},
+		Cases: []mesa.FunctionCase[time.Duration, time.Time]{
+			{Name: "Exact", Input: 0, Expected: created, TimeTolerance: time.Millisecond},
+			{Name: "Milliseconds late", Input: 40 * time.Millisecond, Expected: created, TimeTolerance: 50 * time.Millisecond},
+			{Name: "Truncated precision", Input: 999 * time.Microsecond, Expected: created, TimeTolerance: time.Millisecond},
+		},
+	}
+
+	m.Run(t)
+}
+
+func TestTimeTolerance_Exceeded(t *testing.T) {
+	expectFailure(t, func(t *testing.T) {
+		created := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+		m := mesa.FunctionMesa[time.Duration, time.Time]{
+			Target: func(ctx *mesa.Ctx, skew time.Duration) time.Time {
+				return created.Add(skew)
+			},
+			Cases: []mesa.FunctionCase[time.Duration, time.Time]{
+				{Name: "Too late", Input: time.Second, Expected: created, TimeTolerance: 50 * time.Millisecond},
+			},
+		}
+
+		m.Run(t)
+	}, "TestTimeTolerance_Exceeded/Too_late: times are not within 50ms of each other")
+}
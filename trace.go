@@ -0,0 +1,12 @@
+package mesa
+
+// trace logs a phase of the lifecycle of the suite or case when the suite sets TraceLifecycle.
+func (c *Ctx) trace(phase string) {
+	if !c.opts.traceLifecycle {
+		return
+	}
+
+	if l, ok := c.t.(interface{ Logf(string, ...any) }); ok {
+		l.Logf("mesa trace: %s: %s", c.name(), phase)
+	}
+}
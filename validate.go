@@ -0,0 +1,195 @@
+package mesa
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Validate reports every mistake in the definition of the suite that would otherwise surface as a cryptic panic or go
+// unnoticed: a missing NewInstance, Shuffle combined with SortCases, Reset without GroupByFields, no cases, duplicate
+// case names, which t.Run would silently suffix with #01, SameAs referencing a case that doesn't exist, ExpectPanic
+// or Concurrency combined with an allocation check, and a Tolerance or TimeTolerance that doesn't apply to the output.
+// Names computed by a NameFn or derived from the input are not checked.
+func (m MethodMesa[Inst, F, I, O]) Validate() error {
+	var errs []error
+
+	if m.NewInstance == nil && m.NewInstanceErr == nil {
+		errs = append(errs, errors.New("NewInstance or NewInstanceErr is required"))
+	}
+
+	if m.Shuffle && m.SortCases != nil {
+		errs = append(errs, errors.New("Shuffle and SortCases can't be combined"))
+	}
+
+	if m.Reset != nil && m.GroupByFields == nil {
+		errs = append(errs, errors.New("Reset requires GroupByFields"))
+	}
+
+	names := make([]caseName, len(m.Cases))
+	for i, c := range m.Cases {
+		names[i] = caseName{name: c.Name, computed: c.Name == "" || c.NameFn != nil || m.NameFn != nil, sameAs: c.SameAs}
+
+		if (c.ExpectPanic || c.ExpectPanicFrom != "") && (c.MaxAllocs > 0 || c.ExpectNoAllocs) {
+			errs = append(errs, fmt.Errorf("case %d combines ExpectPanic with MaxAllocs or ExpectNoAllocs", i))
+		}
+
+		if c.Concurrency > 1 && (c.MaxAllocs > 0 || c.ExpectNoAllocs) {
+			errs = append(errs, fmt.Errorf("case %d combines Concurrency with MaxAllocs or ExpectNoAllocs", i))
+		}
+
+		errs = append(errs, validateTolerances[O](i, c.Tolerance, c.TimeTolerance)...)
+	}
+
+	return errors.Join(append(errs, validateNames(names)...)...)
+}
+
+// Validate reports every mistake in the definition of the suite that would otherwise go unnoticed: Shuffle combined
+// with SortCases, no cases, duplicate case names, which t.Run would silently suffix with #01, SameAs referencing a
+// case that doesn't exist, ExpectPanic combined with an allocation check, and a Tolerance or TimeTolerance that doesn't
+// apply to the output. Names computed by a NameFn or derived from the input are not checked.
+func (m FunctionMesa[I, O]) Validate() error {
+	var errs []error
+
+	if m.Shuffle && m.SortCases != nil {
+		errs = append(errs, errors.New("Shuffle and SortCases can't be combined"))
+	}
+
+	names := make([]caseName, len(m.Cases))
+	for i, c := range m.Cases {
+		names[i] = caseName{name: c.Name, computed: c.Name == "" || c.NameFn != nil || m.NameFn != nil, sameAs: c.SameAs}
+
+		if (c.ExpectPanic || c.ExpectPanicFrom != "") && (c.MaxAllocs > 0 || c.ExpectNoAllocs) {
+			errs = append(errs, fmt.Errorf("case %d combines ExpectPanic with MaxAllocs or ExpectNoAllocs", i))
+		}
+
+		errs = append(errs, validateTolerances[O](i, c.Tolerance, c.TimeTolerance)...)
+	}
+
+	return errors.Join(append(errs, validateNames(names)...)...)
+}
+
+// validateTolerances checks that the Tolerance and TimeTolerance of case i are only set for outputs that they apply to,
+// since they are silently ignored otherwise.
+func validateTolerances[O any](i int, tolerance float64, timeTolerance time.Duration) []error {
+	var (
+		errs []error
+		typ  = reflect.TypeOf((*O)(nil)).Elem()
+	)
+
+	if tolerance > 0 && !isFloat[O]() {
+		errs = append(errs, fmt.Errorf("case %d sets Tolerance but the output is a %v, not a float", i, typ))
+	}
+
+	if timeTolerance > 0 && !isTime[O]() {
+		errs = append(errs, fmt.Errorf("case %d sets TimeTolerance but the output is a %v, not a time.Time", i, typ))
+	}
+
+	return errs
+}
+
+// caseName is the name of a case, whether it is computed by a NameFn or derived from the input when the case runs, and
+// the SameAs of the case.
+type caseName struct {
+	name     string
+	computed bool
+	sameAs   string
+}
+
+// validateNames checks that there is at least one case, that the names of the cases are unique, and that SameAs
+// references another case. Computed names are only known when the cases run, so they are not checked, but SameAs
+// references the Name field, which is always known.
+func validateNames(names []caseName) []error {
+	if len(names) == 0 {
+		return []error{errors.New("Cases is empty")}
+	}
+
+	var (
+		errs []error
+		seen = make(map[string]bool, len(names))
+	)
+
+	for i, n := range names {
+		switch {
+		case n.computed:
+			continue
+		case seen[n.name]:
+			errs = append(errs, fmt.Errorf("case %d has the duplicate case name %q", i, n.name))
+		}
+
+		seen[n.name] = true
+	}
+
+	for i, n := range names {
+		switch {
+		case n.sameAs == "":
+		case n.sameAs == n.name:
+			errs = append(errs, fmt.Errorf("case %d has SameAs referencing itself", i))
+		case !declared(names, n.sameAs):
+			errs = append(errs, fmt.Errorf("case %d has SameAs referencing the unknown case %q", i, n.sameAs))
+		}
+	}
+
+	return errs
+}
+
+// declared reports whether one of the cases has the given Name field.
+func declared(names []caseName, name string) bool {
+	for _, n := range names {
+		if n.name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// noCasesReason returns why none of the cases would run, or an empty string if at least one would. A case is excluded
+// by the first of Filter, Skip and RequireEnv that applies to it, in the order the cases check them.
+func (m MethodMesa[Inst, F, I, O]) noCasesReason() string {
+	var filtered, skipped, missingEnv int
+
+	for _, c := range m.Cases {
+		switch {
+		case m.Filter != nil && !m.Filter(m.caseMeta(c)):
+			filtered++
+		case c.Skip != "":
+			skipped++
+		case missingEnvVar(c.RequireEnv):
+			missingEnv++
+		default:
+			return ""
+		}
+	}
+
+	var reasons []string
+
+	for _, r := range []struct {
+		n      int
+		reason string
+	}{
+		{filtered, "excluded by Filter"},
+		{skipped, "skipped"},
+		{missingEnv, "missing a RequireEnv variable"},
+	} {
+		if r.n > 0 {
+			reasons = append(reasons, fmt.Sprintf("%d %s", r.n, r.reason))
+		}
+	}
+
+	return fmt.Sprintf("no cases would run out of %d: %s", len(m.Cases), strings.Join(reasons, ", "))
+}
+
+// missingEnvVar reports whether any of the environment variables is unset or empty.
+func missingEnvVar(env []string) bool {
+	for _, e := range env {
+		if os.Getenv(e) == "" {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,241 @@
+package mesa_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/a20r/mesa"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMethodMesa_Validate(t *testing.T) {
+	newBuilder := func(ctx *mesa.Ctx, _ mesa.Empty) *strings.Builder {
+		return &strings.Builder{}
+	}
+
+	tests := []struct {
+		name    string
+		m       mesa.MethodMesa[*strings.Builder, mesa.Empty, string, int]
+		wantErr []string
+	}{
+		{
+			name: "Valid",
+			m: mesa.MethodMesa[*strings.Builder, mesa.Empty, string, int]{
+				NewInstance: newBuilder,
+				Cases: []mesa.MethodCase[*strings.Builder, mesa.Empty, string, int]{
+					{Name: "A"},
+					{Name: "B"},
+				},
+			},
+		},
+		{
+			name: "Missing NewInstance",
+			m: mesa.MethodMesa[*strings.Builder, mesa.Empty, string, int]{
+				Cases: []mesa.MethodCase[*strings.Builder, mesa.Empty, string, int]{
+					{Name: "A"},
+				},
+			},
+			wantErr: []string{"NewInstance or NewInstanceErr is required"},
+		},
+		{
+			name: "No cases",
+			m: mesa.MethodMesa[*strings.Builder, mesa.Empty, string, int]{
+				NewInstance: newBuilder,
+			},
+			wantErr: []string{"Cases is empty"},
+		},
+		{
+			name: "Unnamed cases",
+			m: mesa.MethodMesa[*strings.Builder, mesa.Empty, string, int]{
+				NewInstance: newBuilder,
+				Cases: []mesa.MethodCase[*strings.Builder, mesa.Empty, string, int]{
+					{Name: "A"},
+					{Input: "a"},
+					{Input: "a"},
+				},
+			},
+		},
+		{
+			name: "Duplicate names",
+			m: mesa.MethodMesa[*strings.Builder, mesa.Empty, string, int]{
+				NewInstance: newBuilder,
+				Cases: []mesa.MethodCase[*strings.Builder, mesa.Empty, string, int]{
+					{Name: "A"},
+					{Name: "B"},
+					{Name: "A"},
+				},
+			},
+			wantErr: []string{`case 2 has the duplicate case name "A"`},
+		},
+		{
+			name: "Every mistake",
+			m: mesa.MethodMesa[*strings.Builder, mesa.Empty, string, int]{
+				Cases: []mesa.MethodCase[*strings.Builder, mesa.Empty, string, int]{
+					{},
+					{Name: "A"},
+					{Name: "A"},
+				},
+			},
+			wantErr: []string{
+				"NewInstance or NewInstanceErr is required",
+				`case 2 has the duplicate case name "A"`,
+			},
+		},
+		{
+			name: "Bad SameAs",
+			m: mesa.MethodMesa[*strings.Builder, mesa.Empty, string, int]{
+				NewInstance: newBuilder,
+				Cases: []mesa.MethodCase[*strings.Builder, mesa.Empty, string, int]{
+					{Name: "A"},
+					{Name: "B", SameAs: "A"},
+					{Name: "C", SameAs: "C"},
+					{Name: "D", SameAs: "E"},
+				},
+			},
+			wantErr: []string{
+				"case 2 has SameAs referencing itself",
+				`case 3 has SameAs referencing the unknown case "E"`,
+			},
+		},
+		{
+			name: "Concurrent allocation check",
+			m: mesa.MethodMesa[*strings.Builder, mesa.Empty, string, int]{
+				NewInstance: newBuilder,
+				Cases: []mesa.MethodCase[*strings.Builder, mesa.Empty, string, int]{
+					{Name: "A", Concurrency: 4, MaxAllocs: 1},
+					{Name: "B", Concurrency: 4, ExpectNoAllocs: true},
+					{Name: "C", Concurrency: 1, ExpectNoAllocs: true},
+				},
+			},
+			wantErr: []string{
+				"case 0 combines Concurrency with MaxAllocs or ExpectNoAllocs",
+				"case 1 combines Concurrency with MaxAllocs or ExpectNoAllocs",
+			},
+		},
+		{
+			name: "Computed names",
+			m: mesa.MethodMesa[*strings.Builder, mesa.Empty, string, int]{
+				NewInstance: newBuilder,
+				NameFn: func(ctx *mesa.Ctx, in string) string {
+					return in
+				},
+				Cases: []mesa.MethodCase[*strings.Builder, mesa.Empty, string, int]{
+					{Input: "a"},
+					{Input: "b"},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.m.Validate()
+			if len(tt.wantErr) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+
+			if assert.Error(t, err) {
+				assert.Equal(t, strings.Join(tt.wantErr, "\n"), err.Error())
+			}
+		})
+	}
+}
+
+func TestFunctionMesa_Validate(t *testing.T) {
+	m := mesa.FunctionMesa[string, int]{}
+	assert.EqualError(t, m.Validate(), "Cases is empty")
+
+	m.Cases = []mesa.FunctionCase[string, int]{{Name: "A"}, {Name: "A"}}
+	assert.EqualError(t, m.Validate(), `case 1 has the duplicate case name "A"`)
+
+	m.Cases = []mesa.FunctionCase[string, int]{
+		{Name: "A", ExpectPanic: true, MaxAllocs: 1},
+		{Name: "B", ExpectPanicFrom: "parse", ExpectNoAllocs: true},
+		{Name: "C", ExpectPanic: true},
+	}
+	assert.EqualError(t, m.Validate(), "case 0 combines ExpectPanic with MaxAllocs or ExpectNoAllocs\n"+
+		"case 1 combines ExpectPanic with MaxAllocs or ExpectNoAllocs")
+
+	m.Cases = []mesa.FunctionCase[string, int]{
+		{Name: "A", Tolerance: 0.1},
+		{Name: "B", TimeTolerance: time.Second},
+	}
+	assert.EqualError(t, m.Validate(), "case 0 sets Tolerance but the output is a int, not a float\n"+
+		"case 1 sets TimeTolerance but the output is a int, not a time.Time")
+
+	floats := mesa.FunctionMesa[string, mesa.ErrorPair[float64]]{
+		Cases: []mesa.FunctionCase[string, mesa.ErrorPair[float64]]{{Name: "A", Tolerance: 0.1}},
+	}
+	assert.EqualError(t, floats.Validate(),
+		"case 0 sets Tolerance but the output is a mesa.ErrorPair[float64], not a float")
+}
+
+func TestFunctionMesa_Run_Invalid(t *testing.T) {
+	expectFailure(t, func(t *testing.T) {
+		m := mesa.FunctionMesa[string, int]{
+			Shuffle:   true,
+			SortCases: func(a, b mesa.CaseMeta) bool { return a.Name < b.Name },
+			Cases:     []mesa.FunctionCase[string, int]{{Name: "A"}},
+		}
+
+		m.Run(t)
+	}, "invalid mesa: Shuffle and SortCases can't be combined")
+}
+
+func TestFailOnNoCases_Empty(t *testing.T) {
+	expectFailure(t, func(t *testing.T) {
+		m := mesa.FunctionMesa[int, int]{
+			FailOnNoCases: true,
+		}
+
+		m.Run(t)
+	}, "invalid mesa: Cases is empty")
+}
+
+func TestFailOnNoCases_AllExcluded(t *testing.T) {
+	expectFailure(t, func(t *testing.T) {
+		m := mesa.FunctionMesa[int, int]{
+			FailOnNoCases: true,
+			Init: func(ctx *mesa.Ctx) {
+				ctx.Re.Fail("Init must not run")
+			},
+			Filter: func(c mesa.CaseMeta) bool {
+				return c.Labels["tier"] == "fast"
+			},
+			Cases: []mesa.FunctionCase[int, int]{
+				{Name: "Slow", Input: 1},
+				{Name: "Slower", Input: 2},
+				{Name: "Fast", Input: 3, Labels: map[string]string{"tier": "fast"}, Skip: "flaky"},
+				{Name: "Integration", Input: 4, Labels: map[string]string{"tier": "fast"},
+					RequireEnv: []string{"MESA_TEST_UNSET_ENV"}},
+			},
+		}
+
+		m.Run(t)
+	}, "no cases would run out of 4: 2 excluded by Filter, 1 skipped, 1 missing a RequireEnv variable")
+}
+
+func TestFailOnNoCases_SomeRun(t *testing.T) {
+	var ran []string
+
+	m := mesa.FunctionMesa[int, int]{
+		FailOnNoCases: true,
+		Filter: func(c mesa.CaseMeta) bool {
+			return c.Name != "Excluded"
+		},
+		Target: func(ctx *mesa.Ctx, in int) int {
+			ran = append(ran, ctx.T().Name())
+			return in
+		},
+		Cases: []mesa.FunctionCase[int, int]{
+			{Name: "Excluded", Input: 1},
+			{Name: "Included", Input: 2},
+		},
+	}
+
+	m.Run(t)
+
+	assert.Equal(t, []string{"TestFailOnNoCases_SomeRun/Included"}, ran)
+}
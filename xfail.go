@@ -0,0 +1,106 @@
+package mesa
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+	"testing"
+)
+
+// xfailT is a TestingT that records the failures of a case marked with XFail instead of reporting them. Once the case
+// finishes it is sealed, and failures made afterwards, e.g. by Cleanup, are reported to the test again.
+type xfailT struct {
+	testingT
+
+	mu     sync.Mutex
+	errors []string
+	sealed bool
+}
+
+// Errorf records the failure, or reports it once the case finished.
+func (x *xfailT) Errorf(format string, args ...any) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	if x.sealed {
+		x.testingT.Helper()
+		x.testingT.Errorf(format, args...)
+
+		return
+	}
+
+	x.errors = append(x.errors, fmt.Sprintf(format, args...))
+}
+
+// FailNow stops the case without failing the test, or fails the test once the case finished.
+func (x *xfailT) FailNow() {
+	x.mu.Lock()
+	sealed := x.sealed
+	x.mu.Unlock()
+
+	if sealed {
+		x.testingT.Helper()
+		x.testingT.FailNow()
+	}
+
+	runtime.Goexit()
+}
+
+// seal stops recording failures and returns the failures recorded so far.
+func (x *xfailT) seal() []string {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	x.sealed = true
+
+	return x.errors
+}
+
+// reporter returns the TestingT that the failures of the case are reported to.
+func (c *Ctx) reporter() testingT {
+	if c.xfail != nil {
+		return c.xfail
+	}
+
+	if c.failures != nil {
+		return failT{testingT: withHelper(c.t), count: c.failures}
+	}
+
+	return withHelper(c.t)
+}
+
+// runXFail runs a case marked with XFail, whose result is inverted: the case is skipped if it fails and fails if it
+// passes. Failures must be reported through the context, e.g. with ctx.As, ctx.Re or ctx.Errorf, to be recorded. A
+// panic also counts as a failure. The case runs on the test goroutine, and a fatal assertion stops it with
+// runtime.Goexit, so the result is decided by a deferred function.
+func runXFail(t *testing.T, reason string, run func(x *xfailT)) {
+	x := &xfailT{testingT: t}
+	returned := false
+
+	defer func() {
+		if r := recover(); r != nil {
+			x.Errorf("panic: %v", r)
+		}
+
+		failures := x.seal()
+		if t.Skipped() {
+			return
+		}
+
+		// The case was stopped through the test itself, e.g. with ctx.T().Fatal, which is reported as usual.
+		if !returned && len(failures) == 0 {
+			return
+		}
+
+		if len(failures) == 0 {
+			t.Errorf("%s: XFail case unexpectedly passed; remove XFail (%s)", t.Name(), reason)
+			return
+		}
+
+		t.Skipf("expected failure (%s): %s", reason, failures[0])
+	}()
+
+	run(x)
+
+	returned = true
+}
@@ -0,0 +1,148 @@
+package mesa_test
+
+import (
+	"testing"
+
+	"github.com/a20r/mesa"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFunctionCase_XFail(t *testing.T) {
+	var afterFatal bool
+
+	m := mesa.FunctionMesa[int, int]{
+		Target: func(ctx *mesa.Ctx, in int) int {
+			return in * 2
+		},
+		Cases: []mesa.FunctionCase[int, int]{
+			{Name: "Wrong expected", Input: 2, Expected: 5, XFail: "rounding bug"},
+			{
+				Name:  "Fatal assertion",
+				Input: 1,
+				XFail: "rounding bug",
+				Check: func(ctx *mesa.Ctx, in int, out int) {
+					ctx.Re.Equal(3, out)
+					afterFatal = true
+				},
+			},
+			{
+				Name:  "Errorf",
+				Input: 1,
+				XFail: "rounding bug",
+				Check: func(ctx *mesa.Ctx, in int, out int) {
+					ctx.Errorf("got %d", out)
+				},
+			},
+			{
+				Name:  "Panic",
+				Input: 1,
+				XFail: "nil map",
+				Check: func(ctx *mesa.Ctx, in int, out int) {
+					var m map[int]int
+					m[in] = out
+				},
+			},
+		},
+	}
+
+	m.Run(t)
+
+	assert.False(t, afterFatal, "a fatal assertion stops an XFail case")
+}
+
+func TestFunctionCase_XFail_Skipped(t *testing.T) {
+	out, ok, err := runInSubprocess(t, func(t *testing.T) {
+		m := mesa.FunctionMesa[int, int]{
+			Target: func(ctx *mesa.Ctx, in int) int {
+				return in * 2
+			},
+			Cases: []mesa.FunctionCase[int, int]{
+				{Name: "Known bug", Input: 2, Expected: 5, XFail: "rounding bug"},
+			},
+		}
+
+		m.Run(t)
+	})
+
+	if !ok {
+		return
+	}
+
+	assert.NoError(t, err)
+	assert.Contains(t, out, "--- SKIP: TestFunctionCase_XFail_Skipped/Known_bug")
+	assert.Contains(t, out, "expected failure (rounding bug)")
+}
+
+func TestFunctionCase_XFail_Passed(t *testing.T) {
+	expectFailure(t, func(t *testing.T) {
+		m := mesa.FunctionMesa[int, int]{
+			Target: func(ctx *mesa.Ctx, in int) int {
+				return in * 2
+			},
+			Cases: []mesa.FunctionCase[int, int]{
+				{Name: "Fixed", Input: 2, Expected: 4, XFail: "rounding bug"},
+			},
+		}
+
+		m.Run(t)
+	}, "TestFunctionCase_XFail_Passed/Fixed: XFail case unexpectedly passed; remove XFail (rounding bug)")
+}
+
+func TestFunctionCase_XFail_Cleanup(t *testing.T) {
+	expectFailure(t, func(t *testing.T) {
+		m := mesa.FunctionMesa[int, int]{
+			Target: func(ctx *mesa.Ctx, in int) int {
+				return in * 2
+			},
+			Cleanup: func(ctx *mesa.Ctx) {
+				ctx.As.Fail("cleanup failed")
+			},
+			Cases: []mesa.FunctionCase[int, int]{
+				{Name: "Known bug", Input: 2, Expected: 5, XFail: "rounding bug"},
+			},
+		}
+
+		m.Run(t)
+	}, "cleanup failed")
+}
+
+func TestFunctionCase_XFail_Stopped(t *testing.T) {
+	out, ok, err := runInSubprocess(t, func(t *testing.T) {
+		m := mesa.FunctionMesa[int, int]{
+			Target: func(ctx *mesa.Ctx, in int) int {
+				return in * 2
+			},
+			Cases: []mesa.FunctionCase[int, int]{
+				{
+					Name:  "Skipped",
+					Input: 1,
+					XFail: "rounding bug",
+					Check: func(ctx *mesa.Ctx, in int, out int) {
+						ctx.Skip("not supported")
+					},
+				},
+				{
+					Name:  "Fatal",
+					Input: 1,
+					XFail: "rounding bug",
+					Check: func(ctx *mesa.Ctx, in int, out int) {
+						ctx.T().Fatal("stopped through the test")
+					},
+				},
+			},
+		}
+
+		m.Run(t)
+	})
+	if !ok {
+		return
+	}
+
+	require.Error(t, err, out)
+	assert.Contains(t, out, "--- SKIP: TestFunctionCase_XFail_Stopped/Skipped")
+	assert.Contains(t, out, "--- FAIL: TestFunctionCase_XFail_Stopped/Fatal")
+	assert.Contains(t, out, "stopped through the test")
+	assert.NotContains(t, out, "Goexit")
+	assert.NotContains(t, out, "unexpectedly passed")
+}